@@ -2,17 +2,59 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"short-url/internal/config"
+	domaincreate "short-url/internal/http-server/handlers/domains/create"
+	domainlist "short-url/internal/http-server/handlers/domains/list"
+	"short-url/internal/http-server/handlers/domains/verify"
+	"short-url/internal/http-server/handlers/events/list"
+	"short-url/internal/http-server/handlers/events/requeue"
+	"short-url/internal/http-server/handlers/keys/create"
+	"short-url/internal/http-server/handlers/keys/revoke"
+	"short-url/internal/http-server/handlers/keys/rotate"
+	"short-url/internal/http-server/handlers/url/batch"
+	"short-url/internal/http-server/handlers/url/export"
+	"short-url/internal/http-server/handlers/url/get"
+	"short-url/internal/http-server/handlers/url/importer"
+	urllist "short-url/internal/http-server/handlers/url/list"
 	"short-url/internal/http-server/handlers/url/redirect"
+	"short-url/internal/http-server/handlers/url/remove"
 	"short-url/internal/http-server/handlers/url/save"
+	"short-url/internal/http-server/handlers/url/stats"
+	"short-url/internal/http-server/handlers/url/update"
+	wscreate "short-url/internal/http-server/handlers/workspaces/create"
+	"short-url/internal/http-server/handlers/workspaces/members"
+	"short-url/internal/http-server/handlers/workspaces/quota"
 	mwLogger "short-url/internal/http-server/middleware"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	mwIdempotency "short-url/internal/http-server/middleware/idempotency"
+	mwLegacy "short-url/internal/http-server/middleware/legacy"
+	mwRateLimit "short-url/internal/http-server/middleware/ratelimit"
+	mwRealIP "short-url/internal/http-server/middleware/realip"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/lib/aliasgen"
+	"short-url/internal/lib/domainverify"
+	"short-url/internal/lib/idcode"
+	"short-url/internal/lib/ratelimit"
+	"short-url/internal/lib/shorturl"
 	"short-url/internal/lib/sl"
+	clickaggregator "short-url/internal/services/click-aggregator"
+	clicktracker "short-url/internal/services/click-tracker"
 	eventsender "short-url/internal/services/event-sender"
+	"short-url/internal/services/event-sender/publisher"
+	"short-url/internal/services/janitor"
+	"short-url/internal/storage"
+	"short-url/internal/storage/migrations"
+	"short-url/internal/storage/postgres"
 	"short-url/internal/storage/sqlite"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
@@ -23,6 +65,11 @@ const (
 	envDev   = "dev"
 )
 
+const (
+	driverSQLite   = "sqlite"
+	driverPostgres = "postgres"
+)
+
 func setupLogger(env string) *slog.Logger {
 	var log *slog.Logger
 	switch env {
@@ -34,45 +81,258 @@ func setupLogger(env string) *slog.Logger {
 	return log
 }
 
+const (
+	publisherLog     = "log"
+	publisherWebhook = "webhook"
+	publisherFile    = "file"
+	publisherKafka   = "kafka"
+)
+
+// appStorage is a storage backend together with its schema migrator
+type appStorage interface {
+	storage.Repository
+	Migrator() (*migrations.Migrator, error)
+	Close() error
+}
+
+type eventPublisher interface {
+	eventsender.Publisher
+	Close() error
+}
+
+func setupPublisher(cfg config.Publisher, log *slog.Logger) (eventPublisher, error) {
+	switch cfg.Type {
+	case publisherLog:
+		return publisher.NewLog(log), nil
+	case publisherWebhook:
+		return publisher.NewWebhook(cfg.Webhook.URL, cfg.Webhook.Secret, cfg.Webhook.Timeout), nil
+	case publisherFile:
+		return publisher.NewFile(cfg.File.Path)
+	case publisherKafka:
+		return publisher.NewKafka(cfg.Kafka.Brokers, cfg.Kafka.Topic, cfg.Kafka.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown publisher type %q", cfg.Type)
+	}
+}
+
+const (
+	aliasesRandom = "random"
+	aliasesBase62 = "base62"
+	aliasesSqids  = "sqids"
+)
+
+func setupAliasStrategy(cfg config.Aliases, seq aliasgen.Sequence) (save.AliasStrategy, error) {
+	alphabet := aliasgen.Alphabet(cfg.Alphabet)
+	switch cfg.Strategy {
+	case aliasesRandom:
+		return aliasgen.New(alphabet, cfg.Length, cfg.MaxLength, cfg.GrowAfter)
+	case aliasesBase62:
+		codec, err := idcode.NewBase62(alphabet, cfg.Salt)
+		if err != nil {
+			return nil, err
+		}
+		return aliasgen.NewSequential(seq, codec), nil
+	case aliasesSqids:
+		codec, err := idcode.NewSqids(alphabet, cfg.Salt, cfg.MinLength)
+		if err != nil {
+			return nil, err
+		}
+		return aliasgen.NewSequential(seq, codec), nil
+	default:
+		return nil, fmt.Errorf("unknown alias strategy %q", cfg.Strategy)
+	}
+}
+
+const rateLimitMemory = "memory"
+
+// links normalized in one statement on startup
+const normalizeBatchSize = 500
+
+func setupRateLimiter(cfg config.RateLimit) (ratelimit.Store, error) {
+	switch cfg.Backend {
+	case rateLimitMemory:
+		return ratelimit.NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", cfg.Backend)
+	}
+}
+
+func setupStorage(cfg config.Storage) (appStorage, error) {
+	switch cfg.Driver {
+	case driverSQLite:
+		return sqlite.New(cfg.Path)
+	case driverPostgres:
+		return postgres.New(cfg.DSN)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
 func main() {
 	//config cleanenv
 	cfg := config.MustLoad()
 
-	//TODO: remove
-	fmt.Println(cfg)
-
 	//logger slog
 	log := setupLogger(cfg.Env)
 	//TODO: remove
 	log.Debug("Logger ready")
 	log.Info("env is", slog.String("env", cfg.Env))
 
-	//storage sqllite
-	storage, err := sqlite.New(cfg.StoragePath)
+	//storage sqlite or postgres
+	storage, err := setupStorage(cfg.Storage)
 	if err != nil {
-		log.Error("can't connect to storage", sl.Err(err))
+		log.Error("can't connect to storage", sl.Err(err), slog.String("driver", cfg.Driver))
 		os.Exit(1)
 	}
 
+	migrator, err := storage.Migrator()
+	if err != nil {
+		log.Error("can't load migrations", sl.Err(err))
+		os.Exit(1)
+	}
+
+	//migrate up|down|status subcommand
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(migrator, os.Args[2:]); err != nil {
+			log.Error("migration failed", sl.Err(err))
+			os.Exit(1)
+		}
+		return
+	}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		log.Error("can't apply migrations", sl.Err(err))
+		os.Exit(1)
+	}
+	for _, m := range applied {
+		log.Info("migration applied", slog.Int("version", m.Version), slog.String("name", m.Name))
+	}
+
+	//links saved before normalized urls were stored aren't deduplicated until they are normalized
+	normalized, err := normalizeURLs(storage, normalizeBatchSize)
+	if err != nil {
+		log.Error("can't normalize urls", sl.Err(err))
+		os.Exit(1)
+	}
+	if normalized > 0 {
+		log.Info("urls normalized", slog.Int("count", normalized))
+	}
+
+	//background workers are stopped after the http server, so in-flight requests can still track clicks
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	tracker := clicktracker.New(storage, log, cfg.ClickTracker)
+	tracker.StartProcessClicks(workersCtx)
+
+	if cfg.Janitor.Mode != janitor.ModePurge && cfg.Janitor.Mode != janitor.ModeArchive {
+		log.Error("unknown janitor mode", slog.String("mode", cfg.Janitor.Mode))
+		os.Exit(1)
+	}
+	cleaner := janitor.New(storage, log, cfg.Janitor)
+	cleaner.StartPurgeExpired(workersCtx)
+
+	//clicks are flushed up to flush_interval after they happened, the rollup must wait for them
+	if cfg.ClickAggregator.Lag < cfg.ClickTracker.FlushInterval {
+		cfg.ClickAggregator.Lag = cfg.ClickTracker.FlushInterval
+	}
+	aggregator := clickaggregator.New(storage, log, cfg.ClickAggregator)
+	aggregator.StartAggregate(workersCtx)
+
+	trustedProxies, err := mwRealIP.ParseTrustedProxies(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		log.Error("invalid trusted proxies", sl.Err(err))
+		os.Exit(1)
+	}
+	limiter, err := setupRateLimiter(cfg.RateLimit)
+	if err != nil {
+		log.Error("can't create rate limiter", sl.Err(err), slog.String("backend", cfg.RateLimit.Backend))
+		os.Exit(1)
+	}
+	createLimit := mwRateLimit.New(log, limiter, "create", ratelimit.PerPeriod(
+		cfg.RateLimit.Create.Requests, cfg.RateLimit.Create.Period, cfg.RateLimit.Create.Burst))
+	redirectLimit := mwRateLimit.New(log, limiter, "redirect", ratelimit.PerPeriod(
+		cfg.RateLimit.Redirect.Requests, cfg.RateLimit.Redirect.Period, cfg.RateLimit.Redirect.Burst))
+	//the api key isn't known yet, so authLimit goes by client ip and also counts the requests failing authentication
+	authLimit := mwRateLimit.New(log, limiter, "auth", ratelimit.PerPeriod(
+		cfg.RateLimit.Auth.Requests, cfg.RateLimit.Auth.Period, cfg.RateLimit.Auth.Burst))
+	//retries of write requests with an Idempotency-Key header get the response of the first one
+	idempotent := mwIdempotency.New(log, storage, cfg.Idempotency.Window, cfg.Idempotency.MaxBodyBytes)
+
 	//router chi
 	router := chi.NewRouter()
 	router.Use(middleware.RequestID)
+	//the client address of requests from trusted proxies is taken from X-Forwarded-For
+	router.Use(mwRealIP.New(trustedProxies))
 	router.Use(mwLogger.New(log))
 	router.Use(middleware.Recoverer)
 	//to get params from url
 	router.Use(middleware.URLFormat)
+	//old clients get {"status","error"} bodies with 200 instead of problem+json
+	router.Use(mwLegacy.New(cfg.HTTPServer.LegacyResponses))
+
+	//api keys are required for the links API, basic auth with the admin credentials acts as a root key
+	auth := mwAuth.New(log, storage, cfg.HTTPServer.User, cfg.HTTPServer.Password)
+
+	shortURLs := shorturl.Builder{DefaultURL: cfg.Domains.DefaultURL, Scheme: cfg.Domains.Scheme}
+
+	aliases, err := setupAliasStrategy(cfg.Aliases, storage)
+	if err != nil {
+		log.Error("can't create alias strategy", sl.Err(err), slog.String("strategy", cfg.Aliases.Strategy))
+		os.Exit(1)
+	}
+
+	router.Route("/url", func(r chi.Router) {
+		r.Use(authLimit, auth)
+
+		r.With(mwAuth.RequireScope(domain.ScopeLinksWrite), createLimit, idempotent).Post("/", save.New(log, storage, shortURLs, aliases, cfg.Aliases.MaxAttempts))
+		r.With(mwAuth.RequireScope(domain.ScopeLinksWrite), createLimit, idempotent).Post("/batch",
+			batch.New(log, storage, shortURLs, aliases, cfg.Batch.MaxItems, cfg.Batch.ChunkSize, cfg.Aliases.MaxAttempts))
+		r.With(mwAuth.RequireScope(domain.ScopeLinksWrite), createLimit, idempotent).Post("/import",
+			importer.New(log, storage, cfg.Batch.ChunkSize, cfg.Batch.MaxImportRows))
+		r.With(mwAuth.RequireScope(domain.ScopeLinksRead)).Get("/", urllist.New(log, storage))
+		r.With(mwAuth.RequireScope(domain.ScopeLinksRead)).Get("/export", export.New(log, storage))
+		r.With(mwAuth.RequireScope(domain.ScopeLinksRead)).Get("/{alias}", get.New(log, storage))
+		r.With(mwAuth.RequireScope(domain.ScopeLinksWrite)).Patch("/{alias}", update.New(log, storage))
+		r.With(mwAuth.RequireScope(domain.ScopeLinksWrite)).Delete("/{alias}", remove.New(log, storage))
+		r.With(mwAuth.RequireScope(domain.ScopeLinksRead)).Get("/{alias}/stats", stats.New(log, storage))
+	})
+	//custom domains of the workspace, served once their TXT record is verified
+	router.Route("/domains", func(r chi.Router) {
+		r.Use(authLimit, auth)
+
+		r.With(mwAuth.RequireScope(domain.ScopeLinksWrite)).Post("/", domaincreate.New(log, storage))
+		r.With(mwAuth.RequireScope(domain.ScopeLinksRead)).Get("/", domainlist.New(log, storage))
+		r.With(mwAuth.RequireScope(domain.ScopeLinksWrite)).Post("/{hostname}/verify",
+			verify.New(log, storage, domainverify.New(net.DefaultResolver), cfg.Domains.VerifyTimeout))
+	})
+	//redirects stay public, aliases are resolved on the verified custom domain of the host,
+	//other hosts serve the default domain where /w/{workspace} selects the workspace
+	redirectHandler := redirect.New(log, storage, tracker)
+	router.With(redirectLimit).Get("/{alias}", redirectHandler)
+	router.With(redirectLimit).Get("/w/{workspace}/{alias}", redirectHandler)
+
+	router.Route("/admin", func(r chi.Router) {
+		r.Use(authLimit, auth)
+		r.Use(mwAuth.RequireScope(domain.ScopeAdmin))
 
-	// router.Route("/url", func(r chi.Router) {
-	// 	//'short-url' - title in browser
-	// 	r.Use(middleware.BasicAuth("short-url", map[string]string{
-	// 		cfg.HTTPServer.User: cfg.HTTPServer.Password,
-	// 	}))
+		//admins of a workspace manage its keys, global admins the keys of every workspace
+		r.Post("/keys", create.New(log, storage))
+		r.Delete("/keys/{id}", revoke.New(log, storage))
+		r.Post("/keys/{id}/rotate", rotate.New(log, storage))
+		r.Get("/workspaces/{id}/members", members.New(log, storage))
 
-	// 	r.Post("/", save.New(log, storage))
-	// })
+		r.Group(func(r chi.Router) {
+			r.Use(mwAuth.RequireGlobalAdmin)
 
-	router.Post("/url", save.New(log, storage))
-	router.Get("/{alias}", redirect.New(log, storage))
+			r.Get("/events/dead", list.New(log, storage))
+			r.Post("/events/{id}/requeue", requeue.New(log, storage))
+
+			r.Post("/workspaces", wscreate.New(log, storage))
+			r.Put("/workspaces/{id}/quota", quota.New(log, storage))
+		})
+	})
 
 	//server
 	log.Info("starting server", slog.String("address", cfg.Address))
@@ -84,10 +344,102 @@ func main() {
 		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
 	}
 
-	sender := eventsender.New(storage, log)
-	sender.StartProcessEvents(context.Background(), cfg.HTTPServer.EventSenderPeriod)
+	pub, err := setupPublisher(cfg.EventSender.Publisher, log)
+	if err != nil {
+		log.Error("can't create event publisher", sl.Err(err), slog.String("type", cfg.EventSender.Publisher.Type))
+		os.Exit(1)
+	}
+
+	sender := eventsender.New(storage, pub, log, cfg.EventSender)
+	sender.StartProcessEvents(workersCtx, cfg.EventSender.Period)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("failed to start server", sl.Err(err))
+			stop <- syscall.SIGTERM
+		}
+	}()
+
+	sig := <-stop
+	log.Info("stopping server", slog.String("signal", sig.String()))
+
+	//graceful shutdown: http server -> workers -> publisher -> storage
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error("failed to stop server gracefully", sl.Err(err))
+	}
+
+	stopWorkers()
+	sender.Wait()
+	tracker.Wait()
+	cleaner.Wait()
+	aggregator.Wait()
+
+	if err := pub.Close(); err != nil {
+		log.Error("failed to close event publisher", sl.Err(err))
+	}
+	if err := storage.Close(); err != nil {
+		log.Error("failed to close storage", sl.Err(err))
+	}
+
+	log.Info("server stopped")
+}
+
+// normalizeURLs normalizes the links without a normalized url batch by batch and returns their number
+func normalizeURLs(st storage.Repository, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("normalize batch size must be positive, got %d", batchSize)
+	}
+	total := 0
+	for {
+		n, err := st.NormalizeURLs(batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < batchSize {
+			return total, nil
+		}
+	}
+}
+
+func runMigrate(migrator *migrations.Migrator, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: migrate up|down|status")
+	}
 
-	if err = srv.ListenAndServe(); err != nil {
-		log.Error("failed to start server")
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "down":
+		m, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		return nil
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			state := "pending"
+			if st.AppliedAt != nil {
+				state = "applied at " + st.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", st.Version, st.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up|down|status", args[0])
 	}
 }
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/lib/linkio"
+	"short-url/internal/storage"
+	"short-url/internal/storage/sqlite"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAdmin(t *testing.T, jsonOutput bool) (*admin, *bytes.Buffer) {
+	t.Helper()
+
+	s, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+
+	m, err := s.Migrator()
+	require.NoError(t, err)
+	_, err = m.Up()
+	require.NoError(t, err)
+	require.NoError(t, checkSchema(s))
+
+	var out bytes.Buffer
+	return &admin{st: s, out: printer{w: &out, json: jsonOutput}, chunkSize: 10}, &out
+}
+
+func run(t *testing.T, a *admin, out *bytes.Buffer, args ...string) string {
+	t.Helper()
+
+	out.Reset()
+	require.NoError(t, a.run(args))
+	return out.String()
+}
+
+func TestAdmin_Links(t *testing.T) {
+	a, out := newTestAdmin(t, false)
+	_, err := a.st.SaveURL(domain.Link{WorkspaceID: domain.DefaultWorkspaceID, Alias: "abc", URL: "https://example.com"})
+	require.NoError(t, err)
+
+	res := run(t, a, out, "links", "list")
+	require.Regexp(t, `^ID\s+WORKSPACE\s+DOMAIN\s+ALIAS\s+URL`, res)
+	require.Regexp(t, `\n1\s+1\s+-\s+abc\s+https://example.com\s+-\s+false`, res)
+
+	//flags may follow the alias
+	res = run(t, a, out, "links", "update", "abc", "-url", "https://example.org", "-disable")
+	require.Contains(t, res, "https://example.org")
+
+	a.out.json = true
+	var link domain.Link
+	require.NoError(t, json.Unmarshal([]byte(run(t, a, out, "links", "get", "abc")), &link))
+	require.Equal(t, "https://example.org", link.URL)
+	require.True(t, link.Disabled)
+
+	run(t, a, out, "links", "delete", "abc")
+	require.ErrorIs(t, a.run([]string{"links", "get", "abc"}), storage.ErrURLNotFound)
+
+	err = a.run([]string{"links", "update", "abc", "-url", "not a url"})
+	require.EqualError(t, err, "invalid body,field URL is not in URL format")
+	err = a.run([]string{"links", "update", "abc"})
+	require.EqualError(t, err, "invalid update: nothing to update")
+}
+
+func TestAdmin_Events(t *testing.T) {
+	a, out := newTestAdmin(t, true)
+	_, err := a.st.SaveURL(domain.Link{WorkspaceID: domain.DefaultWorkspaceID, Alias: "abc", URL: "https://example.com"})
+	require.NoError(t, err)
+	events, err := a.st.ClaimEvents("worker", 1, 0)
+	require.NoError(t, err)
+	require.NoError(t, a.st.MarkEventDead(events[0].ID, "bad gateway"))
+
+	var dead []domain.OutboxEvent
+	require.NoError(t, json.Unmarshal([]byte(run(t, a, out, "events", "list")), &dead))
+	require.Len(t, dead, 1)
+	require.Equal(t, domain.EventStatusDead, dead[0].Status)
+	require.Equal(t, "bad gateway", dead[0].LastError)
+
+	run(t, a, out, "events", "requeue", "1")
+
+	var pending []domain.OutboxEvent
+	require.NoError(t, json.Unmarshal([]byte(run(t, a, out, "events", "list", "-status", "pending")), &pending))
+	require.Len(t, pending, 1)
+	require.Equal(t, 1, pending[0].ID)
+
+	var usageErr usageError
+	require.ErrorAs(t, a.run([]string{"events", "list", "-status", "new"}), &usageErr)
+
+	out.Reset()
+	require.EqualError(t, a.run([]string{"events", "requeue", "1", "2"}), "2 of 2 events not requeued")
+	var requeued []requeuedEvent
+	require.NoError(t, json.Unmarshal(out.Bytes(), &requeued))
+	require.Len(t, requeued, 2)
+	require.False(t, requeued[0].Requeued)
+	require.Contains(t, requeued[0].Error, storage.ErrDeadEventNotFound.Error())
+
+	var purged purgedEvents
+	require.NoError(t, json.Unmarshal([]byte(run(t, a, out, "events", "purge", "-dead")), &purged))
+	require.Zero(t, purged.Deleted)
+}
+
+func TestAdmin_Keys(t *testing.T) {
+	a, out := newTestAdmin(t, true)
+
+	var key createdKey
+	require.NoError(t, json.Unmarshal([]byte(run(t, a, out, "keys", "create", "-name", "ci", "-scopes", "links:read, links:write")), &key))
+	require.True(t, strings.HasPrefix(key.Key, key.Prefix))
+	require.Equal(t, []string{domain.ScopeLinksRead, domain.ScopeLinksWrite}, key.Scopes)
+
+	run(t, a, out, "keys", "revoke", "1")
+
+	var usageErr usageError
+	require.ErrorAs(t, a.run([]string{"keys", "create", "-name", "ci", "-scopes", "root"}), &usageErr)
+}
+
+func TestAdmin_ImportExport(t *testing.T) {
+	a, out := newTestAdmin(t, true)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "links.ndjson")
+	require.NoError(t, os.WriteFile(file, []byte(
+		`{"alias":"a","url":"https://a.example"}`+"\n"+`{"alias":"b","url":"not a url"}`+"\n"), 0o600))
+
+	var sum linkio.Summary
+	require.NoError(t, json.Unmarshal([]byte(run(t, a, out, "import", "-dry-run", file)), &sum))
+	require.Equal(t, 1, sum.Created)
+	require.Equal(t, 1, sum.Failed)
+	require.True(t, sum.DryRun)
+
+	out.Reset()
+	require.EqualError(t, a.run([]string{"import", "-on-conflict", "fail", file}), "import aborted, nothing is saved")
+
+	a.in = strings.NewReader("alias,url\na,https://a.example\n")
+	run(t, a, out, "import", "-")
+
+	res := run(t, a, out, "export")
+	require.Equal(t, "id,alias,url,domain,expires_at,disabled,created_at,updated_at\n", res[:strings.Index(res, "\n")+1])
+	require.Contains(t, res, ",a,https://a.example,")
+
+	var exported exportedLinks
+	require.NoError(t, json.Unmarshal([]byte(run(t, a, out, "export", "-clicks", "-o", filepath.Join(dir, "out.ndjson"))), &exported))
+	require.Equal(t, exportedLinks{File: filepath.Join(dir, "out.ndjson"), Format: linkio.FormatNDJSON, Exported: 1}, exported)
+}
+
+func TestAdmin_Stats(t *testing.T) {
+	a, out := newTestAdmin(t, false)
+	_, err := a.st.SaveURL(domain.Link{WorkspaceID: domain.DefaultWorkspaceID, Alias: "abc", URL: "https://example.com"})
+	require.NoError(t, err)
+
+	res := run(t, a, out, "stats", "abc", "-bucket", "hour")
+	require.Regexp(t, `ALIAS\s+FROM\s+TO\s+SOURCE\s+TOTAL\s+UNIQUE_VISITORS\nabc\s`, res)
+	require.Contains(t, res, "\n\nREFERRER")
+
+	res = run(t, a, out, "stats")
+	require.Regexp(t, `\n1\s+\d{4}-\d{2}\s+1\n$`, res)
+}
+
+func TestAdmin_UsageErrors(t *testing.T) {
+	a, _ := newTestAdmin(t, false)
+
+	for _, args := range [][]string{
+		{"urls"},
+		{"links"},
+		{"links", "purge"},
+		{"links", "get"},
+		{"links", "list", "-limit", "x"},
+		{"events", "requeue", "first"},
+		{"import", "-on-conflict", "replace", "-"},
+		{"stats", "abc", "-bucket", "week"},
+	} {
+		var usageErr usageError
+		require.ErrorAs(t, a.run(args), &usageErr, strings.Join(args, " "))
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"short-url/internal/http-server/model/domain"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (a *admin) eventsList(args []string) error {
+	fs := newFlags("events list")
+	status := fs.String("status", domain.EventStatusDead, strings.Join(domain.EventStatuses, ", ")+" events")
+	limit := fs.Int("limit", 100, "max number of events")
+	if _, err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if !slices.Contains(domain.EventStatuses, *status) {
+		return usagef("events list: status must be one of %s", strings.Join(domain.EventStatuses, ", "))
+	}
+	if *limit <= 0 {
+		return usagef("events list: limit must be positive")
+	}
+
+	events, err := a.st.ListEvents(*status, *limit)
+	if err != nil {
+		return err
+	}
+	t := table{header: []string{"ID", "TYPE", "STATUS", "ATTEMPTS", "LAST_ERROR", "NEXT_ATTEMPT_AT", "CREATED_AT"}}
+	for _, e := range events {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(e.ID),
+			e.EventType,
+			e.Status,
+			strconv.Itoa(e.Attempts),
+			orDash(e.LastError),
+			formatTime(e.NextAttemptAt),
+			formatTime(&e.CreatedAt),
+		})
+	}
+	return a.out.print(events, t)
+}
+
+type requeuedEvent struct {
+	ID       int    `json:"id"`
+	Requeued bool   `json:"requeued"`
+	Error    string `json:"error,omitempty"`
+}
+
+// eventsRequeue returns dead events to the outbox, it reports every id and fails if any of them isn't requeued
+func (a *admin) eventsRequeue(args []string) error {
+	fs := newFlags("events requeue")
+	pos, err := parseFlags(fs, args, 1, -1)
+	if err != nil {
+		return err
+	}
+	ids := make([]int, len(pos))
+	for i, p := range pos {
+		if ids[i], err = strconv.Atoi(p); err != nil {
+			return usagef("events requeue: id %q is not a number", p)
+		}
+	}
+
+	var requeued []requeuedEvent
+	var failed int
+	t := table{header: []string{"ID", "REQUEUED", "ERROR"}}
+	for _, id := range ids {
+		res := requeuedEvent{ID: id, Requeued: true}
+		if err := a.st.RequeueEvent(id); err != nil {
+			res.Requeued, res.Error = false, err.Error()
+			failed++
+		}
+		requeued = append(requeued, res)
+		t.rows = append(t.rows, []string{strconv.Itoa(id), strconv.FormatBool(res.Requeued), orDash(res.Error)})
+	}
+	if err := a.out.print(requeued, t); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d events not requeued", failed, len(ids))
+	}
+	return nil
+}
+
+type purgedEvents struct {
+	Before  time.Time `json:"before"`
+	Dead    bool      `json:"dead"`
+	Deleted int       `json:"deleted"`
+}
+
+// eventsPurge deletes the delivered events (and the dead ones with -dead) older than -older-than batch by batch
+func (a *admin) eventsPurge(args []string) error {
+	fs := newFlags("events purge")
+	olderThan := fs.Duration("older-than", 7*24*time.Hour, "age of the deleted events")
+	dead := fs.Bool("dead", false, "delete the dead events too")
+	batchSize := fs.Int("batch", 1000, "events deleted in one statement")
+	if _, err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *olderThan < 0 || *batchSize <= 0 {
+		return usagef("events purge: older-than can't be negative and batch must be positive")
+	}
+
+	res := purgedEvents{Before: time.Now().Add(-*olderThan).UTC(), Dead: *dead}
+	for {
+		n, err := a.st.PurgeEvents(res.Before, *dead, *batchSize)
+		res.Deleted += n
+		if err != nil {
+			return err
+		}
+		if n < *batchSize {
+			break
+		}
+	}
+	return a.out.print(res, table{
+		header: []string{"BEFORE", "DEAD", "DELETED"},
+		rows:   [][]string{{formatTime(&res.Before), strconv.FormatBool(res.Dead), strconv.Itoa(res.Deleted)}},
+	})
+}
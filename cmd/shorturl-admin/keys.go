@@ -0,0 +1,88 @@
+package main
+
+import (
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/lib/apikey"
+	"short-url/internal/storage"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type createdKey struct {
+	domain.APIKey
+	// the secret key is only shown once, the storage keeps its hash
+	Key string `json:"key"`
+}
+
+func (a *admin) keysCreate(args []string) error {
+	fs := newFlags("keys create")
+	workspaceID := fs.Int64("workspace", domain.DefaultWorkspaceID, "workspace the key is a member of")
+	name := fs.String("name", "", "name of the key")
+	scopes := fs.String("scopes", "", "comma separated scopes: "+strings.Join(domain.Scopes, ", "))
+	if _, err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *name == "" || *scopes == "" {
+		return usagef("keys create: name and scopes are required")
+	}
+
+	key := domain.APIKey{
+		WorkspaceID: *workspaceID,
+		Name:        *name,
+		CreatedAt:   time.Now().UTC(),
+	}
+	for _, scope := range strings.Split(*scopes, ",") {
+		scope = strings.TrimSpace(scope)
+		if !slices.Contains(domain.Scopes, scope) {
+			return usagef("keys create: unknown scope %q", scope)
+		}
+		key.Scopes = append(key.Scopes, scope)
+	}
+
+	secret, prefix, err := apikey.Generate()
+	if err != nil {
+		return err
+	}
+	key.Prefix = prefix
+	if key.ID, err = a.st.CreateAPIKey(key, apikey.Hash(secret)); err != nil {
+		return err
+	}
+
+	return a.out.print(createdKey{APIKey: key, Key: secret}, table{
+		header: []string{"ID", "WORKSPACE", "NAME", "SCOPES", "KEY"},
+		rows:   [][]string{{formatInt(key.ID), formatInt(key.WorkspaceID), key.Name, strings.Join(key.Scopes, ","), secret}},
+	})
+}
+
+type revokedKey struct {
+	ID      int64 `json:"id"`
+	Revoked bool  `json:"revoked"`
+}
+
+// keysRevoke revokes the keys, it stops at the first id that isn't an active key
+func (a *admin) keysRevoke(args []string) error {
+	fs := newFlags("keys revoke")
+	pos, err := parseFlags(fs, args, 1, -1)
+	if err != nil {
+		return err
+	}
+	ids := make([]int64, len(pos))
+	for i, p := range pos {
+		if ids[i], err = strconv.ParseInt(p, 10, 64); err != nil {
+			return usagef("keys revoke: id %q is not a number", p)
+		}
+	}
+
+	var revoked []revokedKey
+	t := table{header: []string{"ID", "REVOKED"}}
+	for _, id := range ids {
+		if err := a.st.RevokeAPIKey(storage.AnyWorkspace, id); err != nil {
+			return err
+		}
+		revoked = append(revoked, revokedKey{ID: id, Revoked: true})
+		t.rows = append(t.rows, []string{formatInt(id), "true"})
+	}
+	return a.out.print(revoked, t)
+}
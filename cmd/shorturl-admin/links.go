@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"short-url/internal/http-server/handlers/url/update"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var linkHeader = []string{"ID", "WORKSPACE", "DOMAIN", "ALIAS", "URL", "EXPIRES_AT", "DISABLED", "CREATED_AT"}
+
+func linkRow(l domain.Link) []string {
+	return []string{
+		formatInt(l.ID),
+		formatInt(l.WorkspaceID),
+		orDash(l.Domain),
+		l.Alias,
+		l.URL,
+		formatTime(l.ExpiresAt),
+		strconv.FormatBool(l.Disabled),
+		formatTime(&l.CreatedAt),
+	}
+}
+
+// refFlags adds the flags selecting a link, the alias is the positional arg
+func refFlags(fs *flag.FlagSet) (workspaceID *int64, host *string) {
+	workspaceID = fs.Int64("workspace", domain.DefaultWorkspaceID, "workspace of the link")
+	host = fs.String("domain", "", "custom domain of the link, the default domain if empty")
+	return workspaceID, host
+}
+
+func linkRef(workspaceID int64, host string, alias string) domain.LinkRef {
+	return domain.LinkRef{WorkspaceID: workspaceID, Domain: strings.ToLower(host), Alias: alias}
+}
+
+func (a *admin) linksList(args []string) error {
+	fs := newFlags("links list")
+	workspaceID := fs.Int64("workspace", domain.DefaultWorkspaceID, "workspace of the links")
+	prefix := fs.String("prefix", "", "alias prefix")
+	sort := fs.String("sort", "-created_at", "created_at or alias, - for descending order")
+	limit := fs.Int("limit", 100, "max number of links")
+	if _, err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	query := domain.LinkQuery{
+		WorkspaceID: *workspaceID,
+		Prefix:      *prefix,
+		SortBy:      strings.TrimPrefix(*sort, "-"),
+		Desc:        strings.HasPrefix(*sort, "-"),
+		Limit:       *limit,
+	}
+	if query.SortBy != domain.LinkSortCreatedAt && query.SortBy != domain.LinkSortAlias {
+		return usagef("links list: sort must be created_at or alias")
+	}
+	if query.Limit <= 0 {
+		return usagef("links list: limit must be positive")
+	}
+
+	links, err := a.st.ListLinks(query)
+	if err != nil {
+		return err
+	}
+	t := table{header: linkHeader}
+	for _, l := range links {
+		t.rows = append(t.rows, linkRow(l))
+	}
+	return a.out.print(links, t)
+}
+
+func (a *admin) linksGet(args []string) error {
+	fs := newFlags("links get")
+	workspaceID, host := refFlags(fs)
+	pos, err := parseFlags(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	link, err := a.st.GetLink(linkRef(*workspaceID, *host, pos[0]))
+	if err != nil {
+		return err
+	}
+	return a.out.print(link, table{header: linkHeader, rows: [][]string{linkRow(link)}})
+}
+
+type deletedLink struct {
+	WorkspaceID int64  `json:"workspace_id"`
+	Domain      string `json:"domain,omitempty"`
+	Alias       string `json:"alias"`
+	Deleted     bool   `json:"deleted"`
+}
+
+func (a *admin) linksDelete(args []string) error {
+	fs := newFlags("links delete")
+	workspaceID, host := refFlags(fs)
+	pos, err := parseFlags(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	ref := linkRef(*workspaceID, *host, pos[0])
+	if err := a.st.DeleteURL(ref); err != nil {
+		return err
+	}
+	deleted := deletedLink{WorkspaceID: ref.WorkspaceID, Domain: ref.Domain, Alias: ref.Alias, Deleted: true}
+	return a.out.print(deleted, table{
+		header: []string{"WORKSPACE", "DOMAIN", "ALIAS", "DELETED"},
+		rows:   [][]string{{formatInt(ref.WorkspaceID), orDash(ref.Domain), ref.Alias, "true"}},
+	})
+}
+
+func (a *admin) linksUpdate(args []string) error {
+	fs := newFlags("links update")
+	workspaceID, host := refFlags(fs)
+	url := fs.String("url", "", "new destination")
+	expiresAt := fs.String("expires-at", "", "expiration time in RFC 3339")
+	ttl := fs.String("ttl", "", "lifetime from now, e.g. 72h")
+	neverExpires := fs.Bool("never-expires", false, "remove the expiration")
+	disable := fs.Bool("disable", false, "stop redirecting the link")
+	enable := fs.Bool("enable", false, "redirect the link again")
+	pos, err := parseFlags(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if *disable && *enable {
+		return usagef("links update: -disable and -enable are mutually exclusive")
+	}
+
+	//the flags are checked like the body of PATCH /url/{alias}
+	req := update.Request{TTL: *ttl, NeverExpires: *neverExpires}
+	if *url != "" {
+		req.URL = url
+	}
+	if *expiresAt != "" {
+		t, err := time.Parse(time.RFC3339, *expiresAt)
+		if err != nil {
+			return usagef("links update: expires-at is not in RFC 3339 format")
+		}
+		req.ExpiresAt = &t
+	}
+	if *disable || *enable {
+		req.Disabled = disable
+	}
+	upd, err := req.Update(time.Now())
+	var validErrs validator.ValidationErrors
+	if errors.As(err, &validErrs) {
+		return errors.New(responseModel.ValidationError(validErrs).Error)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid update: %w", err)
+	}
+
+	link, err := a.st.UpdateLink(linkRef(*workspaceID, *host, pos[0]), upd)
+	if err != nil {
+		return err
+	}
+	return a.out.print(link, table{header: linkHeader, rows: [][]string{linkRow(link)}})
+}
@@ -0,0 +1,210 @@
+// shorturl-admin manages the links, outbox events and api keys of the storage in the CONFIG_PATH config,
+// without going through the http API.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"short-url/internal/config"
+	"short-url/internal/storage"
+	"short-url/internal/storage/migrations"
+	"short-url/internal/storage/postgres"
+	"short-url/internal/storage/sqlite"
+)
+
+const usage = `usage: shorturl-admin [-json] <command> [flags] [args]
+
+commands:
+  links list [-workspace id] [-prefix p] [-sort -created_at|created_at|alias|-alias] [-limit n]
+  links get|delete [-workspace id] [-domain host] <alias>
+  links update [-workspace id] [-domain host] [-url u] [-expires-at t] [-ttl d] [-never-expires] [-disable|-enable] <alias>
+  events list [-status pending|dead|done] [-limit n]
+  events requeue <id>...
+  events purge [-older-than d] [-dead] [-batch n]
+  keys create [-workspace id] -name n -scopes s1,s2
+  keys revoke <id>...
+  import [-workspace id] [-format csv|ndjson] [-on-conflict skip|overwrite|fail] [-dry-run] <file|->
+  export [-workspace id] [-format csv|ndjson] [-clicks] [-o file]
+  stats [-workspace id] [-domain host] [-from t] [-to t] [-bucket hour|day] <alias>
+  stats [-workspace id] [-period yyyy-mm]
+
+-json prints JSON instead of tables, times are RFC 3339
+`
+
+const (
+	driverSQLite   = "sqlite"
+	driverPostgres = "postgres"
+)
+
+// adminStorage is a storage backend together with its schema migrator
+type adminStorage interface {
+	storage.Repository
+	Migrator() (*migrations.Migrator, error)
+	Close() error
+}
+
+func setupStorage(cfg config.Storage) (adminStorage, error) {
+	switch cfg.Driver {
+	case driverSQLite:
+		return sqlite.New(cfg.Path)
+	case driverPostgres:
+		return postgres.New(cfg.DSN)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+func main() {
+	fs := flag.NewFlagSet("shorturl-admin", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "print JSON instead of tables")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	_ = fs.Parse(os.Args[1:])
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.MustLoad()
+
+	st, err := setupStorage(cfg.Storage)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "can't connect to storage:", err)
+		os.Exit(1)
+	}
+
+	a := &admin{
+		st:        st,
+		out:       printer{w: os.Stdout, json: *jsonOutput},
+		in:        os.Stdin,
+		chunkSize: cfg.Batch.ChunkSize,
+	}
+	err = checkSchema(st)
+	if err == nil {
+		err = a.run(fs.Args())
+	}
+	if closeErr := st.Close(); closeErr != nil {
+		fmt.Fprintln(os.Stderr, "failed to close storage:", closeErr)
+	}
+
+	var usageErr usageError
+	switch {
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(os.Stderr, "%s\n\n%s", err, usage)
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// checkSchema refuses to work on a database the server hasn't migrated yet
+func checkSchema(st adminStorage) error {
+	migrator, err := st.Migrator()
+	if err != nil {
+		return fmt.Errorf("can't load migrations: %w", err)
+	}
+	statuses, err := migrator.Status()
+	if err != nil {
+		return fmt.Errorf("can't read migrations: %w", err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			return fmt.Errorf("migration %04d_%s is pending, start the server or run its migrate up first", s.Version, s.Name)
+		}
+	}
+	return nil
+}
+
+// usageError is a command line the commands can't run, it is printed with the usage
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func usagef(format string, args ...any) error {
+	return usageError(fmt.Sprintf(format, args...))
+}
+
+// admin runs the commands against the storage
+type admin struct {
+	st  storage.Repository
+	out printer
+	// input of import -
+	in io.Reader
+	// links saved in one transaction of an import
+	chunkSize int
+}
+
+func (a *admin) run(args []string) error {
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "links":
+		return a.subcommand(cmd, args, map[string]func([]string) error{
+			"list":   a.linksList,
+			"get":    a.linksGet,
+			"delete": a.linksDelete,
+			"update": a.linksUpdate,
+		})
+	case "events":
+		return a.subcommand(cmd, args, map[string]func([]string) error{
+			"list":    a.eventsList,
+			"requeue": a.eventsRequeue,
+			"purge":   a.eventsPurge,
+		})
+	case "keys":
+		return a.subcommand(cmd, args, map[string]func([]string) error{
+			"create": a.keysCreate,
+			"revoke": a.keysRevoke,
+		})
+	case "import":
+		return a.importLinks(args)
+	case "export":
+		return a.exportLinks(args)
+	case "stats":
+		return a.stats(args)
+	default:
+		return usagef("unknown command %q", cmd)
+	}
+}
+
+func (a *admin) subcommand(cmd string, args []string, subcommands map[string]func([]string) error) error {
+	if len(args) == 0 {
+		return usagef("%s needs a subcommand", cmd)
+	}
+	run, ok := subcommands[args[0]]
+	if !ok {
+		return usagef("unknown subcommand %q of %s", args[0], cmd)
+	}
+	return run(args[1:])
+}
+
+// newFlags returns the flag set of a command, its errors are returned instead of exiting
+func newFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags parses the flags of a command and returns its positional args, flags may follow them too
+// (links get abc -domain go.example.com)
+func parseFlags(fs *flag.FlagSet, args []string, minArgs, maxArgs int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usagef("%s: %s", fs.Name(), err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) < minArgs || (maxArgs >= 0 && len(positional) > maxArgs) {
+		return nil, usagef("%s: wrong number of arguments", fs.Name())
+	}
+	return positional, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// printer writes the results of the commands as aligned tables for people or as JSON for scripts
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+// table is the tabular form of a result
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// print writes v as indented JSON, or the tables separated by empty lines when tables are printed
+func (p printer) print(v any, tables ...table) error {
+	if p.json {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	for i, t := range tables {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+	}
+	return tw.Flush()
+}
+
+// formatTime returns the cell of a time, - for nil
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+// orDash returns the cell of an optional value
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"short-url/internal/http-server/model/domain"
+	"strconv"
+	"time"
+)
+
+// stats prints the click stats of the alias like GET /url/{alias}/stats,
+// or without an alias the links the workspace created in a month
+func (a *admin) stats(args []string) error {
+	fs := newFlags("stats")
+	workspaceID, host := refFlags(fs)
+	from := fs.String("from", "", "start of the range in RFC 3339, 7 days before to by default")
+	to := fs.String("to", "", "end of the range in RFC 3339, now by default")
+	bucket := fs.String("bucket", domain.BucketDay, "hour or day")
+	period := fs.String("period", domain.UsagePeriod(time.Now()), "month of the workspace usage, e.g. 2025-01")
+	pos, err := parseFlags(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+
+	if len(pos) == 0 {
+		usage, err := a.st.GetWorkspaceUsage(*workspaceID, *period)
+		if err != nil {
+			return err
+		}
+		return a.out.print(usage, table{
+			header: []string{"WORKSPACE", "PERIOD", "LINKS_CREATED"},
+			rows:   [][]string{{formatInt(usage.WorkspaceID), usage.Period, formatInt(usage.LinksCreated)}},
+		})
+	}
+
+	end := time.Now()
+	if *to != "" {
+		if end, err = time.Parse(time.RFC3339, *to); err != nil {
+			return usagef("stats: to is not in RFC 3339 format")
+		}
+	}
+	start := end.Add(-7 * 24 * time.Hour)
+	if *from != "" {
+		if start, err = time.Parse(time.RFC3339, *from); err != nil {
+			return usagef("stats: from is not in RFC 3339 format")
+		}
+	}
+	if !start.Before(end) {
+		return usagef("stats: from must be before to")
+	}
+	if *bucket != domain.BucketDay && *bucket != domain.BucketHour {
+		return usagef("stats: bucket must be hour or day")
+	}
+
+	link, err := a.st.GetLink(linkRef(*workspaceID, *host, pos[0]))
+	if err != nil {
+		return err
+	}
+	stats, err := a.st.GetClickStats(link.ID, start, end, *bucket)
+	if err != nil {
+		return err
+	}
+
+	//unique visitors of a range with purged raw clicks are only known per bucket
+	unique := "-"
+	if stats.UniqueVisitors != nil {
+		unique = strconv.Itoa(*stats.UniqueVisitors)
+	}
+	buckets := table{header: []string{"START", "CLICKS", "UNIQUE_VISITORS"}}
+	for _, b := range stats.Buckets {
+		buckets.rows = append(buckets.rows, []string{formatTime(&b.Start), strconv.Itoa(b.Clicks), strconv.Itoa(b.UniqueVisitors)})
+	}
+	return a.out.print(stats,
+		table{
+			header: []string{"ALIAS", "FROM", "TO", "SOURCE", "TOTAL", "UNIQUE_VISITORS"},
+			rows: [][]string{{
+				stats.Alias,
+				formatTime(&stats.From),
+				formatTime(&stats.To),
+				stats.Source,
+				strconv.Itoa(stats.Total),
+				unique,
+			}},
+		},
+		buckets,
+		breakdown("REFERRER", stats.Referrers),
+		breakdown("BROWSER", stats.Browsers),
+		breakdown("COUNTRY", stats.Countries),
+	)
+}
+
+// breakdown is the table of the top values of a dimension
+func breakdown(dimension string, counts []domain.ClickCount) table {
+	t := table{header: []string{dimension, "CLICKS"}}
+	for _, c := range counts {
+		t.rows = append(t.rows, []string{orDash(c.Value), strconv.Itoa(c.Clicks)})
+	}
+	return t
+}
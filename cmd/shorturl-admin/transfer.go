@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/lib/linkio"
+	"strconv"
+	"strings"
+)
+
+// formatOf returns the format of a file by its extension, csv unless it is .ndjson or .jsonl
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return linkio.FormatNDJSON
+	default:
+		return linkio.FormatCSV
+	}
+}
+
+// importLinks imports a file like POST /url/import without its row limit, - reads the standard input.
+// An import aborted by -on-conflict fail prints its summary and fails.
+func (a *admin) importLinks(args []string) error {
+	fs := newFlags("import")
+	workspaceID := fs.Int64("workspace", domain.DefaultWorkspaceID, "workspace of the links")
+	format := fs.String("format", "", "csv or ndjson, by default from the file extension")
+	onConflict := fs.String("on-conflict", linkio.ConflictSkip, "skip, overwrite or fail for taken aliases")
+	dryRun := fs.Bool("dry-run", false, "only report what would be imported")
+	pos, err := parseFlags(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	switch *onConflict {
+	case linkio.ConflictSkip, linkio.ConflictOverwrite, linkio.ConflictFail:
+	default:
+		return usagef("import: on-conflict must be skip, overwrite or fail")
+	}
+
+	in := a.in
+	if pos[0] != "-" {
+		f, err := os.Open(pos[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	if *format == "" {
+		*format = formatOf(pos[0])
+	}
+
+	r, err := linkio.NewReader(in, *format)
+	if err != nil {
+		return err
+	}
+	sum, err := linkio.Import(a.st, r, linkio.ImportOptions{
+		WorkspaceID: *workspaceID,
+		OnConflict:  *onConflict,
+		DryRun:      *dryRun,
+		ChunkSize:   a.chunkSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	errs := table{header: []string{"LINE", "ALIAS", "ERROR"}}
+	for _, e := range sum.Errors {
+		errs.rows = append(errs.rows, []string{strconv.Itoa(e.Line), orDash(e.Alias), e.Error})
+	}
+	tables := []table{{
+		header: []string{"ROWS", "CREATED", "UPDATED", "SKIPPED", "FAILED", "DRY_RUN"},
+		rows: [][]string{{
+			strconv.Itoa(sum.Rows),
+			strconv.Itoa(sum.Created),
+			strconv.Itoa(sum.Updated),
+			strconv.Itoa(sum.Skipped),
+			strconv.Itoa(sum.Failed),
+			strconv.FormatBool(sum.DryRun),
+		}},
+	}}
+	if len(errs.rows) > 0 {
+		tables = append(tables, errs)
+	}
+	if err := a.out.print(sum, tables...); err != nil {
+		return err
+	}
+
+	if sum.Aborted {
+		return errors.New("import aborted, nothing is saved")
+	}
+	return nil
+}
+
+type exportedLinks struct {
+	File     string `json:"file"`
+	Format   string `json:"format"`
+	Exported int    `json:"exported"`
+}
+
+// exportLinks writes the links of the workspace like GET /url/export to the standard output,
+// or to the -o file and then prints the number of exported links
+func (a *admin) exportLinks(args []string) error {
+	fs := newFlags("export")
+	workspaceID := fs.Int64("workspace", domain.DefaultWorkspaceID, "workspace of the links")
+	format := fs.String("format", "", "csv or ndjson, by default from the file extension")
+	clicks := fs.Bool("clicks", false, "add the total clicks of every link")
+	output := fs.String("o", "", "output file, the standard output if empty")
+	if _, err := parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = formatOf(*output)
+	}
+
+	var out io.Writer = a.out.w
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w, err := linkio.NewWriter(out, *format, *clicks)
+	if err != nil {
+		return usagef("export: %s", err)
+	}
+	n, err := linkio.Export(a.st, w, *workspaceID, *clicks)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return nil
+	}
+
+	res := exportedLinks{File: *output, Format: *format, Exported: n}
+	return a.out.print(res, table{
+		header: []string{"FILE", "FORMAT", "EXPORTED"},
+		rows:   [][]string{{res.File, res.Format, strconv.Itoa(res.Exported)}},
+	})
+}
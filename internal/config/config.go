@@ -9,18 +9,170 @@ import (
 )
 
 type Config struct {
-	Env         string `yaml:"env" env:"ENV" env-default:"local"`
-	StoragePath string `yaml:"storage_path" env-requered:"true"`
-	HTTPServer  `yaml:"http_server"`
+	Env             string `yaml:"env" env:"ENV" env-default:"local"`
+	Storage         `yaml:"storage"`
+	HTTPServer      `yaml:"http_server"`
+	EventSender     `yaml:"event_sender"`
+	ClickTracker    `yaml:"click_tracker"`
+	Janitor         `yaml:"janitor"`
+	ClickAggregator `yaml:"click_aggregator"`
+	Domains         `yaml:"domains"`
+	RateLimit       `yaml:"rate_limit"`
+	Aliases         `yaml:"aliases"`
+	Idempotency     `yaml:"idempotency"`
+	Batch           `yaml:"batch"`
+}
+
+type Storage struct {
+	// sqlite or postgres
+	Driver string `yaml:"driver" env:"STORAGE_DRIVER" env-default:"sqlite"`
+	// path to the sqlite database file
+	Path string `yaml:"path" env:"STORAGE_PATH"`
+	// postgres connection string
+	DSN string `yaml:"dsn" env:"STORAGE_DSN"`
 }
 
 type HTTPServer struct {
-	Address           string        `yaml:"address" env-default:"localhost:9000"`
-	Timeout           time.Duration `yaml:"timeout" env-default:"4s"`
-	IdleTimeout       time.Duration `yaml:"idle_timeout" env-default:"60s"`
-	User              string        `yaml:"user" env-requered:"true"`
-	Password          string        `yaml:"password" env-requered:"true" env:"HTTP_SERVER_PASSWORD"`
-	EventSenderPeriod time.Duration `yaml:"event_sender_period" env-default:"5s"`
+	Address     string        `yaml:"address" env-default:"localhost:9000"`
+	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
+	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	// time given to in-flight requests on SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"10s"`
+	User            string        `yaml:"user" env-requered:"true"`
+	Password        string        `yaml:"password" env-requered:"true" env:"HTTP_SERVER_PASSWORD"`
+	// answer errors with the old {"status","error"} body and 200 instead of problem+json
+	LegacyResponses bool `yaml:"legacy_responses" env-default:"false"`
+}
+
+type EventSender struct {
+	Period time.Duration `yaml:"period" env-default:"5s"`
+	// how long a claimed event stays reserved for the replica before it may be claimed again
+	Lease time.Duration `yaml:"lease" env-default:"30s"`
+	// max events claimed and marked as done at once
+	BatchSize int `yaml:"batch_size" env-default:"100"`
+	// max time of one drain cycle, the rest of the backlog waits for the next tick
+	MaxDrainTime time.Duration `yaml:"max_drain_time" env-default:"1m"`
+	// failed deliveries before the event becomes dead
+	MaxAttempts int `yaml:"max_attempts" env-default:"10"`
+	// exponential backoff between attempts: retry_base_delay*2^(attempt-1) capped by retry_max_delay
+	RetryBaseDelay time.Duration `yaml:"retry_base_delay" env-default:"1s"`
+	RetryMaxDelay  time.Duration `yaml:"retry_max_delay" env-default:"1h"`
+	// CloudEvents source attribute of published events
+	Source    string `yaml:"source" env-default:"/short-url"`
+	Publisher `yaml:"publisher"`
+}
+
+type Publisher struct {
+	// log, webhook, file or kafka
+	Type    string `yaml:"type" env:"PUBLISHER_TYPE" env-default:"log"`
+	Webhook struct {
+		URL     string        `yaml:"url" env:"PUBLISHER_WEBHOOK_URL"`
+		Secret  string        `yaml:"secret" env:"PUBLISHER_WEBHOOK_SECRET"`
+		Timeout time.Duration `yaml:"timeout" env-default:"5s"`
+	} `yaml:"webhook"`
+	File struct {
+		Path string `yaml:"path" env-default:"./storage/events.ndjson"`
+	} `yaml:"file"`
+	Kafka struct {
+		Brokers []string      `yaml:"brokers" env:"PUBLISHER_KAFKA_BROKERS"`
+		Topic   string        `yaml:"topic" env-default:"short-url.events"`
+		Timeout time.Duration `yaml:"timeout" env-default:"10s"`
+	} `yaml:"kafka"`
+}
+
+type ClickTracker struct {
+	// share of redirects written to the outbox as url_clicked events, 0 disables them
+	EventSampleRate float64 `yaml:"event_sample_rate" env-default:"0"`
+	// salt of the client ip hash used to count unique visitors
+	IPHashSalt string `yaml:"ip_hash_salt" env:"CLICK_TRACKER_IP_HASH_SALT"`
+	// clicks waiting to be written, new ones are dropped when it is full
+	BufferSize    int           `yaml:"buffer_size" env-default:"1024"`
+	BatchSize     int           `yaml:"batch_size" env-default:"100"`
+	FlushInterval time.Duration `yaml:"flush_interval" env-default:"1s"`
+}
+
+type Janitor struct {
+	Period    time.Duration `yaml:"period" env-default:"1m"`
+	BatchSize int           `yaml:"batch_size" env-default:"100"`
+	// purge deletes expired links, archive moves them to the url_archive table
+	Mode string `yaml:"mode" env-default:"purge"`
+}
+
+type ClickAggregator struct {
+	Period time.Duration `yaml:"period" env-default:"10m"`
+	// hours are rolled up once they ended this long ago, so clicks the tracker flushes late are counted.
+	// It is raised to flush_interval of click_tracker if it is shorter.
+	Lag time.Duration `yaml:"lag" env-default:"1m"`
+	// raw clicks older than it are deleted once they are rolled up
+	Retention time.Duration `yaml:"retention" env-default:"720h"`
+	BatchSize int           `yaml:"batch_size" env-default:"1000"`
+}
+
+type Domains struct {
+	// base url of the default domain, it serves the hosts without a verified custom domain
+	DefaultURL string `yaml:"default_url" env:"DOMAINS_DEFAULT_URL" env-default:"http://localhost:9000"`
+	// scheme of the short urls on custom domains
+	Scheme string `yaml:"scheme" env-default:"https"`
+	// timeout of the TXT lookup verifying a domain
+	VerifyTimeout time.Duration `yaml:"verify_timeout" env-default:"5s"`
+}
+
+type RateLimit struct {
+	// memory keeps the buckets in the process, every replica limits on its own
+	Backend string `yaml:"backend" env-default:"memory"`
+	// ip addresses and CIDR ranges of the proxies whose X-Forwarded-For header is trusted
+	TrustedProxies []string `yaml:"trusted_proxies" env:"RATE_LIMIT_TRUSTED_PROXIES"`
+	// link creation per api key, or per client ip for the root key
+	Create Limit `yaml:"create"`
+	// redirects per client ip
+	Redirect Limit `yaml:"redirect"`
+	// requests to the authenticated routes per client ip, taken before the api key is checked
+	// so requests with wrong keys are throttled too
+	Auth Limit `yaml:"auth"`
+}
+
+// Limit is a token bucket of requests per period with bursts of up to burst requests
+type Limit struct {
+	// 0 disables the limit
+	Requests int           `yaml:"requests" env-default:"0"`
+	Period   time.Duration `yaml:"period" env-default:"1m"`
+	Burst    int           `yaml:"burst" env-default:"1"`
+}
+
+type Aliases struct {
+	// random, or base62 and sqids encoding the next id of the alias sequence
+	Strategy string `yaml:"strategy" env-default:"random"`
+	// alphabet of the generated aliases: base62, unambiguous (no 0/O/o, 1/l/I), lowercase
+	// or the characters of a custom one
+	Alphabet string `yaml:"alphabet" env-default:"base62"`
+	// secret shuffling the alphabet of base62 and sqids codes, so they don't reveal the number of links
+	Salt string `yaml:"salt" env:"ALIASES_SALT"`
+	// sqids codes are padded to it
+	MinLength int `yaml:"min_length" env-default:"0"`
+	// length of the random aliases, grown up to max_length while they keep colliding
+	Length    int `yaml:"length" env-default:"6"`
+	MaxLength int `yaml:"max_length" env-default:"12"`
+	// collisions in a row of one save after which the length grows by one
+	GrowAfter int `yaml:"grow_after" env-default:"2"`
+	// saves of a link with a generated alias before giving up
+	MaxAttempts int `yaml:"max_attempts" env-default:"5"`
+}
+
+type Idempotency struct {
+	// responses of the requests with an Idempotency-Key header are replayed to their retries for it, 0 disables the keys
+	Window time.Duration `yaml:"window" env-default:"24h"`
+	// bodies of requests with a key are read into memory to hash them, longer ones get 413
+	MaxBodyBytes int64 `yaml:"max_body_bytes" env-default:"10485760"`
+}
+
+type Batch struct {
+	// items of one POST /url/batch request
+	MaxItems int `yaml:"max_items" env-default:"1000"`
+	// links saved in one transaction of a partial batch, atomic batches are saved in one transaction,
+	// 0 or less saves the whole batch in one transaction
+	ChunkSize int `yaml:"chunk_size" env-default:"100"`
+	// rows of one POST /url/import request, imports are saved in transactions of chunk_size links too
+	MaxImportRows int `yaml:"max_import_rows" env-default:"100000"`
 }
 
 // functions with the 'Must...' name usually return panic
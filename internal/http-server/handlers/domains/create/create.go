@@ -0,0 +1,115 @@
+package create
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/domainverify"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Request struct {
+	Hostname string `json:"hostname" validate:"required,fqdn"`
+}
+
+// TXTRecord is the DNS record proving the ownership of the domain
+type TXTRecord struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Response struct {
+	responseModel.Response
+	domain.Domain
+	TXTRecord TXTRecord `json:"txt_record"`
+}
+
+//go:generate mockery --name=DomainCreator
+type DomainCreator interface {
+	CreateDomain(d domain.Domain) (int64, error)
+}
+
+// New adds an unverified custom domain to the workspace of the request,
+// it is served once the returned TXT record is created and verified.
+func New(log *slog.Logger, creator DomainCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.domains.create.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("can't decode request body", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "can't decode request body")
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validErrs := err.(validator.ValidationErrors)
+
+			log.Error("invalid request body", sl.Err(err))
+
+			responseModel.RenderValidationError(w, r, validErrs)
+			return
+		}
+
+		token, err := domainverify.NewToken()
+		if err != nil {
+			log.Error("failed to generate verification token", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to add domain")
+			return
+		}
+
+		d := domain.Domain{
+			WorkspaceID: workspaceID,
+			//hosts are matched case-insensitively
+			Hostname:          strings.ToLower(req.Hostname),
+			VerificationToken: token,
+			CreatedAt:         time.Now().UTC(),
+		}
+		d.ID, err = creator.CreateDomain(d)
+		if errors.Is(err, storage.ErrDomainExists) {
+			log.Info("domain already exists", slog.String("hostname", d.Hostname))
+			responseModel.RenderError(w, r, http.StatusConflict, "domain already exists")
+			return
+		}
+		if err != nil {
+			log.Error("failed to add domain", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to add domain")
+			return
+		}
+		log.Info("domain added", slog.Int64("id", d.ID), slog.String("hostname", d.Hostname))
+
+		responseModel.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{
+			Response: responseModel.OK(),
+			Domain:   d,
+			TXTRecord: TXTRecord{
+				Name:  domainverify.RecordName(d.Hostname),
+				Value: domainverify.RecordValue(token),
+			},
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package create_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/domains/create"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"strings"
+	"testing"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey authenticates the requests, domains are added to its workspace
+var testKey = domain.APIKey{ID: 1, WorkspaceID: 3, Scopes: []string{domain.ScopeLinksWrite}}
+
+func TestCreateHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name: "Success",
+			body: `{"hostname": "Go.Example"}`,
+		},
+		{
+			name:      "Invalid hostname",
+			body:      `{"hostname": "not a host"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid body",
+		},
+		{
+			name:      "Exists",
+			body:      `{"hostname": "go.example"}`,
+			respCode:  http.StatusConflict,
+			respError: "domain already exists",
+			mockError: storage.ErrDomainExists,
+		},
+		{
+			name:      "Storage error",
+			body:      `{"hostname": "go.example"}`,
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to add domain",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			creatorMock := create.NewMockDomainCreator(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				creatorMock.On("CreateDomain", mock.MatchedBy(func(d domain.Domain) bool {
+					return d.Hostname == "go.example" && d.WorkspaceID == testKey.WorkspaceID &&
+						d.VerificationToken != "" && !d.Verified()
+				})).Return(int64(2), tc.mockError).Once()
+			}
+
+			handler := create.New(silentlog.NewSilentLogger(), creatorMock)
+
+			req, err := http.NewRequest(http.MethodPost, "/domains", bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+			require.Equal(t, http.StatusCreated, rr.Code)
+
+			var resp create.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, int64(2), resp.ID)
+			require.Equal(t, "_short-url-verification.go.example", resp.TXTRecord.Name)
+			require.True(t, strings.HasSuffix(resp.TXTRecord.Value, resp.VerificationToken))
+		})
+	}
+}
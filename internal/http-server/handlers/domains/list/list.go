@@ -0,0 +1,55 @@
+package list
+
+import (
+	"log/slog"
+	"net/http"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	responseModel.Response
+	Domains []domain.Domain `json:"domains"`
+}
+
+//go:generate mockery --name=DomainLister
+type DomainLister interface {
+	ListDomains(workspaceID int64) ([]domain.Domain, error)
+}
+
+// New lists the custom domains of the workspace of the request.
+func New(log *slog.Logger, lister DomainLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.domains.list.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		domains, err := lister.ListDomains(workspaceID)
+		if err != nil {
+			log.Error("failed to list domains", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to list domains")
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response: responseModel.OK(),
+			Domains:  domains,
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package list_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/domains/list"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testKey authenticates the requests, the domains of its workspace are listed
+var testKey = domain.APIKey{ID: 1, WorkspaceID: 3, Scopes: []string{domain.ScopeLinksRead}}
+
+func TestListHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		domains   []domain.Domain
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:    "Success",
+			domains: []domain.Domain{{ID: 1, WorkspaceID: 3, Hostname: "go.example"}},
+		},
+		{
+			name:    "Empty",
+			domains: []domain.Domain{},
+		},
+		{
+			name:      "Storage error",
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to list domains",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			listerMock := list.NewMockDomainLister(t)
+			listerMock.On("ListDomains", testKey.WorkspaceID).Return(tc.domains, tc.mockError).Once()
+
+			handler := list.New(silentlog.NewSilentLogger(), listerMock)
+
+			req, err := http.NewRequest(http.MethodGet, "/domains", nil)
+			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp list.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.domains, resp.Domains)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/domainverify"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	responseModel.Response
+	domain.Domain
+}
+
+//go:generate mockery --name=DomainStore
+type DomainStore interface {
+	GetWorkspaceDomain(workspaceID int64, hostname string) (domain.Domain, error)
+	VerifyDomain(id int64, verifiedAt time.Time) error
+}
+
+// Verifier is implemented by *domainverify.Verifier
+type Verifier interface {
+	Verify(ctx context.Context, hostname string, token string) error
+}
+
+// New looks up the TXT record of the {hostname} domain and marks it as verified if it has the token.
+// Claims of other workspaces are reported as not found, the first workspace to verify the hostname gets it.
+func New(log *slog.Logger, store DomainStore, verifier Verifier, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.domains.verify.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		hostname := strings.ToLower(chi.URLParam(r, "hostname"))
+		d, err := store.GetWorkspaceDomain(workspaceID, hostname)
+		if err != nil {
+			if errors.Is(err, storage.ErrDomainNotFound) {
+				log.Info("domain not found", slog.String("hostname", hostname))
+				responseModel.RenderError(w, r, http.StatusNotFound, "domain not found")
+			} else {
+				log.Error("failed to get domain", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to verify domain")
+			}
+			return
+		}
+
+		if !d.Verified() {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			err = verifier.Verify(ctx, d.Hostname, d.VerificationToken)
+			if errors.Is(err, domainverify.ErrNotVerified) {
+				log.Info("verification record not found", slog.String("hostname", hostname))
+				responseModel.RenderError(w, r, http.StatusUnprocessableEntity,
+					"TXT record "+domainverify.RecordName(d.Hostname)+" with the value "+domainverify.RecordValue(d.VerificationToken)+" not found")
+				return
+			}
+			if err != nil {
+				log.Error("failed to look up verification record", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusBadGateway, "failed to look up the TXT record")
+				return
+			}
+
+			verifiedAt := time.Now().UTC()
+			err = store.VerifyDomain(d.ID, verifiedAt)
+			if errors.Is(err, storage.ErrDomainExists) {
+				log.Info("domain verified by another workspace", slog.String("hostname", hostname))
+				responseModel.RenderError(w, r, http.StatusConflict, "domain is verified by another workspace")
+				return
+			}
+			if err != nil {
+				log.Error("failed to verify domain", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to verify domain")
+				return
+			}
+			d.VerifiedAt = &verifiedAt
+			log.Info("domain verified", slog.String("hostname", hostname))
+		}
+
+		render.JSON(w, r, Response{
+			Response: responseModel.OK(),
+			Domain:   d,
+		})
+	}
+}
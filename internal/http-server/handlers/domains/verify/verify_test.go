@@ -0,0 +1,120 @@
+package verify_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/domains/verify"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/domainverify"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey authenticates the requests, only the domains of its workspace are verified
+var testKey = domain.APIKey{ID: 1, WorkspaceID: 3, Scopes: []string{domain.ScopeLinksWrite}}
+
+func TestVerifyHandler(t *testing.T) {
+	verifiedAt := time.Now()
+	unverified := domain.Domain{ID: 7, WorkspaceID: 3, Hostname: "go.example", VerificationToken: "token"}
+
+	cases := []struct {
+		name     string
+		domain   domain.Domain
+		resolver domainverify.FakeResolver
+		// the record is found and the domain is marked as verified
+		verifies    bool
+		verifyError error
+		respCode    int
+		respError   string
+		mockError   error
+	}{
+		{
+			name:     "Success",
+			domain:   unverified,
+			resolver: domainverify.FakeResolver{"_short-url-verification.go.example": {"short-url-verification=token"}},
+			verifies: true,
+		},
+		{
+			name:   "Already verified",
+			domain: domain.Domain{ID: 7, WorkspaceID: 3, Hostname: "go.example", VerifiedAt: &verifiedAt},
+		},
+		{
+			name:      "No record",
+			domain:    unverified,
+			resolver:  domainverify.FakeResolver{"_short-url-verification.go.example": {"short-url-verification=other"}},
+			respCode:  http.StatusUnprocessableEntity,
+			respError: "TXT record _short-url-verification.go.example with the value short-url-verification=token not found",
+		},
+		{
+			name:        "Verified by another workspace",
+			domain:      unverified,
+			resolver:    domainverify.FakeResolver{"_short-url-verification.go.example": {"short-url-verification=token"}},
+			verifies:    true,
+			verifyError: storage.ErrDomainExists,
+			respCode:    http.StatusConflict,
+			respError:   "domain is verified by another workspace",
+		},
+		{
+			name:      "Not found",
+			respCode:  http.StatusNotFound,
+			respError: "domain not found",
+			mockError: storage.ErrDomainNotFound,
+		},
+		{
+			name:      "Storage error",
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to verify domain",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			storeMock := verify.NewMockDomainStore(t)
+			//claims of other workspaces are not found by the storage
+			storeMock.On("GetWorkspaceDomain", testKey.WorkspaceID, "go.example").Return(tc.domain, tc.mockError).Once()
+			if tc.verifies {
+				storeMock.On("VerifyDomain", int64(7), mock.AnythingOfType("time.Time")).Return(tc.verifyError).Once()
+			}
+
+			//here using chi becouse there is URL param {hostname}
+			r := chi.NewRouter()
+			verifier := domainverify.New(tc.resolver)
+			r.Post("/domains/{hostname}/verify", verify.New(silentlog.NewSilentLogger(), storeMock, verifier, time.Second))
+
+			req, err := http.NewRequest(http.MethodPost, "/domains/Go.Example/verify", nil)
+			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var resp verify.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.True(t, resp.Verified())
+		})
+	}
+}
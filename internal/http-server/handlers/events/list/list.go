@@ -0,0 +1,64 @@
+package list
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type Response struct {
+	responseModel.Response
+	Events []domain.DeadEvent `json:"events"`
+}
+
+//go:generate mockery --name=DeadEventsLister
+type DeadEventsLister interface {
+	ListDeadEvents(limit int) ([]domain.DeadEvent, error)
+}
+
+// New lists dead events, ?limit= caps the number of returned events.
+func New(log *slog.Logger, lister DeadEventsLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.events.list.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		limit := defaultLimit
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			l, err := strconv.Atoi(raw)
+			if err != nil || l <= 0 || l > maxLimit {
+				log.Info("invalid limit", slog.String("limit", raw))
+				responseModel.RenderError(w, r, http.StatusBadRequest, "invalid limit")
+				return
+			}
+			limit = l
+		}
+
+		events, err := lister.ListDeadEvents(limit)
+		if err != nil {
+			log.Error("failed to list dead events", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to list dead events")
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response: responseModel.OK(),
+			Events:   events,
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package list_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/events/list"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		limit     int
+		events    []domain.DeadEvent
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:   "Success",
+			limit:  100,
+			events: []domain.DeadEvent{{Event: domain.Event{ID: 1, EventType: "url_saved"}, Attempts: 10, LastError: "timeout"}},
+		},
+		{
+			name:   "Custom limit",
+			query:  "?limit=5",
+			limit:  5,
+			events: []domain.DeadEvent{},
+		},
+		{
+			name:      "Invalid limit",
+			query:     "?limit=abc",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid limit",
+		},
+		{
+			name:      "Storage error",
+			limit:     100,
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to list dead events",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			listerMock := list.NewMockDeadEventsLister(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				listerMock.On("ListDeadEvents", tc.limit).Return(tc.events, tc.mockError).Once()
+			}
+
+			handler := list.New(silentlog.NewSilentLogger(), listerMock)
+
+			req, err := http.NewRequest(http.MethodGet, "/admin/events/dead"+tc.query, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp list.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Len(t, resp.Events, len(tc.events))
+		})
+	}
+}
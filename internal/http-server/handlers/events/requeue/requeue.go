@@ -0,0 +1,55 @@
+package requeue
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+//go:generate mockery --name=EventRequeuer
+type EventRequeuer interface {
+	RequeueEvent(eventID int) error
+}
+
+// New returns a dead event with the {id} url param to the outbox.
+func New(log *slog.Logger, requeuer EventRequeuer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.events.requeue.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Info("invalid event id", slog.String("id", chi.URLParam(r, "id")))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		err = requeuer.RequeueEvent(id)
+		if err != nil {
+			if errors.Is(err, storage.ErrDeadEventNotFound) {
+				log.Info("dead event not found", slog.Int("event_id", id))
+				responseModel.RenderError(w, r, http.StatusNotFound, "dead event not found")
+			} else {
+				log.Error("failed to requeue event", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to requeue event")
+			}
+			return
+		}
+
+		log.Info("event requeued", slog.Int("event_id", id))
+		render.JSON(w, r, responseModel.OK())
+	}
+}
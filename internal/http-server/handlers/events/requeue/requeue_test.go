@@ -0,0 +1,91 @@
+package requeue_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/events/requeue"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequeueHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		id        string
+		eventID   int
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:    "Success",
+			id:      "42",
+			eventID: 42,
+		},
+		{
+			name:      "Invalid id",
+			id:        "abc",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid request",
+		},
+		{
+			name:      "Not dead",
+			id:        "42",
+			eventID:   42,
+			respCode:  http.StatusNotFound,
+			respError: "dead event not found",
+			mockError: storage.ErrDeadEventNotFound,
+		},
+		{
+			name:      "Storage error",
+			id:        "42",
+			eventID:   42,
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to requeue event",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			requeuerMock := requeue.NewMockEventRequeuer(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				requeuerMock.On("RequeueEvent", tc.eventID).Return(tc.mockError).Once()
+			}
+
+			//here using chi becouse there is URL param {id}
+			r := chi.NewRouter()
+			r.Post("/admin/events/{id}/requeue", requeue.New(silentlog.NewSilentLogger(), requeuerMock))
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/events/"+tc.id+"/requeue", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp responseModel.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, responseModel.StatusOK, resp.Status)
+		})
+	}
+}
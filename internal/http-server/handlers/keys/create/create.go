@@ -0,0 +1,118 @@
+package create
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/apikey"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Request struct {
+	// workspace the key is a member of, the workspace of the caller if omitted.
+	// Only global admins create keys of other workspaces.
+	WorkspaceID int64    `json:"workspace_id,omitempty"`
+	Name        string   `json:"name" validate:"required"`
+	Scopes      []string `json:"scopes" validate:"required,min=1,dive,oneof=links:write links:read admin"`
+}
+
+type Response struct {
+	responseModel.Response
+	domain.APIKey
+	// the secret key is only returned once, the storage keeps its hash
+	Key string `json:"key"`
+}
+
+//go:generate mockery --name=APIKeyCreator
+type APIKeyCreator interface {
+	CreateAPIKey(key domain.APIKey, keyHash string) (int64, error)
+}
+
+// New issues a new api key with the requested scopes.
+func New(log *slog.Logger, creator APIKeyCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.keys.create.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		callerWorkspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("can't decode request body", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "can't decode request body")
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validErrs := err.(validator.ValidationErrors)
+
+			log.Error("invalid request body", sl.Err(err))
+
+			responseModel.RenderValidationError(w, r, validErrs)
+			return
+		}
+
+		if req.WorkspaceID == 0 {
+			req.WorkspaceID = callerWorkspaceID
+		}
+		if !mwAuth.CanManage(r.Context(), req.WorkspaceID) {
+			log.Info("api key of another workspace", slog.Int64("workspace_id", req.WorkspaceID))
+			responseModel.RenderError(w, r, http.StatusForbidden, "api key can't manage the workspace")
+			return
+		}
+
+		secret, prefix, err := apikey.Generate()
+		if err != nil {
+			log.Error("failed to generate api key", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to create api key")
+			return
+		}
+
+		key := domain.APIKey{
+			WorkspaceID: req.WorkspaceID,
+			Name:        req.Name,
+			Prefix:      prefix,
+			Scopes:      req.Scopes,
+			CreatedAt:   time.Now().UTC(),
+		}
+		key.ID, err = creator.CreateAPIKey(key, apikey.Hash(secret))
+		if errors.Is(err, storage.ErrWorkspaceNotFound) {
+			log.Info("workspace not found", slog.Int64("workspace_id", key.WorkspaceID))
+			responseModel.RenderError(w, r, http.StatusNotFound, "workspace not found")
+			return
+		}
+		if err != nil {
+			log.Error("failed to create api key", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to create api key")
+			return
+		}
+		log.Info("api key created", slog.Int64("id", key.ID), slog.String("prefix", prefix))
+
+		responseModel.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{
+			Response: responseModel.OK(),
+			APIKey:   key,
+			Key:      secret,
+		})
+	}
+}
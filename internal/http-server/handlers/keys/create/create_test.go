@@ -0,0 +1,143 @@
+package create_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/keys/create"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/apikey"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"strings"
+	"testing"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateHandler(t *testing.T) {
+	workspaceAdmin := domain.APIKey{ID: 5, WorkspaceID: 7, Scopes: []string{domain.ScopeAdmin}}
+
+	cases := []struct {
+		name string
+		body string
+		//key of the request, the root key if empty
+		apiKey      domain.APIKey
+		workspaceID int64
+		respCode    int
+		respError   string
+		mockError   error
+	}{
+		{
+			name:        "Success",
+			body:        `{"name": "ci", "scopes": ["links:write", "links:read"]}`,
+			workspaceID: domain.DefaultWorkspaceID,
+		},
+		{
+			name:        "Global admin creates key of another workspace",
+			body:        `{"workspace_id": 7, "name": "ci", "scopes": ["links:read"]}`,
+			workspaceID: 7,
+		},
+		{
+			name:        "Workspace admin creates key of its workspace",
+			body:        `{"name": "ci", "scopes": ["admin"]}`,
+			apiKey:      workspaceAdmin,
+			workspaceID: 7,
+		},
+		{
+			name:      "Workspace admin creates key of another workspace",
+			body:      `{"workspace_id": 1, "name": "ci", "scopes": ["admin"]}`,
+			apiKey:    workspaceAdmin,
+			respCode:  http.StatusForbidden,
+			respError: "api key can't manage the workspace",
+		},
+		{
+			name:      "Empty name",
+			body:      `{"scopes": ["links:read"]}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid body",
+		},
+		{
+			name:      "No scopes",
+			body:      `{"name": "ci"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid body",
+		},
+		{
+			name:      "Unknown scope",
+			body:      `{"name": "ci", "scopes": ["links:delete"]}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid body",
+		},
+		{
+			name:        "Unknown workspace",
+			body:        `{"workspace_id": 42, "name": "ci", "scopes": ["links:read"]}`,
+			workspaceID: 42,
+			respCode:    http.StatusNotFound,
+			respError:   "workspace not found",
+			mockError:   storage.ErrWorkspaceNotFound,
+		},
+		{
+			name:        "Storage error",
+			body:        `{"name": "ci", "scopes": ["admin"]}`,
+			workspaceID: domain.DefaultWorkspaceID,
+			respCode:    http.StatusInternalServerError,
+			respError:   "failed to create api key",
+			mockError:   errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			creatorMock := create.NewMockAPIKeyCreator(t)
+
+			var keyHash string
+			if tc.respError == "" || tc.mockError != nil {
+				creatorMock.On("CreateAPIKey", mock.MatchedBy(func(key domain.APIKey) bool {
+					return key.Name != "" && len(key.Scopes) > 0 && key.Prefix != "" && key.WorkspaceID == tc.workspaceID
+				}), mock.Anything).Run(func(args mock.Arguments) {
+					keyHash = args.String(1)
+				}).Return(int64(3), tc.mockError).Once()
+			}
+
+			handler := create.New(silentlog.NewSilentLogger(), creatorMock)
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/keys", bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			apiKey := tc.apiKey
+			if apiKey.WorkspaceID == 0 {
+				apiKey = mwAuth.RootKey
+			}
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), apiKey))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+			require.Equal(t, http.StatusCreated, rr.Code)
+
+			var resp create.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, responseModel.StatusOK, resp.Status)
+			require.Equal(t, int64(3), resp.ID)
+			require.True(t, strings.HasPrefix(resp.Key, resp.Prefix))
+			//only the hash of the returned key is stored
+			require.Equal(t, apikey.Hash(resp.Key), keyHash)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package revoke
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+//go:generate mockery --name=APIKeyRevoker
+type APIKeyRevoker interface {
+	RevokeAPIKey(workspaceID, id int64) error
+}
+
+// New revokes the api key with the {id} url param,
+// keys of other workspaces are only found by global admins.
+func New(log *slog.Logger, revoker APIKeyRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.keys.revoke.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.ManagedWorkspace(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Info("invalid api key id", slog.String("id", chi.URLParam(r, "id")))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		err = revoker.RevokeAPIKey(workspaceID, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrAPIKeyNotFound) {
+				log.Info("api key not found", slog.Int64("id", id))
+				responseModel.RenderError(w, r, http.StatusNotFound, "api key not found")
+			} else {
+				log.Error("failed to revoke api key", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to revoke api key")
+			}
+			return
+		}
+
+		log.Info("api key revoked", slog.Int64("id", id))
+		render.JSON(w, r, responseModel.OK())
+	}
+}
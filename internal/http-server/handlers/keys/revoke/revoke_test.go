@@ -0,0 +1,123 @@
+package revoke_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/keys/revoke"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeHandler(t *testing.T) {
+	workspaceAdmin := domain.APIKey{ID: 5, WorkspaceID: 7, Scopes: []string{domain.ScopeAdmin}}
+
+	cases := []struct {
+		name string
+		id   string
+		//key of the request, the root key if empty
+		apiKey      domain.APIKey
+		keyID       int64
+		workspaceID int64
+		respCode    int
+		respError   string
+		mockError   error
+	}{
+		{
+			name:        "Success",
+			id:          "42",
+			keyID:       42,
+			workspaceID: storage.AnyWorkspace,
+		},
+		{
+			name:        "Workspace admin revokes key of its workspace",
+			id:          "42",
+			apiKey:      workspaceAdmin,
+			keyID:       42,
+			workspaceID: 7,
+		},
+		{
+			name:        "Workspace admin revokes key of another workspace",
+			id:          "43",
+			apiKey:      workspaceAdmin,
+			keyID:       43,
+			workspaceID: 7,
+			respCode:    http.StatusNotFound,
+			respError:   "api key not found",
+			mockError:   storage.ErrAPIKeyNotFound,
+		},
+		{
+			name:      "Invalid id",
+			id:        "abc",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid request",
+		},
+		{
+			name:        "Not found",
+			id:          "42",
+			keyID:       42,
+			workspaceID: storage.AnyWorkspace,
+			respCode:    http.StatusNotFound,
+			respError:   "api key not found",
+			mockError:   storage.ErrAPIKeyNotFound,
+		},
+		{
+			name:        "Storage error",
+			id:          "42",
+			keyID:       42,
+			workspaceID: storage.AnyWorkspace,
+			respCode:    http.StatusInternalServerError,
+			respError:   "failed to revoke api key",
+			mockError:   errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			revokerMock := revoke.NewMockAPIKeyRevoker(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				revokerMock.On("RevokeAPIKey", tc.workspaceID, tc.keyID).Return(tc.mockError).Once()
+			}
+
+			//here using chi becouse there is URL param {id}
+			r := chi.NewRouter()
+			r.Delete("/admin/keys/{id}", revoke.New(silentlog.NewSilentLogger(), revokerMock))
+
+			req, err := http.NewRequest(http.MethodDelete, "/admin/keys/"+tc.id, nil)
+			require.NoError(t, err)
+			apiKey := tc.apiKey
+			if apiKey.WorkspaceID == 0 {
+				apiKey = mwAuth.RootKey
+			}
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), apiKey))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp responseModel.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, responseModel.StatusOK, resp.Status)
+		})
+	}
+}
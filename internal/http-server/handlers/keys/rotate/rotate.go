@@ -0,0 +1,84 @@
+package rotate
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/apikey"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	responseModel.Response
+	domain.APIKey
+	// the new secret key, the previous one stops working
+	Key string `json:"key"`
+}
+
+//go:generate mockery --name=APIKeyRotator
+type APIKeyRotator interface {
+	RotateAPIKey(workspaceID, id int64, prefix string, keyHash string) (domain.APIKey, error)
+}
+
+// New replaces the secret of the api key with the {id} url param, its name and scopes are kept.
+// Keys of other workspaces are only found by global admins.
+func New(log *slog.Logger, rotator APIKeyRotator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.keys.rotate.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.ManagedWorkspace(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Info("invalid api key id", slog.String("id", chi.URLParam(r, "id")))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		secret, prefix, err := apikey.Generate()
+		if err != nil {
+			log.Error("failed to generate api key", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to rotate api key")
+			return
+		}
+
+		key, err := rotator.RotateAPIKey(workspaceID, id, prefix, apikey.Hash(secret))
+		if err != nil {
+			if errors.Is(err, storage.ErrAPIKeyNotFound) {
+				log.Info("api key not found", slog.Int64("id", id))
+				responseModel.RenderError(w, r, http.StatusNotFound, "api key not found")
+			} else {
+				log.Error("failed to rotate api key", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to rotate api key")
+			}
+			return
+		}
+
+		log.Info("api key rotated", slog.Int64("id", id), slog.String("prefix", prefix))
+		render.JSON(w, r, Response{
+			Response: responseModel.OK(),
+			APIKey:   key,
+			Key:      secret,
+		})
+	}
+}
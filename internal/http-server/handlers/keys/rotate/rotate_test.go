@@ -0,0 +1,132 @@
+package rotate_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/keys/rotate"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/apikey"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateHandler(t *testing.T) {
+	workspaceAdmin := domain.APIKey{ID: 3, WorkspaceID: 7, Scopes: []string{domain.ScopeAdmin}}
+
+	cases := []struct {
+		name string
+		id   string
+		//key of the request, the root key if empty
+		apiKey      domain.APIKey
+		keyID       int64
+		workspaceID int64
+		respCode    int
+		respError   string
+		mockError   error
+	}{
+		{
+			name:        "Success",
+			id:          "5",
+			keyID:       5,
+			workspaceID: storage.AnyWorkspace,
+		},
+		{
+			name:        "Workspace admin rotates key of its workspace",
+			id:          "5",
+			apiKey:      workspaceAdmin,
+			keyID:       5,
+			workspaceID: 7,
+		},
+		{
+			name:        "Workspace admin rotates key of another workspace",
+			id:          "6",
+			apiKey:      workspaceAdmin,
+			keyID:       6,
+			workspaceID: 7,
+			respCode:    http.StatusNotFound,
+			respError:   "api key not found",
+			mockError:   storage.ErrAPIKeyNotFound,
+		},
+		{
+			name:      "Invalid id",
+			id:        "abc",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid request",
+		},
+		{
+			name:        "Not found",
+			id:          "5",
+			keyID:       5,
+			workspaceID: storage.AnyWorkspace,
+			respCode:    http.StatusNotFound,
+			respError:   "api key not found",
+			mockError:   storage.ErrAPIKeyNotFound,
+		},
+		{
+			name:        "Storage error",
+			id:          "5",
+			keyID:       5,
+			workspaceID: storage.AnyWorkspace,
+			respCode:    http.StatusInternalServerError,
+			respError:   "failed to rotate api key",
+			mockError:   errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			rotatorMock := rotate.NewMockAPIKeyRotator(t)
+
+			var prefix, keyHash string
+			if tc.respError == "" || tc.mockError != nil {
+				rotatorMock.On("RotateAPIKey", tc.workspaceID, tc.keyID, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					prefix, keyHash = args.String(2), args.String(3)
+				}).Return(domain.APIKey{ID: tc.keyID, Name: "ci", Scopes: []string{domain.ScopeLinksRead}}, tc.mockError).Once()
+			}
+
+			//here using chi becouse there is URL param {id}
+			r := chi.NewRouter()
+			r.Post("/admin/keys/{id}/rotate", rotate.New(silentlog.NewSilentLogger(), rotatorMock))
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/keys/"+tc.id+"/rotate", nil)
+			require.NoError(t, err)
+			apiKey := tc.apiKey
+			if apiKey.WorkspaceID == 0 {
+				apiKey = mwAuth.RootKey
+			}
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), apiKey))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp rotate.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, responseModel.StatusOK, resp.Status)
+			require.Equal(t, tc.keyID, resp.ID)
+			require.True(t, strings.HasPrefix(resp.Key, prefix))
+			require.Equal(t, apikey.Hash(resp.Key), keyHash)
+		})
+	}
+}
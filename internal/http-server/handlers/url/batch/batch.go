@@ -0,0 +1,369 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"short-url/internal/http-server/handlers/url/save"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/shorturl"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// ModePartial saves the valid items and reports the rest
+	ModePartial = "partial"
+	// ModeAtomic saves all the items or none of them
+	ModeAtomic = "atomic"
+)
+
+// Result is the outcome of an item of the batch
+type Result struct {
+	// position of the item in the request
+	Index int `json:"index"`
+	// status code POST /url would answer the item with
+	Code      int        `json:"code"`
+	Alias     string     `json:"alias,omitempty"`
+	ShortURL  string     `json:"short_url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+type Response struct {
+	responseModel.Response
+	Created int      `json:"created"`
+	Failed  int      `json:"failed"`
+	Results []Result `json:"results"`
+}
+
+//go:generate mockery --name=LinkSaver
+type LinkSaver interface {
+	SaveURLs(links []domain.Link, opts storage.SaveOptions) ([]storage.SaveResult, error)
+	GetWorkspace(id int64) (domain.Workspace, error)
+}
+
+//go:generate mockery --name=AliasStrategy
+type AliasStrategy interface {
+	// Generate returns a random alias or the code of the next id of a sequence
+	// for the attempt of the save, attempts start at 1
+	Generate(attempt int) (string, error)
+	// Reserved reports whether a custom alias may be generated later
+	Reserved(alias string) bool
+}
+
+var errTooManyItems = errors.New("too many items")
+
+// item is a valid link of the batch waiting to be saved
+type item struct {
+	index     int
+	link      domain.Link
+	generated bool
+	// saves of the generated alias so far
+	attempt int
+}
+
+// New saves up to maxItems links of a JSON array, or of an NDJSON body (one item per line), with the fields of POST /url.
+// With ?mode=partial (the default) the items are saved in transactions of chunkSize links, invalid and rejected
+// items are reported in their results. With ?mode=atomic the batch is saved in one transaction, nothing is saved
+// if an item fails and the request gets 422. Taken generated aliases are regenerated up to maxAttempts times.
+func New(log *slog.Logger, saver LinkSaver, shortURLs shorturl.Builder, aliases AliasStrategy, maxItems, chunkSize, maxAttempts int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.batch.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		mode := r.URL.Query().Get("mode")
+		if mode == "" {
+			mode = ModePartial
+		}
+		if mode != ModePartial && mode != ModeAtomic {
+			responseModel.RenderError(w, r, http.StatusBadRequest, "mode must be partial or atomic")
+			return
+		}
+
+		reqs, err := decodeItems(r, maxItems)
+		if errors.Is(err, errTooManyItems) {
+			log.Info("batch is too large", slog.Int("max_items", maxItems))
+			responseModel.RenderError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("batch has more than %d items", maxItems))
+			return
+		}
+		if err != nil {
+			log.Error("can't decode request body", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "can't decode request body")
+			return
+		}
+		if len(reqs) == 0 {
+			responseModel.RenderError(w, r, http.StatusBadRequest, "batch is empty")
+			return
+		}
+		log.Info("batch decoded", slog.Int("items", len(reqs)), slog.String("mode", mode))
+
+		//the root key has no id and isn't recorded
+		var apiKeyID *int64
+		if key, _ := mwAuth.APIKeyFromContext(r.Context()); key.ID != 0 {
+			apiKeyID = &key.ID
+		}
+
+		//aliases of other workspaces on the default domain are under their slug
+		var slug string
+		if workspaceID != domain.DefaultWorkspaceID {
+			ws, err := saver.GetWorkspace(workspaceID)
+			if err != nil {
+				log.Error("failed to get workspace", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to add urls")
+				return
+			}
+			slug = ws.Slug
+		}
+
+		results := make([]Result, len(reqs))
+		var pending []*item
+		now := time.Now()
+		for i, req := range reqs {
+			link, err := req.Link(workspaceID, now)
+			if err == nil && req.Dedupe {
+				err = errors.New("dedupe isn't supported in batches")
+			}
+			if err == nil && req.Alias != "" && aliases.Reserved(req.Alias) {
+				err = save.ErrGeneratedAlias
+			}
+			if err != nil {
+				results[i] = Result{Index: i, Code: http.StatusBadRequest, Error: invalidMessage(err)}
+				continue
+			}
+			link.APIKeyID = apiKeyID
+			pending = append(pending, &item{index: i, link: link, generated: req.Alias == ""})
+		}
+
+		b := batch{saver: saver, aliases: aliases, maxAttempts: maxAttempts, results: results}
+		failed := false
+		if mode == ModeAtomic {
+			failed = len(pending) < len(reqs) || !b.saveAtomic(log, pending)
+		} else {
+			b.savePartial(log, pending, chunkSize)
+		}
+
+		resp := Response{Response: responseModel.OK(), Results: results}
+		for i := range results {
+			if failed && results[i].Error == "" {
+				//nothing is saved, the items that didn't fail are reported as not saved
+				results[i] = Result{Index: i, Code: http.StatusFailedDependency, Error: "not saved, another item of the batch failed"}
+			}
+			if results[i].Error != "" {
+				resp.Failed++
+				continue
+			}
+			resp.Created++
+			results[i].ShortURL = shortURLs.Build(b.links[i], slug)
+		}
+		log.Info("batch saved", slog.Int("created", resp.Created), slog.Int("failed", resp.Failed))
+
+		switch {
+		case failed:
+			responseModel.Status(r, http.StatusUnprocessableEntity)
+		case resp.Failed == 0:
+			responseModel.Status(r, http.StatusCreated)
+		}
+		render.JSON(w, r, resp)
+	}
+}
+
+// batch saves the items and fills their results
+type batch struct {
+	saver       LinkSaver
+	aliases     AliasStrategy
+	maxAttempts int
+	results     []Result
+	// saved links by item index
+	links map[int]domain.Link
+}
+
+// savePartial saves the items chunk by chunk, items with a taken generated alias are saved again in the next round.
+func (b *batch) savePartial(log *slog.Logger, pending []*item, chunkSize int) {
+	if chunkSize <= 0 {
+		chunkSize = len(pending)
+	}
+	for len(pending) > 0 {
+		var retry []*item
+		for start := 0; start < len(pending); start += chunkSize {
+			chunk := pending[start:min(start+chunkSize, len(pending))]
+
+			saved, err := b.save(chunk, false)
+			if err != nil {
+				log.Error("failed to add urls", sl.Err(err))
+				for _, it := range chunk {
+					b.results[it.index] = Result{Index: it.index, Code: http.StatusInternalServerError, Error: "failed to add urls"}
+				}
+				continue
+			}
+			for i, it := range chunk {
+				switch err := saved[i].Err; {
+				case err == nil:
+					b.created(it)
+				case b.retryable(it, err):
+					retry = append(retry, it)
+				default:
+					b.failed(it, err)
+				}
+			}
+		}
+		pending = retry
+	}
+}
+
+// saveAtomic saves all the items in one transaction, it is saved again while only a generated alias is taken.
+// It returns false if nothing is saved, the result of the item that failed is filled.
+func (b *batch) saveAtomic(log *slog.Logger, pending []*item) bool {
+	for {
+		saved, err := b.save(pending, true)
+		if err != nil {
+			log.Error("failed to add urls", sl.Err(err))
+			for _, it := range pending {
+				b.results[it.index] = Result{Index: it.index, Code: http.StatusInternalServerError, Error: "failed to add urls"}
+			}
+			return false
+		}
+
+		rejected := slices.IndexFunc(saved, func(res storage.SaveResult) bool { return res.Err != nil })
+		if rejected == -1 {
+			for _, it := range pending {
+				b.created(it)
+			}
+			return true
+		}
+		it, err := pending[rejected], saved[rejected].Err
+		if !b.retryable(it, err) {
+			b.failed(it, err)
+			return false
+		}
+	}
+}
+
+// save generates the missing aliases of the items and saves them in one transaction
+func (b *batch) save(items []*item, atomic bool) ([]storage.SaveResult, error) {
+	links := make([]domain.Link, len(items))
+	for i, it := range items {
+		if it.generated && it.link.Alias == "" {
+			it.attempt++
+			alias, err := b.aliases.Generate(it.attempt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate alias: %w", err)
+			}
+			it.link.Alias = alias
+		}
+		links[i] = it.link
+	}
+	return b.saver.SaveURLs(links, storage.SaveOptions{Atomic: atomic})
+}
+
+// retryable reports whether the generated alias of the item is taken and may be regenerated,
+// its alias is cleared for the next save
+func (b *batch) retryable(it *item, err error) bool {
+	if !it.generated || !errors.Is(err, storage.ErrURLExists) || it.attempt >= b.maxAttempts {
+		return false
+	}
+	it.link.Alias = ""
+	return true
+}
+
+func (b *batch) created(it *item) {
+	if b.links == nil {
+		b.links = map[int]domain.Link{}
+	}
+	b.links[it.index] = it.link
+	b.results[it.index] = Result{
+		Index:     it.index,
+		Code:      http.StatusCreated,
+		Alias:     it.link.Alias,
+		ExpiresAt: it.link.ExpiresAt,
+	}
+}
+
+// failed fills the result of a rejected item with the response POST /url gives
+func (b *batch) failed(it *item, err error) {
+	res := Result{Index: it.index}
+	switch {
+	case errors.Is(err, storage.ErrURLExists) && it.generated:
+		res.Code, res.Error = http.StatusInternalServerError, "failed to generate a unique alias"
+	case errors.Is(err, storage.ErrURLExists):
+		res.Code, res.Error = http.StatusConflict, "url already exists"
+	case errors.Is(err, storage.ErrDomainNotFound):
+		res.Code, res.Error = http.StatusBadRequest, "domain not found or not verified"
+	case errors.Is(err, storage.ErrQuotaExceeded):
+		res.Code, res.Error = http.StatusTooManyRequests, "monthly link quota exceeded"
+	default:
+		res.Code, res.Error = http.StatusInternalServerError, "failed to add url"
+	}
+	b.results[it.index] = res
+}
+
+// invalidMessage returns the error of an invalid item as save.New reports it
+func invalidMessage(err error) string {
+	var validErrs validator.ValidationErrors
+	if errors.As(err, &validErrs) {
+		return responseModel.ValidationError(validErrs).Error
+	}
+	return err.Error()
+}
+
+// decodeItems reads the items of a JSON array, or of an NDJSON body with one item per line
+func decodeItems(r *http.Request, maxItems int) ([]save.Request, error) {
+	dec := json.NewDecoder(r.Body)
+
+	ndjson := isNDJSON(r.Header.Get("Content-Type"))
+	if !ndjson {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return nil, errors.New("batch isn't a JSON array")
+		}
+	}
+
+	reqs := []save.Request{}
+	for dec.More() {
+		if len(reqs) == maxItems {
+			return nil, errTooManyItems
+		}
+		var req save.Request
+		if err := dec.Decode(&req); err != nil {
+			return nil, fmt.Errorf("item %d: %w", len(reqs), err)
+		}
+		reqs = append(reqs, req)
+	}
+	if !ndjson {
+		//closing bracket of the array
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return reqs, nil
+}
+
+func isNDJSON(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/x-ndjson" || mediaType == "application/ndjson"
+}
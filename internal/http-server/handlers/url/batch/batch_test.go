@@ -0,0 +1,307 @@
+package batch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/url/batch"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/lib/shorturl"
+	"short-url/internal/storage"
+	"strings"
+	"testing"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey authenticates the requests, the links are saved in the default workspace
+var testKey = domain.APIKey{ID: 1, WorkspaceID: domain.DefaultWorkspaceID, Scopes: []string{domain.ScopeLinksWrite}}
+
+var shortURLs = shorturl.Builder{DefaultURL: "https://sho.rt", Scheme: "https"}
+
+func serve(t *testing.T, handler http.HandlerFunc, query string, contentType string, body string) (int, batch.Response) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/url/batch"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var resp batch.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	return rr.Code, resp
+}
+
+// newAliases is a strategy reserving only the reserved custom aliases
+func newAliases(t *testing.T, reserved ...string) *batch.MockAliasStrategy {
+	m := batch.NewMockAliasStrategy(t)
+	for _, alias := range reserved {
+		m.On("Reserved", alias).Return(true).Maybe()
+	}
+	m.On("Reserved", mock.Anything).Return(false).Maybe()
+	return m
+}
+
+func codes(resp batch.Response) []int {
+	res := make([]int, len(resp.Results))
+	for i, r := range resp.Results {
+		res[i] = r.Code
+	}
+	return res
+}
+
+func aliases(links []domain.Link) []string {
+	res := make([]string, len(links))
+	for i, l := range links {
+		res[i] = l.Alias
+	}
+	return res
+}
+
+const body = `[
+	{"url":"https://a.example","alias":"a"},
+	{"url":"invalid url text","alias":"b"},
+	{"url":"https://c.example","alias":"taken"}
+]`
+
+func TestBatchHandler(t *testing.T) {
+	cases := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		// aliases of the links passed to SaveURLs, nil if it isn't called
+		saved []string
+		// custom aliases the strategy generates later
+		reserved  []string
+		results   []storage.SaveResult
+		mockError error
+		respCode  int
+		codes     []int
+		created   int
+	}{
+		{
+			name:     "Partial",
+			body:     body,
+			saved:    []string{"a", "taken"},
+			results:  []storage.SaveResult{{ID: 1}, {Err: storage.ErrURLExists}},
+			respCode: http.StatusOK,
+			codes:    []int{http.StatusCreated, http.StatusBadRequest, http.StatusConflict},
+			created:  1,
+		},
+		{
+			name:     "All created",
+			body:     `[{"url":"https://a.example","alias":"a"},{"url":"https://c.example","alias":"c","ttl":"1h"}]`,
+			saved:    []string{"a", "c"},
+			results:  []storage.SaveResult{{ID: 1}, {ID: 2}},
+			respCode: http.StatusCreated,
+			codes:    []int{http.StatusCreated, http.StatusCreated},
+			created:  2,
+		},
+		{
+			name:        "NDJSON",
+			contentType: "application/x-ndjson",
+			body:        "{\"url\":\"https://a.example\",\"alias\":\"a\"}\n{\"url\":\"https://c.example\",\"alias\":\"c\"}\n",
+			saved:       []string{"a", "c"},
+			results:     []storage.SaveResult{{ID: 1}, {ID: 2}},
+			respCode:    http.StatusCreated,
+			codes:       []int{http.StatusCreated, http.StatusCreated},
+			created:     2,
+		},
+		{
+			name:     "Atomic with an invalid item",
+			query:    "?mode=atomic",
+			body:     body,
+			respCode: http.StatusUnprocessableEntity,
+			codes:    []int{http.StatusFailedDependency, http.StatusBadRequest, http.StatusFailedDependency},
+		},
+		{
+			name:     "Atomic with a rejected item",
+			query:    "?mode=atomic",
+			body:     `[{"url":"https://a.example","alias":"a"},{"url":"https://c.example","alias":"taken"}]`,
+			saved:    []string{"a", "taken"},
+			results:  []storage.SaveResult{{}, {Err: storage.ErrURLExists}},
+			respCode: http.StatusUnprocessableEntity,
+			codes:    []int{http.StatusFailedDependency, http.StatusConflict},
+		},
+		{
+			name:     "Atomic",
+			query:    "?mode=atomic",
+			body:     `[{"url":"https://a.example","alias":"a"},{"url":"https://c.example","alias":"c"}]`,
+			saved:    []string{"a", "c"},
+			results:  []storage.SaveResult{{ID: 1}, {ID: 2}},
+			respCode: http.StatusCreated,
+			codes:    []int{http.StatusCreated, http.StatusCreated},
+			created:  2,
+		},
+		{
+			name:      "Storage error",
+			body:      `[{"url":"https://a.example","alias":"a"}]`,
+			saved:     []string{"a"},
+			mockError: errors.New("unexpected error"),
+			respCode:  http.StatusOK,
+			codes:     []int{http.StatusInternalServerError},
+		},
+		{
+			name:     "Generated alias code",
+			body:     `[{"url":"https://a.example","alias":"a"},{"url":"https://c.example","alias":"b7"}]`,
+			saved:    []string{"a"},
+			reserved: []string{"b7"},
+			results:  []storage.SaveResult{{ID: 1}},
+			respCode: http.StatusOK,
+			codes:    []int{http.StatusCreated, http.StatusBadRequest},
+			created:  1,
+		},
+		{
+			name:     "Dedupe",
+			body:     `[{"url":"https://a.example","dedupe":true}]`,
+			respCode: http.StatusOK,
+			codes:    []int{http.StatusBadRequest},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			saverMock := batch.NewMockLinkSaver(t)
+			if tc.saved != nil {
+				saverMock.On("SaveURLs", mock.MatchedBy(func(links []domain.Link) bool {
+					return strings.Join(aliases(links), ",") == strings.Join(tc.saved, ",")
+				}), storage.SaveOptions{Atomic: tc.query == "?mode=atomic"}).Return(tc.results, tc.mockError).Once()
+			}
+
+			handler := batch.New(silentlog.NewSilentLogger(), saverMock, shortURLs, newAliases(t, tc.reserved...), 10, 10, 3)
+			code, resp := serve(t, handler, tc.query, tc.contentType, tc.body)
+
+			require.Equal(t, tc.respCode, code)
+			require.Equal(t, tc.codes, codes(resp))
+			require.Equal(t, tc.created, resp.Created)
+			require.Equal(t, len(tc.codes)-tc.created, resp.Failed)
+			for i, res := range resp.Results {
+				require.Equal(t, i, res.Index)
+				if res.Code == http.StatusCreated {
+					require.Equal(t, "https://sho.rt/"+res.Alias, res.ShortURL)
+				} else {
+					require.NotEmpty(t, res.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchHandler_Chunks(t *testing.T) {
+	saverMock := batch.NewMockLinkSaver(t)
+	saverMock.On("SaveURLs", mock.MatchedBy(func(links []domain.Link) bool { return len(links) == 2 }), storage.SaveOptions{}).
+		Return([]storage.SaveResult{{ID: 1}, {ID: 2}}, nil).Once()
+	saverMock.On("SaveURLs", mock.MatchedBy(func(links []domain.Link) bool { return len(links) == 1 }), storage.SaveOptions{}).
+		Return([]storage.SaveResult{{ID: 3}}, nil).Once()
+
+	handler := batch.New(silentlog.NewSilentLogger(), saverMock, shortURLs, newAliases(t), 10, 2, 3)
+	code, resp := serve(t, handler, "", "application/json",
+		`[{"url":"https://a.example","alias":"a"},{"url":"https://b.example","alias":"b"},{"url":"https://c.example","alias":"c"}]`)
+
+	require.Equal(t, http.StatusCreated, code)
+	require.Equal(t, 3, resp.Created)
+}
+
+func TestBatchHandler_GeneratedAliases(t *testing.T) {
+	for _, mode := range []string{batch.ModePartial, batch.ModeAtomic} {
+		t.Run(mode, func(t *testing.T) {
+			atomic := mode == batch.ModeAtomic
+			aliasMock := newAliases(t)
+			aliasMock.On("Generate", 1).Return("gen1", nil).Once()
+			aliasMock.On("Generate", 2).Return("gen2", nil).Once()
+
+			saverMock := batch.NewMockLinkSaver(t)
+			//the collided alias is regenerated, the custom one is kept
+			first := []storage.SaveResult{{ID: 1}, {Err: storage.ErrURLExists}}
+			if atomic {
+				first[0].ID = 0
+			}
+			saverMock.On("SaveURLs", mock.MatchedBy(func(links []domain.Link) bool {
+				return strings.Join(aliases(links), ",") == "a,gen1"
+			}), storage.SaveOptions{Atomic: atomic}).Return(first, nil).Once()
+			if atomic {
+				saverMock.On("SaveURLs", mock.MatchedBy(func(links []domain.Link) bool {
+					return strings.Join(aliases(links), ",") == "a,gen2"
+				}), storage.SaveOptions{Atomic: true}).Return([]storage.SaveResult{{ID: 1}, {ID: 2}}, nil).Once()
+			} else {
+				saverMock.On("SaveURLs", mock.MatchedBy(func(links []domain.Link) bool {
+					return strings.Join(aliases(links), ",") == "gen2"
+				}), storage.SaveOptions{}).Return([]storage.SaveResult{{ID: 2}}, nil).Once()
+			}
+
+			handler := batch.New(silentlog.NewSilentLogger(), saverMock, shortURLs, aliasMock, 10, 10, 3)
+			code, resp := serve(t, handler, "?mode="+mode, "application/json",
+				`[{"url":"https://a.example","alias":"a"},{"url":"https://b.example"}]`)
+
+			require.Equal(t, http.StatusCreated, code)
+			require.Equal(t, "a", resp.Results[0].Alias)
+			require.Equal(t, "gen2", resp.Results[1].Alias)
+		})
+	}
+}
+
+func TestBatchHandler_BadRequests(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		body      string
+		respCode  int
+		respError string
+	}{
+		{
+			name:      "Too many items",
+			body:      `[{"url":"https://a.example"},{"url":"https://b.example"},{"url":"https://c.example"}]`,
+			respCode:  http.StatusRequestEntityTooLarge,
+			respError: "batch has more than 2 items",
+		},
+		{
+			name:      "Empty",
+			body:      `[]`,
+			respCode:  http.StatusBadRequest,
+			respError: "batch is empty",
+		},
+		{
+			name:      "Not an array",
+			body:      `{"url":"https://a.example"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "can't decode request body",
+		},
+		{
+			name:      "Unknown mode",
+			query:     "?mode=all",
+			body:      `[{"url":"https://a.example"}]`,
+			respCode:  http.StatusBadRequest,
+			respError: "mode must be partial or atomic",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			handler := batch.New(silentlog.NewSilentLogger(), batch.NewMockLinkSaver(t), shortURLs, newAliases(t), 2, 10, 3)
+
+			req := httptest.NewRequest(http.MethodPost, "/url/batch"+tc.query, strings.NewReader(tc.body))
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respCode, rr.Code)
+			var problem responseModel.Problem
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+			require.Equal(t, tc.respError, problem.Detail)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/linkio"
+	"short-url/internal/lib/sl"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+//go:generate mockery --name=LinkLister
+type LinkLister interface {
+	ListLinks(query domain.LinkQuery) ([]domain.Link, error)
+	CountClicks(linkIDs []int64) (map[int64]int64, error)
+}
+
+// New streams all links of the workspace as an attachment ordered by creation.
+// Query params: format=csv|ndjson (csv by default) and clicks=true to add the total clicks of every link.
+func New(log *slog.Logger, lister LinkLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.export.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = linkio.FormatCSV
+		}
+		clicks := r.URL.Query().Get("clicks") == "true"
+
+		out := &streamWriter{w: w}
+		lw, err := linkio.NewWriter(out, format, clicks)
+		if err != nil {
+			responseModel.RenderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", linkio.ContentType(format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="links.%s"`, format))
+
+		n, err := linkio.Export(lister, lw, workspaceID, clicks)
+		if err != nil {
+			log.Error("failed to export urls", sl.Err(err), slog.Int("exported", n))
+			//the status is sent with the first page, a broken export can only be cut short
+			if !out.written {
+				w.Header().Del("Content-Disposition")
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to export urls")
+			}
+			return
+		}
+		log.Info("urls exported", slog.Int("exported", n), slog.String("format", format))
+	}
+}
+
+// streamWriter sends every write to the client right away
+type streamWriter struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	s.written = true
+	n, err := s.w.Write(p)
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
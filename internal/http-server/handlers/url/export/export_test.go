@@ -0,0 +1,104 @@
+package export_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/url/export"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = domain.APIKey{ID: 1, WorkspaceID: domain.DefaultWorkspaceID, Scopes: []string{domain.ScopeLinksRead}}
+
+func TestExportHandler(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	links := []domain.Link{
+		{ID: 1, Alias: "a", URL: "https://a.example", CreatedAt: createdAt, UpdatedAt: createdAt},
+		{ID: 2, Alias: "b", URL: "https://b.example", Domain: "go.example.com", Disabled: true, CreatedAt: createdAt, UpdatedAt: createdAt},
+	}
+
+	cases := []struct {
+		name        string
+		query       string
+		clicks      bool
+		mockError   error
+		respCode    int
+		contentType string
+		body        string
+		respError   string
+	}{
+		{
+			name:        "CSV",
+			respCode:    http.StatusOK,
+			contentType: "text/csv",
+			body: "id,alias,url,domain,expires_at,disabled,created_at,updated_at\n" +
+				"1,a,https://a.example,,,false,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z\n" +
+				"2,b,https://b.example,go.example.com,,true,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z\n",
+		},
+		{
+			name:        "NDJSON with clicks",
+			query:       "?format=ndjson&clicks=true",
+			clicks:      true,
+			respCode:    http.StatusOK,
+			contentType: "application/x-ndjson",
+			body: `{"id":1,"alias":"a","url":"https://a.example","disabled":false,"created_at":"2024-01-02T03:04:05Z","updated_at":"2024-01-02T03:04:05Z","clicks":7}` + "\n" +
+				`{"id":2,"alias":"b","url":"https://b.example","domain":"go.example.com","disabled":true,"created_at":"2024-01-02T03:04:05Z","updated_at":"2024-01-02T03:04:05Z","clicks":0}` + "\n",
+		},
+		{
+			name:      "Unknown format",
+			query:     "?format=xml",
+			respCode:  http.StatusBadRequest,
+			respError: "format must be csv or ndjson",
+		},
+		{
+			name:      "Storage error",
+			mockError: errors.New("unexpected error"),
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to export urls",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			listerMock := export.NewMockLinkLister(t)
+			if tc.respCode != http.StatusBadRequest {
+				listerMock.On("ListLinks", mock.MatchedBy(func(q domain.LinkQuery) bool {
+					return q.WorkspaceID == domain.DefaultWorkspaceID && q.SortBy == domain.LinkSortCreatedAt
+				})).Return(links, tc.mockError).Once()
+			}
+			if tc.clicks {
+				listerMock.On("CountClicks", []int64{1, 2}).Return(map[int64]int64{1: 7}, nil).Once()
+			}
+
+			handler := export.New(silentlog.NewSilentLogger(), listerMock)
+
+			req := httptest.NewRequest(http.MethodGet, "/url/export"+tc.query, nil)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respCode, rr.Code)
+			if tc.respError != "" {
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+			require.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+			require.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+			require.Equal(t, tc.body, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package get
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	responseModel.Response
+	domain.Link
+}
+
+//go:generate mockery --name=LinkGetter
+type LinkGetter interface {
+	GetLink(ref domain.LinkRef) (domain.Link, error)
+}
+
+// New returns the link metadata, expired and disabled links included.
+func New(log *slog.Logger, linkGetter LinkGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.get.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias url param is empty")
+			responseModel.RenderError(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		//links of custom domains are selected by the domain query param
+		ref := domain.LinkRef{
+			WorkspaceID: workspaceID,
+			Domain:      strings.ToLower(r.URL.Query().Get("domain")),
+			Alias:       alias,
+		}
+
+		link, err := linkGetter.GetLink(ref)
+		if err != nil {
+			if errors.Is(err, storage.ErrURLNotFound) {
+				log.Info("url not found", slog.String("alias", alias))
+				responseModel.RenderError(w, r, http.StatusNotFound, "url not found")
+			} else {
+				log.Error("failed to get url", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to get url")
+			}
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response: responseModel.OK(),
+			Link:     link,
+		})
+	}
+}
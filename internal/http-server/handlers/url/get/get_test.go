@@ -0,0 +1,121 @@
+package get_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/url/get"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey authenticates the requests, links are looked up in its workspace
+var testKey = domain.APIKey{ID: 1, WorkspaceID: 3, Scopes: []string{domain.ScopeAdmin}}
+
+func TestGetHandler(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		link      domain.Link
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name: "Success",
+			link: domain.Link{ID: 1, Alias: "abc", URL: "https://example.com", CreatedAt: createdAt, UpdatedAt: createdAt},
+		},
+		{
+			name:      "Not found",
+			respCode:  http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrURLNotFound,
+		},
+		{
+			name:      "Storage error",
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to get url",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			linkGetterMock := get.NewMockLinkGetter(t)
+			linkGetterMock.On("GetLink", domain.LinkRef{WorkspaceID: testKey.WorkspaceID, Alias: "abc"}).Return(tc.link, tc.mockError).Once()
+
+			//here using chi becouse there is URL param {alias}
+			r := chi.NewRouter()
+			r.Get("/url/{alias}", get.New(silentlog.NewSilentLogger(), linkGetterMock))
+
+			req, err := http.NewRequest(http.MethodGet, "/url/abc", nil)
+			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp get.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.link, resp.Link)
+		})
+	}
+}
+
+func TestGetHandler_Unauthenticated(t *testing.T) {
+	linkGetterMock := get.NewMockLinkGetter(t)
+
+	r := chi.NewRouter()
+	r.Get("/url/{alias}", get.New(silentlog.NewSilentLogger(), linkGetterMock))
+
+	req, err := http.NewRequest(http.MethodGet, "/url/abc", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	//handlers never fall back to another workspace
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestGetHandler_Domain(t *testing.T) {
+	linkGetterMock := get.NewMockLinkGetter(t)
+	ref := domain.LinkRef{WorkspaceID: testKey.WorkspaceID, Domain: "go.example", Alias: "abc"}
+	linkGetterMock.On("GetLink", ref).Return(domain.Link{ID: 1, Domain: "go.example", Alias: "abc"}, nil).Once()
+
+	r := chi.NewRouter()
+	r.Get("/url/{alias}", get.New(silentlog.NewSilentLogger(), linkGetterMock))
+
+	req, err := http.NewRequest(http.MethodGet, "/url/abc?domain=Go.Example", nil)
+	require.NoError(t, err)
+	req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var resp get.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "go.example", resp.Domain)
+}
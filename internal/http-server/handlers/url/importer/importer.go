@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/linkio"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	responseModel.Response
+	linkio.Summary
+}
+
+//go:generate mockery --name=LinkImporter
+type LinkImporter interface {
+	SaveURLs(links []domain.Link, opts storage.SaveOptions) ([]storage.SaveResult, error)
+	UpdateLink(ref domain.LinkRef, update domain.LinkUpdate) (domain.Link, error)
+}
+
+// New imports up to maxRows links of a CSV or NDJSON body in the format of GET /url/export and reports the result.
+// Rows are validated like POST /url, invalid and rejected rows are listed in the summary and not saved.
+// Query params: format=csv|ndjson (by default from the Content-Type, else csv), on_conflict=skip|overwrite|fail
+// for the rows whose alias is taken (skip by default) and dry_run=true to only report what would be imported.
+// With on_conflict=fail nothing is saved if a row fails and the request gets 422.
+func New(log *slog.Logger, importer LinkImporter, chunkSize, maxRows int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.importer.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		onConflict := r.URL.Query().Get("on_conflict")
+		switch onConflict {
+		case "":
+			onConflict = linkio.ConflictSkip
+		case linkio.ConflictSkip, linkio.ConflictOverwrite, linkio.ConflictFail:
+		default:
+			responseModel.RenderError(w, r, http.StatusBadRequest, "on_conflict must be skip, overwrite or fail")
+			return
+		}
+
+		reader, err := linkio.NewReader(r.Body, format(r))
+		if err != nil {
+			log.Info("can't read request body", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		//the root key has no id and isn't recorded
+		var apiKeyID *int64
+		if key, _ := mwAuth.APIKeyFromContext(r.Context()); key.ID != 0 {
+			apiKeyID = &key.ID
+		}
+
+		sum, err := linkio.Import(importer, reader, linkio.ImportOptions{
+			WorkspaceID: workspaceID,
+			APIKeyID:    apiKeyID,
+			OnConflict:  onConflict,
+			DryRun:      r.URL.Query().Get("dry_run") == "true",
+			ChunkSize:   chunkSize,
+			MaxRows:     maxRows,
+		})
+		switch {
+		case errors.Is(err, linkio.ErrTooManyRows):
+			log.Info("import is too large", slog.Int("max_rows", maxRows))
+			responseModel.RenderError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("import has more than %d rows", maxRows))
+			return
+		case errors.Is(err, linkio.ErrMalformed):
+			log.Info("can't read request body", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		case err != nil:
+			//chunks saved before the error stay saved
+			log.Error("failed to import urls", sl.Err(err), slog.Int("created", sum.Created), slog.Int("updated", sum.Updated))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to import urls")
+			return
+		}
+		log.Info("urls imported",
+			slog.Int("rows", sum.Rows),
+			slog.Int("created", sum.Created),
+			slog.Int("updated", sum.Updated),
+			slog.Int("failed", sum.Failed),
+			slog.Bool("dry_run", sum.DryRun),
+		)
+
+		if sum.Aborted {
+			responseModel.Status(r, http.StatusUnprocessableEntity)
+		}
+		render.JSON(w, r, Response{Response: responseModel.OK(), Summary: sum})
+	}
+}
+
+// format returns the format query param, NDJSON bodies may be sent with their Content-Type instead
+func format(r *http.Request) string {
+	if f := r.URL.Query().Get("format"); f != "" {
+		return f
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-ndjson" || mediaType == "application/ndjson" {
+		return linkio.FormatNDJSON
+	}
+	return linkio.FormatCSV
+}
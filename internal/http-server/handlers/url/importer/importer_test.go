@@ -0,0 +1,170 @@
+package importer_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/url/importer"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/linkio"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = domain.APIKey{ID: 1, WorkspaceID: domain.DefaultWorkspaceID, Scopes: []string{domain.ScopeLinksWrite}}
+
+const body = "alias,url\na,https://a.example\nb,invalid url text\ntaken,https://c.example\n"
+
+func aliases(links []domain.Link) string {
+	res := make([]string, len(links))
+	for i, l := range links {
+		res[i] = l.Alias
+	}
+	return strings.Join(res, ",")
+}
+
+func TestImportHandler(t *testing.T) {
+	cases := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		// aliases of the links passed to SaveURLs, empty if it isn't called
+		saved      string
+		opts       storage.SaveOptions
+		results    []storage.SaveResult
+		mockError  error
+		updated    bool
+		respCode   int
+		respError  string
+		wantResult linkio.Summary
+	}{
+		{
+			name:       "Skip",
+			body:       body,
+			saved:      "a,taken",
+			results:    []storage.SaveResult{{ID: 1}, {Err: storage.ErrURLExists}},
+			respCode:   http.StatusOK,
+			wantResult: linkio.Summary{Rows: 3, Created: 1, Skipped: 1, Failed: 1},
+		},
+		{
+			name:       "Overwrite",
+			query:      "?on_conflict=overwrite",
+			body:       body,
+			saved:      "a,taken",
+			results:    []storage.SaveResult{{ID: 1}, {Err: storage.ErrURLExists}},
+			updated:    true,
+			respCode:   http.StatusOK,
+			wantResult: linkio.Summary{Rows: 3, Created: 1, Updated: 1, Failed: 1},
+		},
+		{
+			name:       "Dry run",
+			query:      "?on_conflict=overwrite&dry_run=true",
+			body:       body,
+			saved:      "a,taken",
+			opts:       storage.SaveOptions{DryRun: true},
+			results:    []storage.SaveResult{{ID: 1}, {Err: storage.ErrURLExists}},
+			respCode:   http.StatusOK,
+			wantResult: linkio.Summary{Rows: 3, Created: 1, Updated: 1, Failed: 1, DryRun: true},
+		},
+		{
+			name:       "Fail",
+			query:      "?on_conflict=fail",
+			body:       body,
+			respCode:   http.StatusUnprocessableEntity,
+			wantResult: linkio.Summary{Rows: 3, Failed: 1, Aborted: true},
+		},
+		{
+			name:        "NDJSON",
+			contentType: "application/x-ndjson",
+			body:        `{"alias":"a","url":"https://a.example"}` + "\n",
+			saved:       "a",
+			results:     []storage.SaveResult{{ID: 1}},
+			respCode:    http.StatusOK,
+			wantResult:  linkio.Summary{Rows: 1, Created: 1},
+		},
+		{
+			name:      "Storage error",
+			body:      body,
+			saved:     "a,taken",
+			mockError: errors.New("unexpected error"),
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to import urls",
+		},
+		{
+			name:      "Too many rows",
+			body:      body + "d,https://d.example\n",
+			respCode:  http.StatusRequestEntityTooLarge,
+			respError: "import has more than 3 rows",
+		},
+		{
+			name:      "No url column",
+			body:      "alias\na\n",
+			respCode:  http.StatusBadRequest,
+			respError: "malformed file: csv header has no url column",
+		},
+		{
+			name:      "Unknown format",
+			query:     "?format=xml",
+			body:      body,
+			respCode:  http.StatusBadRequest,
+			respError: "format must be csv or ndjson",
+		},
+		{
+			name:      "Unknown conflict policy",
+			query:     "?on_conflict=replace",
+			body:      body,
+			respCode:  http.StatusBadRequest,
+			respError: "on_conflict must be skip, overwrite or fail",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			importerMock := importer.NewMockLinkImporter(t)
+			if tc.saved != "" {
+				importerMock.On("SaveURLs", mock.MatchedBy(func(links []domain.Link) bool {
+					return aliases(links) == tc.saved && *links[0].APIKeyID == testKey.ID
+				}), tc.opts).Return(tc.results, tc.mockError).Once()
+			}
+			if tc.updated {
+				importerMock.On("UpdateLink", domain.LinkRef{WorkspaceID: domain.DefaultWorkspaceID, Alias: "taken"}, mock.MatchedBy(func(u domain.LinkUpdate) bool {
+					return *u.URL == "https://c.example" && u.NeverExpires
+				})).Return(domain.Link{}, nil).Once()
+			}
+
+			handler := importer.New(silentlog.NewSilentLogger(), importerMock, 10, 3)
+
+			req := httptest.NewRequest(http.MethodPost, "/url/import"+tc.query, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respCode, rr.Code)
+			if tc.respError != "" {
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp importer.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Len(t, resp.Errors, tc.wantResult.Failed)
+			resp.Errors = nil
+			require.Equal(t, tc.wantResult, resp.Summary)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package list
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type Response struct {
+	responseModel.Response
+	Links []domain.Link `json:"links"`
+	// empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+//go:generate mockery --name=LinkLister
+type LinkLister interface {
+	ListLinks(query domain.LinkQuery) ([]domain.Link, error)
+}
+
+// New lists links page by page. Query params: prefix (alias prefix), created_from and created_to (RFC 3339),
+// sort=created_at|alias with a '-' prefix for descending order (-created_at by default), limit and cursor
+// (next_cursor of the previous page).
+func New(log *slog.Logger, lister LinkLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.list.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		query, err := parseQuery(r.URL.Query())
+		if err != nil {
+			log.Info("invalid query", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		query.WorkspaceID = workspaceID
+		limit := query.Limit
+		//one more link tells whether there is a next page
+		query.Limit++
+
+		links, err := lister.ListLinks(query)
+		if err != nil {
+			log.Error("failed to list urls", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to list urls")
+			return
+		}
+
+		resp := Response{
+			Response: responseModel.OK(),
+			Links:    links,
+		}
+		if len(links) > limit {
+			resp.Links = links[:limit]
+			resp.NextCursor = encodeCursor(links[limit-1])
+		}
+
+		render.JSON(w, r, resp)
+	}
+}
+
+func parseQuery(q url.Values) (domain.LinkQuery, error) {
+	query := domain.LinkQuery{
+		Prefix: q.Get("prefix"),
+		SortBy: domain.LinkSortCreatedAt,
+		Desc:   true,
+		Limit:  defaultLimit,
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_from", &query.CreatedFrom},
+		{"created_to", &query.CreatedTo},
+	} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return domain.LinkQuery{}, errors.New("field " + p.name + " is not in RFC 3339 format")
+		}
+		*p.dst = &t
+	}
+
+	if raw := q.Get("sort"); raw != "" {
+		query.Desc = strings.HasPrefix(raw, "-")
+		query.SortBy = strings.TrimPrefix(raw, "-")
+		if query.SortBy != domain.LinkSortCreatedAt && query.SortBy != domain.LinkSortAlias {
+			return domain.LinkQuery{}, errors.New("field sort must be created_at or alias")
+		}
+	}
+
+	if raw := q.Get("limit"); raw != "" {
+		l, err := strconv.Atoi(raw)
+		if err != nil || l <= 0 || l > maxLimit {
+			return domain.LinkQuery{}, errors.New("invalid limit")
+		}
+		query.Limit = l
+	}
+
+	if raw := q.Get("cursor"); raw != "" {
+		cursor, err := decodeCursor(raw)
+		if err != nil {
+			return domain.LinkQuery{}, errors.New("invalid cursor")
+		}
+		query.After = &cursor
+	}
+
+	return query, nil
+}
+
+// the cursor is opaque for clients, it holds the sort keys of the last link of the page
+func encodeCursor(link domain.Link) string {
+	data, _ := json.Marshal(domain.LinkCursor{
+		ID:        link.ID,
+		Alias:     link.Alias,
+		CreatedAt: link.CreatedAt,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (domain.LinkCursor, error) {
+	var cursor domain.LinkCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
@@ -0,0 +1,168 @@
+package list_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/url/list"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey authenticates the requests, links are looked up in its workspace
+var testKey = domain.APIKey{ID: 1, WorkspaceID: 3, Scopes: []string{domain.ScopeAdmin}}
+
+func TestListHandler(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	links := []domain.Link{
+		{ID: 3, Alias: "c", CreatedAt: createdAt.Add(2 * time.Hour)},
+		{ID: 2, Alias: "b", CreatedAt: createdAt.Add(time.Hour)},
+		{ID: 1, Alias: "a", CreatedAt: createdAt},
+	}
+
+	cases := []struct {
+		name      string
+		query     string
+		check     func(q domain.LinkQuery) bool
+		links     []domain.Link
+		respLen   int
+		hasNext   bool
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:    "Defaults",
+			check:   func(q domain.LinkQuery) bool { return q.SortBy == domain.LinkSortCreatedAt && q.Desc && q.Limit == 21 },
+			links:   links,
+			respLen: 3,
+		},
+		{
+			name:  "Next page",
+			query: "?limit=2&sort=alias&prefix=a",
+			check: func(q domain.LinkQuery) bool {
+				return q.SortBy == domain.LinkSortAlias && !q.Desc && q.Limit == 3 && q.Prefix == "a"
+			},
+			links:   links,
+			respLen: 2,
+			hasNext: true,
+		},
+		{
+			name:  "Created range",
+			query: "?created_from=2025-01-01T00:00:00Z&created_to=2025-01-02T00:00:00Z",
+			check: func(q domain.LinkQuery) bool {
+				return q.CreatedFrom != nil && q.CreatedFrom.Equal(createdAt) &&
+					q.CreatedTo != nil && q.CreatedTo.Equal(createdAt.Add(24*time.Hour))
+			},
+			links:   links[:1],
+			respLen: 1,
+		},
+		{
+			name:      "Invalid sort",
+			query:     "?sort=url",
+			respCode:  http.StatusBadRequest,
+			respError: "field sort must be created_at or alias",
+		},
+		{
+			name:      "Invalid limit",
+			query:     "?limit=1000",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid limit",
+		},
+		{
+			name:      "Invalid cursor",
+			query:     "?cursor=not-a-cursor",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid cursor",
+		},
+		{
+			name:      "Storage error",
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to list urls",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			linkListerMock := list.NewMockLinkLister(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				check := tc.check
+				if check == nil {
+					check = func(domain.LinkQuery) bool { return true }
+				}
+				linkListerMock.On("ListLinks", mock.MatchedBy(func(q domain.LinkQuery) bool {
+					return q.WorkspaceID == testKey.WorkspaceID && check(q)
+				})).Return(tc.links, tc.mockError).Once()
+			}
+
+			handler := list.New(silentlog.NewSilentLogger(), linkListerMock)
+
+			req, err := http.NewRequest(http.MethodGet, "/url"+tc.query, nil)
+			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp list.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Len(t, resp.Links, tc.respLen)
+			require.Equal(t, tc.hasNext, resp.NextCursor != "")
+		})
+	}
+}
+
+func TestListHandler_Cursor(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	linkListerMock := list.NewMockLinkLister(t)
+	handler := list.New(silentlog.NewSilentLogger(), linkListerMock)
+
+	linkListerMock.On("ListLinks", mock.MatchedBy(func(q domain.LinkQuery) bool { return q.After == nil })).
+		Return([]domain.Link{{ID: 2, Alias: "b", CreatedAt: createdAt}, {ID: 1, Alias: "a"}}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/url?limit=1", nil)
+	req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var resp list.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.NextCursor)
+
+	//the cursor of the next page points after the last returned link
+	linkListerMock.On("ListLinks", mock.MatchedBy(func(q domain.LinkQuery) bool {
+		return q.After != nil && q.After.ID == 2 && q.After.Alias == "b" && q.After.CreatedAt.Equal(createdAt)
+	})).Return([]domain.Link{{ID: 1, Alias: "a"}}, nil).Once()
+
+	req = httptest.NewRequest(http.MethodGet, "/url?limit=1&cursor="+resp.NextCursor, nil)
+	req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	resp = list.Response{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Links, 1)
+	require.Empty(t, resp.NextCursor)
+}
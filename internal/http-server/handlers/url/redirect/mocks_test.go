@@ -5,6 +5,8 @@
 package redirect
 
 import (
+	"short-url/internal/http-server/model/domain"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -36,25 +38,25 @@ func (_m *MockURLGetter) EXPECT() *MockURLGetter_Expecter {
 }
 
 // GetURL provides a mock function for the type MockURLGetter
-func (_mock *MockURLGetter) GetURL(alias string) (string, error) {
-	ret := _mock.Called(alias)
+func (_mock *MockURLGetter) GetURL(ref domain.LinkRef) (domain.Link, error) {
+	ret := _mock.Called(ref)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetURL")
 	}
 
-	var r0 string
+	var r0 domain.Link
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string) (string, error)); ok {
-		return returnFunc(alias)
+	if returnFunc, ok := ret.Get(0).(func(domain.LinkRef) (domain.Link, error)); ok {
+		return returnFunc(ref)
 	}
-	if returnFunc, ok := ret.Get(0).(func(string) string); ok {
-		r0 = returnFunc(alias)
+	if returnFunc, ok := ret.Get(0).(func(domain.LinkRef) domain.Link); ok {
+		r0 = returnFunc(ref)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(domain.Link)
 	}
-	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
-		r1 = returnFunc(alias)
+	if returnFunc, ok := ret.Get(1).(func(domain.LinkRef) error); ok {
+		r1 = returnFunc(ref)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -67,12 +69,132 @@ type MockURLGetter_GetURL_Call struct {
 }
 
 // GetURL is a helper method to define mock.On call
-//   - alias string
-func (_e *MockURLGetter_Expecter) GetURL(alias interface{}) *MockURLGetter_GetURL_Call {
-	return &MockURLGetter_GetURL_Call{Call: _e.mock.On("GetURL", alias)}
+//   - ref domain.LinkRef
+func (_e *MockURLGetter_Expecter) GetURL(ref interface{}) *MockURLGetter_GetURL_Call {
+	return &MockURLGetter_GetURL_Call{Call: _e.mock.On("GetURL", ref)}
+}
+
+func (_c *MockURLGetter_GetURL_Call) Run(run func(ref domain.LinkRef)) *MockURLGetter_GetURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 domain.LinkRef
+		if args[0] != nil {
+			arg0 = args[0].(domain.LinkRef)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockURLGetter_GetURL_Call) Return(link domain.Link, err error) *MockURLGetter_GetURL_Call {
+	_c.Call.Return(link, err)
+	return _c
+}
+
+func (_c *MockURLGetter_GetURL_Call) RunAndReturn(run func(ref domain.LinkRef) (domain.Link, error)) *MockURLGetter_GetURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWorkspaceBySlug provides a mock function for the type MockURLGetter
+func (_mock *MockURLGetter) GetWorkspaceBySlug(slug string) (domain.Workspace, error) {
+	ret := _mock.Called(slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspaceBySlug")
+	}
+
+	var r0 domain.Workspace
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) (domain.Workspace, error)); ok {
+		return returnFunc(slug)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) domain.Workspace); ok {
+		r0 = returnFunc(slug)
+	} else {
+		r0 = ret.Get(0).(domain.Workspace)
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockURLGetter_GetWorkspaceBySlug_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWorkspaceBySlug'
+type MockURLGetter_GetWorkspaceBySlug_Call struct {
+	*mock.Call
+}
+
+// GetWorkspaceBySlug is a helper method to define mock.On call
+//   - slug string
+func (_e *MockURLGetter_Expecter) GetWorkspaceBySlug(slug interface{}) *MockURLGetter_GetWorkspaceBySlug_Call {
+	return &MockURLGetter_GetWorkspaceBySlug_Call{Call: _e.mock.On("GetWorkspaceBySlug", slug)}
+}
+
+func (_c *MockURLGetter_GetWorkspaceBySlug_Call) Run(run func(slug string)) *MockURLGetter_GetWorkspaceBySlug_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockURLGetter_GetWorkspaceBySlug_Call) Return(workspace domain.Workspace, err error) *MockURLGetter_GetWorkspaceBySlug_Call {
+	_c.Call.Return(workspace, err)
+	return _c
+}
+
+func (_c *MockURLGetter_GetWorkspaceBySlug_Call) RunAndReturn(run func(slug string) (domain.Workspace, error)) *MockURLGetter_GetWorkspaceBySlug_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDomain provides a mock function for the type MockURLGetter
+func (_mock *MockURLGetter) GetDomain(hostname string) (domain.Domain, error) {
+	ret := _mock.Called(hostname)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDomain")
+	}
+
+	var r0 domain.Domain
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) (domain.Domain, error)); ok {
+		return returnFunc(hostname)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) domain.Domain); ok {
+		r0 = returnFunc(hostname)
+	} else {
+		r0 = ret.Get(0).(domain.Domain)
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(hostname)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockURLGetter_GetDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDomain'
+type MockURLGetter_GetDomain_Call struct {
+	*mock.Call
+}
+
+// GetDomain is a helper method to define mock.On call
+//   - hostname string
+func (_e *MockURLGetter_Expecter) GetDomain(hostname interface{}) *MockURLGetter_GetDomain_Call {
+	return &MockURLGetter_GetDomain_Call{Call: _e.mock.On("GetDomain", hostname)}
 }
 
-func (_c *MockURLGetter_GetURL_Call) Run(run func(alias string)) *MockURLGetter_GetURL_Call {
+func (_c *MockURLGetter_GetDomain_Call) Run(run func(hostname string)) *MockURLGetter_GetDomain_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 string
 		if args[0] != nil {
@@ -85,12 +207,79 @@ func (_c *MockURLGetter_GetURL_Call) Run(run func(alias string)) *MockURLGetter_
 	return _c
 }
 
-func (_c *MockURLGetter_GetURL_Call) Return(s string, err error) *MockURLGetter_GetURL_Call {
-	_c.Call.Return(s, err)
+func (_c *MockURLGetter_GetDomain_Call) Return(domain domain.Domain, err error) *MockURLGetter_GetDomain_Call {
+	_c.Call.Return(domain, err)
+	return _c
+}
+
+func (_c *MockURLGetter_GetDomain_Call) RunAndReturn(run func(hostname string) (domain.Domain, error)) *MockURLGetter_GetDomain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockClickTracker creates a new instance of MockClickTracker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockClickTracker(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockClickTracker {
+	mock := &MockClickTracker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockClickTracker is an autogenerated mock type for the ClickTracker type
+type MockClickTracker struct {
+	mock.Mock
+}
+
+type MockClickTracker_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockClickTracker) EXPECT() *MockClickTracker_Expecter {
+	return &MockClickTracker_Expecter{mock: &_m.Mock}
+}
+
+// Track provides a mock function for the type MockClickTracker
+func (_mock *MockClickTracker) Track(click domain.Click) {
+	_mock.Called(click)
+	return
+}
+
+// MockClickTracker_Track_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Track'
+type MockClickTracker_Track_Call struct {
+	*mock.Call
+}
+
+// Track is a helper method to define mock.On call
+//   - click domain.Click
+func (_e *MockClickTracker_Expecter) Track(click interface{}) *MockClickTracker_Track_Call {
+	return &MockClickTracker_Track_Call{Call: _e.mock.On("Track", click)}
+}
+
+func (_c *MockClickTracker_Track_Call) Run(run func(click domain.Click)) *MockClickTracker_Track_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 domain.Click
+		if args[0] != nil {
+			arg0 = args[0].(domain.Click)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClickTracker_Track_Call) Return() *MockClickTracker_Track_Call {
+	_c.Call.Return()
 	return _c
 }
 
-func (_c *MockURLGetter_GetURL_Call) RunAndReturn(run func(alias string) (string, error)) *MockURLGetter_GetURL_Call {
+func (_c *MockClickTracker_Track_Call) RunAndReturn(run func(click domain.Click)) *MockClickTracker_Track_Call {
 	_c.Call.Return(run)
 	return _c
 }
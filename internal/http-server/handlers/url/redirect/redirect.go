@@ -3,26 +3,40 @@ package redirect
 import (
 	"errors"
 	"log/slog"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
+	"short-url/internal/http-server/model/domain"
 	responseModel "short-url/internal/http-server/model/response"
 	"short-url/internal/lib/sl"
 	"short-url/internal/storage"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/render"
 )
 
+//go:generate mockery --name=URLGetter
 type URLGetter interface {
-	GetURL(alias string) (string, error)
+	GetURL(ref domain.LinkRef) (domain.Link, error)
+	GetWorkspaceBySlug(slug string) (domain.Workspace, error)
+	GetDomain(hostname string) (domain.Domain, error)
 }
 
-func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
+//go:generate mockery --name=ClickTracker
+type ClickTracker interface {
+	Track(click domain.Click)
+}
+
+// New resolves the alias on the verified custom domain of the request host. Other hosts fall back to the default
+// domain where the alias is looked up in the workspace of the {workspace} url param slug or the default workspace.
+// clickTracker is optional and may be nil.
+func New(log *slog.Logger, urlGetter URLGetter, clickTracker ClickTracker) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.url.redirect.new"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -30,24 +44,103 @@ func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
 		alias := chi.URLParam(r, "alias")
 		if alias == "" {
 			log.Info("alias url param is empty")
-			render.JSON(w, r, responseModel.Error("invalid request"))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		ref, err := resolveLink(r, urlGetter, alias)
+		if err != nil {
+			if errors.Is(err, storage.ErrWorkspaceNotFound) {
+				log.Info("workspace not found", slog.String("workspace", chi.URLParam(r, "workspace")))
+				responseModel.RenderError(w, r, http.StatusNotFound, "url not found")
+			} else {
+				log.Error("failed to resolve alias", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to get url")
+			}
 			return
 		}
 
-		url, err := urlGetter.GetURL(alias)
+		link, err := urlGetter.GetURL(ref)
 		if err != nil {
 			if errors.Is(err, storage.ErrURLNotFound) {
 				log.Info("url not found", slog.String("alias", alias))
-				render.JSON(w, r, responseModel.Error("url not found"))
+				responseModel.RenderError(w, r, http.StatusNotFound, "url not found")
+			} else if errors.Is(err, storage.ErrURLExpired) {
+				log.Info("url expired", slog.String("alias", alias))
+				responseModel.RenderError(w, r, http.StatusGone, "url expired")
 			} else {
 				log.Error("failed to get url", sl.Err(err))
-				render.JSON(w, r, responseModel.Error("failed to get url"))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to get url")
 			}
 			return
 		}
 
-		log.Info("url found", slog.String("url", url))
-		http.Redirect(w, r, url, http.StatusFound)
+		log.Info("url found", slog.String("url", link.URL))
+		if clickTracker != nil {
+			clickTracker.Track(domain.Click{
+				LinkID:      link.ID,
+				WorkspaceID: link.WorkspaceID,
+				Domain:      link.Domain,
+				Alias:       alias,
+				ClickedAt:   time.Now(),
+				Referrer:    r.Referer(),
+				UserAgent:   r.UserAgent(),
+				Country:     countryFromHeader(r.Header),
+				IP:          clientIP(r),
+			})
+		}
+		http.Redirect(w, r, link.URL, http.StatusFound)
 	}
 
 }
+
+// resolveLink returns the reference of the alias on the domain of the request
+func resolveLink(r *http.Request, urlGetter URLGetter, alias string) (domain.LinkRef, error) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	d, err := urlGetter.GetDomain(strings.ToLower(host))
+	if err != nil && !errors.Is(err, storage.ErrDomainNotFound) {
+		return domain.LinkRef{}, err
+	}
+	//unverified domains aren't served until their owner is proven
+	if err == nil && d.Verified() {
+		return domain.LinkRef{WorkspaceID: d.WorkspaceID, Domain: d.Hostname, Alias: alias}, nil
+	}
+
+	ref := domain.LinkRef{WorkspaceID: domain.DefaultWorkspaceID, Alias: alias}
+	if slug := chi.URLParam(r, "workspace"); slug != "" {
+		ws, err := urlGetter.GetWorkspaceBySlug(slug)
+		if err != nil {
+			return domain.LinkRef{}, err
+		}
+		ref.WorkspaceID = ws.ID
+	}
+	return ref, nil
+}
+
+// country headers set by common CDNs and proxies
+var countryHeaders = []string{
+	"CF-IPCountry",
+	"CloudFront-Viewer-Country",
+	"X-AppEngine-Country",
+	"X-Country-Code",
+}
+
+func countryFromHeader(h http.Header) string {
+	for _, name := range countryHeaders {
+		if country := h.Get(name); country != "" {
+			return strings.ToUpper(country)
+		}
+	}
+	return ""
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -3,43 +3,78 @@ package redirect_test
 import (
 	"encoding/json"
 	"errors"
+	"net/http"
 	"net/http/httptest"
 	"short-url/internal/http-server/handlers/url/redirect"
-	"short-url/internal/http-server/handlers/url/save"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
 	"short-url/internal/lib/api"
 	"short-url/internal/lib/logger/handlers/silentlog"
 	"short-url/internal/storage"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
 func TestRedirectHandler(t *testing.T) {
 	cases := []struct {
-		name      string
-		alias     string
-		url       string
-		respError string
-		mockError error
+		name  string
+		alias string
+		// slug of the /w/{workspace}/{alias} route, empty for /{alias}
+		workspace   string
+		url         string
+		workspaceID int64
+		respCode    int
+		respError   string
+		mockError   error
 	}{
 		{
-			name:  "Success",
-			alias: "123",
-			url:   "http:google.com",
+			name:        "Success",
+			alias:       "123",
+			url:         "http:google.com",
+			workspaceID: domain.DefaultWorkspaceID,
 		},
 		{
-			name:      "some db error",
-			alias:     "123",
-			mockError: errors.New("some error"),
-			respError: "failed to get url",
+			name:        "Workspace path",
+			alias:       "123",
+			workspace:   "teamb",
+			url:         "http:google.com",
+			workspaceID: 2,
 		},
 		{
-			name:      "no url",
+			name:      "Unknown workspace",
 			alias:     "123",
-			mockError: storage.ErrURLNotFound,
+			workspace: "missing",
+			respCode:  http.StatusNotFound,
 			respError: "url not found",
 		},
+		{
+			name:        "some db error",
+			alias:       "123",
+			workspaceID: domain.DefaultWorkspaceID,
+			mockError:   errors.New("some error"),
+			respCode:    http.StatusInternalServerError,
+			respError:   "failed to get url",
+		},
+		{
+			name:        "expired url",
+			alias:       "123",
+			workspaceID: domain.DefaultWorkspaceID,
+			mockError:   storage.ErrURLExpired,
+			respCode:    http.StatusGone,
+			respError:   "url expired",
+		},
+		{
+			name:        "no url",
+			alias:       "123",
+			workspaceID: domain.DefaultWorkspaceID,
+			mockError:   storage.ErrURLNotFound,
+			respCode:    http.StatusNotFound,
+			respError:   "url not found",
+		},
 	}
 
 	for _, tc := range cases {
@@ -48,34 +83,115 @@ func TestRedirectHandler(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 			urlGetterMock := redirect.NewMockURLGetter(t)
+			clickTrackerMock := redirect.NewMockClickTracker(t)
+
+			//the test server host isn't a custom domain
+			urlGetterMock.On("GetDomain", "127.0.0.1").Return(domain.Domain{}, storage.ErrDomainNotFound).Once()
+
+			path := "/" + tc.alias
+			switch tc.workspace {
+			case "":
+			case "missing":
+				urlGetterMock.On("GetWorkspaceBySlug", tc.workspace).Return(domain.Workspace{}, storage.ErrWorkspaceNotFound).Once()
+			default:
+				urlGetterMock.On("GetWorkspaceBySlug", tc.workspace).Return(domain.Workspace{ID: tc.workspaceID}, nil).Once()
+			}
+			if tc.workspace != "" {
+				path = "/w/" + tc.workspace + path
+			}
 
-			if tc.respError == "" || tc.mockError != nil {
-				urlGetterMock.On("GetURL", tc.alias).Return(tc.url, tc.mockError).Once()
+			if tc.workspaceID != 0 {
+				ref := domain.LinkRef{WorkspaceID: tc.workspaceID, Alias: tc.alias}
+				urlGetterMock.On("GetURL", ref).Return(domain.Link{ID: 9, WorkspaceID: tc.workspaceID, URL: tc.url}, tc.mockError).Once()
+			}
+			//click is tracked only for resolved aliases
+			if tc.respError == "" {
+				clickTrackerMock.On("Track", mock.MatchedBy(func(c domain.Click) bool {
+					return c.Alias == tc.alias && c.LinkID == 9 && c.WorkspaceID == tc.workspaceID
+				})).Once()
 			}
 			//here using chi becouse there is URL param {alias}
 			r := chi.NewRouter()
-			r.Get("/{alias}", redirect.New(silentlog.NewSilentLogger(), urlGetterMock))
+			handler := redirect.New(silentlog.NewSilentLogger(), urlGetterMock, clickTrackerMock)
+			r.Get("/{alias}", handler)
+			r.Get("/w/{workspace}/{alias}", handler)
 
 			ts := httptest.NewServer(r)
 			defer ts.Close()
 
-			if tc.url != "" {
-				redirectedURL, err := api.GetRedirectURL(ts.URL + "/" + tc.alias)
+			if tc.respError == "" {
+				redirectedURL, err := api.GetRedirectURL(ts.URL + path)
 
 				require.NoError(t, err)
 
 				require.Equal(t, tc.url, redirectedURL)
 			} else {
-				body, err := api.GetRedirectResponse(ts.URL + "/" + tc.alias)
+				resp, err := http.Get(ts.URL + path)
 				require.NoError(t, err)
+				defer resp.Body.Close()
+
+				require.Equal(t, tc.respCode, resp.StatusCode)
 
-				var resp save.Response
+				var problem responseModel.Problem
 
-				require.NoError(t, json.Unmarshal(body, &resp))
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
 
-				require.Equal(t, tc.respError, resp.Error)
+				require.Equal(t, tc.respError, problem.Detail)
 			}
 
 		})
 	}
 }
+
+func TestRedirectHandler_Domain(t *testing.T) {
+	verifiedAt := time.Now()
+
+	cases := []struct {
+		name   string
+		domain domain.Domain
+		// error of the domain lookup
+		domainError error
+		ref         domain.LinkRef
+	}{
+		{
+			name:   "Verified domain",
+			domain: domain.Domain{WorkspaceID: 2, Hostname: "go.team-b.example", VerifiedAt: &verifiedAt},
+			ref:    domain.LinkRef{WorkspaceID: 2, Domain: "go.team-b.example", Alias: "abc"},
+		},
+		{
+			name:   "Unverified domain",
+			domain: domain.Domain{WorkspaceID: 2, Hostname: "go.team-b.example"},
+			ref:    domain.LinkRef{WorkspaceID: domain.DefaultWorkspaceID, Alias: "abc"},
+		},
+		{
+			name:        "Default domain",
+			domainError: storage.ErrDomainNotFound,
+			ref:         domain.LinkRef{WorkspaceID: domain.DefaultWorkspaceID, Alias: "abc"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			urlGetterMock := redirect.NewMockURLGetter(t)
+			//hosts are matched without the port and case-insensitively
+			urlGetterMock.On("GetDomain", "go.team-b.example").Return(tc.domain, tc.domainError).Once()
+			urlGetterMock.On("GetURL", tc.ref).Return(domain.Link{ID: 5, URL: "https://b.example"}, nil).Once()
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(silentlog.NewSilentLogger(), urlGetterMock, nil))
+
+			req, err := http.NewRequest(http.MethodGet, "/abc", nil)
+			require.NoError(t, err)
+			req.Host = "Go.Team-B.example:8080"
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusFound, rr.Code)
+			require.Equal(t, "https://b.example", rr.Header().Get("Location"))
+		})
+	}
+}
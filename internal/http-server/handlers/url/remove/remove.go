@@ -0,0 +1,71 @@
+package remove
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+//go:generate mockery --name=URLDeleter
+type URLDeleter interface {
+	DeleteURL(ref domain.LinkRef) error
+}
+
+// New deletes the link, the url_deleted event is written by the storage.
+func New(log *slog.Logger, urlDeleter URLDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.remove.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias url param is empty")
+			responseModel.RenderError(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		//links of custom domains are selected by the domain query param
+		ref := domain.LinkRef{
+			WorkspaceID: workspaceID,
+			Domain:      strings.ToLower(r.URL.Query().Get("domain")),
+			Alias:       alias,
+		}
+
+		err := urlDeleter.DeleteURL(ref)
+		if err != nil {
+			if errors.Is(err, storage.ErrURLNotFound) {
+				log.Info("url not found", slog.String("alias", alias))
+				responseModel.RenderError(w, r, http.StatusNotFound, "url not found")
+			} else {
+				log.Error("failed to delete url", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to delete url")
+			}
+			return
+		}
+
+		log.Info("url deleted", slog.String("alias", alias))
+		render.JSON(w, r, responseModel.OK())
+	}
+}
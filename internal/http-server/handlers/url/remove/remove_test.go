@@ -0,0 +1,80 @@
+package remove_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/url/remove"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey authenticates the requests, links are looked up in its workspace
+var testKey = domain.APIKey{ID: 1, WorkspaceID: 3, Scopes: []string{domain.ScopeAdmin}}
+
+func TestRemoveHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name: "Success",
+		},
+		{
+			name:      "Not found",
+			respCode:  http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrURLNotFound,
+		},
+		{
+			name:      "Storage error",
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to delete url",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			urlDeleterMock := remove.NewMockURLDeleter(t)
+			urlDeleterMock.On("DeleteURL", domain.LinkRef{WorkspaceID: testKey.WorkspaceID, Alias: "abc"}).Return(tc.mockError).Once()
+
+			//here using chi becouse there is URL param {alias}
+			r := chi.NewRouter()
+			r.Delete("/url/{alias}", remove.New(silentlog.NewSilentLogger(), urlDeleterMock))
+
+			req, err := http.NewRequest(http.MethodDelete, "/url/abc", nil)
+			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp responseModel.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, responseModel.StatusOK, resp.Status)
+		})
+	}
+}
@@ -5,6 +5,8 @@
 package save
 
 import (
+	"short-url/internal/http-server/model/domain"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -36,8 +38,8 @@ func (_m *MockURLSaver) EXPECT() *MockURLSaver_Expecter {
 }
 
 // SaveURL provides a mock function for the type MockURLSaver
-func (_mock *MockURLSaver) SaveURL(urlToSave string, alias string) (int64, error) {
-	ret := _mock.Called(urlToSave, alias)
+func (_mock *MockURLSaver) SaveURL(link domain.Link) (int64, error) {
+	ret := _mock.Called(link)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SaveURL")
@@ -45,16 +47,16 @@ func (_mock *MockURLSaver) SaveURL(urlToSave string, alias string) (int64, error
 
 	var r0 int64
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string, string) (int64, error)); ok {
-		return returnFunc(urlToSave, alias)
+	if returnFunc, ok := ret.Get(0).(func(domain.Link) (int64, error)); ok {
+		return returnFunc(link)
 	}
-	if returnFunc, ok := ret.Get(0).(func(string, string) int64); ok {
-		r0 = returnFunc(urlToSave, alias)
+	if returnFunc, ok := ret.Get(0).(func(domain.Link) int64); ok {
+		r0 = returnFunc(link)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
-	if returnFunc, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = returnFunc(urlToSave, alias)
+	if returnFunc, ok := ret.Get(1).(func(domain.Link) error); ok {
+		r1 = returnFunc(link)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -67,36 +69,294 @@ type MockURLSaver_SaveURL_Call struct {
 }
 
 // SaveURL is a helper method to define mock.On call
-//   - urlToSave string
+//   - link domain.Link
+func (_e *MockURLSaver_Expecter) SaveURL(link interface{}) *MockURLSaver_SaveURL_Call {
+	return &MockURLSaver_SaveURL_Call{Call: _e.mock.On("SaveURL", link)}
+}
+
+func (_c *MockURLSaver_SaveURL_Call) Run(run func(link domain.Link)) *MockURLSaver_SaveURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 domain.Link
+		if args[0] != nil {
+			arg0 = args[0].(domain.Link)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockURLSaver_SaveURL_Call) Return(n int64, err error) *MockURLSaver_SaveURL_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockURLSaver_SaveURL_Call) RunAndReturn(run func(link domain.Link) (int64, error)) *MockURLSaver_SaveURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveOrGetURL provides a mock function for the type MockURLSaver
+func (_mock *MockURLSaver) SaveOrGetURL(link domain.Link) (domain.Link, bool, error) {
+	ret := _mock.Called(link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveOrGetURL")
+	}
+
+	var r0 domain.Link
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(domain.Link) (domain.Link, bool, error)); ok {
+		return returnFunc(link)
+	}
+	if returnFunc, ok := ret.Get(0).(func(domain.Link) domain.Link); ok {
+		r0 = returnFunc(link)
+	} else {
+		r0 = ret.Get(0).(domain.Link)
+	}
+	if returnFunc, ok := ret.Get(1).(func(domain.Link) bool); ok {
+		r1 = returnFunc(link)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	if returnFunc, ok := ret.Get(2).(func(domain.Link) error); ok {
+		r2 = returnFunc(link)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockURLSaver_SaveOrGetURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveOrGetURL'
+type MockURLSaver_SaveOrGetURL_Call struct {
+	*mock.Call
+}
+
+// SaveOrGetURL is a helper method to define mock.On call
+//   - link domain.Link
+func (_e *MockURLSaver_Expecter) SaveOrGetURL(link interface{}) *MockURLSaver_SaveOrGetURL_Call {
+	return &MockURLSaver_SaveOrGetURL_Call{Call: _e.mock.On("SaveOrGetURL", link)}
+}
+
+func (_c *MockURLSaver_SaveOrGetURL_Call) Run(run func(link domain.Link)) *MockURLSaver_SaveOrGetURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 domain.Link
+		if args[0] != nil {
+			arg0 = args[0].(domain.Link)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockURLSaver_SaveOrGetURL_Call) Return(link1 domain.Link, b bool, err error) *MockURLSaver_SaveOrGetURL_Call {
+	_c.Call.Return(link1, b, err)
+	return _c
+}
+
+func (_c *MockURLSaver_SaveOrGetURL_Call) RunAndReturn(run func(link domain.Link) (domain.Link, bool, error)) *MockURLSaver_SaveOrGetURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWorkspace provides a mock function for the type MockURLSaver
+func (_mock *MockURLSaver) GetWorkspace(id int64) (domain.Workspace, error) {
+	ret := _mock.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspace")
+	}
+
+	var r0 domain.Workspace
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(int64) (domain.Workspace, error)); ok {
+		return returnFunc(id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(int64) domain.Workspace); ok {
+		r0 = returnFunc(id)
+	} else {
+		r0 = ret.Get(0).(domain.Workspace)
+	}
+	if returnFunc, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = returnFunc(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockURLSaver_GetWorkspace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWorkspace'
+type MockURLSaver_GetWorkspace_Call struct {
+	*mock.Call
+}
+
+// GetWorkspace is a helper method to define mock.On call
+//   - id int64
+func (_e *MockURLSaver_Expecter) GetWorkspace(id interface{}) *MockURLSaver_GetWorkspace_Call {
+	return &MockURLSaver_GetWorkspace_Call{Call: _e.mock.On("GetWorkspace", id)}
+}
+
+func (_c *MockURLSaver_GetWorkspace_Call) Run(run func(id int64)) *MockURLSaver_GetWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int64
+		if args[0] != nil {
+			arg0 = args[0].(int64)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockURLSaver_GetWorkspace_Call) Return(workspace domain.Workspace, err error) *MockURLSaver_GetWorkspace_Call {
+	_c.Call.Return(workspace, err)
+	return _c
+}
+
+func (_c *MockURLSaver_GetWorkspace_Call) RunAndReturn(run func(id int64) (domain.Workspace, error)) *MockURLSaver_GetWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockAliasStrategy creates a new instance of MockAliasStrategy. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAliasStrategy(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAliasStrategy {
+	mock := &MockAliasStrategy{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAliasStrategy is an autogenerated mock type for the AliasStrategy type
+type MockAliasStrategy struct {
+	mock.Mock
+}
+
+type MockAliasStrategy_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAliasStrategy) EXPECT() *MockAliasStrategy_Expecter {
+	return &MockAliasStrategy_Expecter{mock: &_m.Mock}
+}
+
+// Generate provides a mock function for the type MockAliasStrategy
+func (_mock *MockAliasStrategy) Generate(attempt int) (string, error) {
+	ret := _mock.Called(attempt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Generate")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(int) (string, error)); ok {
+		return returnFunc(attempt)
+	}
+	if returnFunc, ok := ret.Get(0).(func(int) string); ok {
+		r0 = returnFunc(attempt)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(int) error); ok {
+		r1 = returnFunc(attempt)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAliasStrategy_Generate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Generate'
+type MockAliasStrategy_Generate_Call struct {
+	*mock.Call
+}
+
+// Generate is a helper method to define mock.On call
+//   - attempt int
+func (_e *MockAliasStrategy_Expecter) Generate(attempt interface{}) *MockAliasStrategy_Generate_Call {
+	return &MockAliasStrategy_Generate_Call{Call: _e.mock.On("Generate", attempt)}
+}
+
+func (_c *MockAliasStrategy_Generate_Call) Run(run func(attempt int)) *MockAliasStrategy_Generate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int
+		if args[0] != nil {
+			arg0 = args[0].(int)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAliasStrategy_Generate_Call) Return(s string, err error) *MockAliasStrategy_Generate_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockAliasStrategy_Generate_Call) RunAndReturn(run func(attempt int) (string, error)) *MockAliasStrategy_Generate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reserved provides a mock function for the type MockAliasStrategy
+func (_mock *MockAliasStrategy) Reserved(alias string) bool {
+	ret := _mock.Called(alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reserved")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = returnFunc(alias)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// MockAliasStrategy_Reserved_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reserved'
+type MockAliasStrategy_Reserved_Call struct {
+	*mock.Call
+}
+
+// Reserved is a helper method to define mock.On call
 //   - alias string
-func (_e *MockURLSaver_Expecter) SaveURL(urlToSave interface{}, alias interface{}) *MockURLSaver_SaveURL_Call {
-	return &MockURLSaver_SaveURL_Call{Call: _e.mock.On("SaveURL", urlToSave, alias)}
+func (_e *MockAliasStrategy_Expecter) Reserved(alias interface{}) *MockAliasStrategy_Reserved_Call {
+	return &MockAliasStrategy_Reserved_Call{Call: _e.mock.On("Reserved", alias)}
 }
 
-func (_c *MockURLSaver_SaveURL_Call) Run(run func(urlToSave string, alias string)) *MockURLSaver_SaveURL_Call {
+func (_c *MockAliasStrategy_Reserved_Call) Run(run func(alias string)) *MockAliasStrategy_Reserved_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 string
 		if args[0] != nil {
 			arg0 = args[0].(string)
 		}
-		var arg1 string
-		if args[1] != nil {
-			arg1 = args[1].(string)
-		}
 		run(
 			arg0,
-			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *MockURLSaver_SaveURL_Call) Return(n int64, err error) *MockURLSaver_SaveURL_Call {
-	_c.Call.Return(n, err)
+func (_c *MockAliasStrategy_Reserved_Call) Return(b bool) *MockAliasStrategy_Reserved_Call {
+	_c.Call.Return(b)
 	return _c
 }
 
-func (_c *MockURLSaver_SaveURL_Call) RunAndReturn(run func(urlToSave string, alias string) (int64, error)) *MockURLSaver_SaveURL_Call {
+func (_c *MockAliasStrategy_Reserved_Call) RunAndReturn(run func(alias string) bool) *MockAliasStrategy_Reserved_Call {
 	_c.Call.Return(run)
 	return _c
 }
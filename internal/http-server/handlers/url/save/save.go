@@ -2,88 +2,231 @@ package save
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	mwRateLimit "short-url/internal/http-server/middleware/ratelimit"
+	"short-url/internal/http-server/model/domain"
 	responseModel "short-url/internal/http-server/model/response"
-	"short-url/internal/lib/random"
+	"short-url/internal/lib/expiration"
+	"short-url/internal/lib/shorturl"
 	"short-url/internal/lib/sl"
 	"short-url/internal/storage"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 )
 
-const aliasLength = 6
-
 type Request struct {
 	URL   string `json:"url" validate:"required,url"`
 	Alias string `json:"alias,omitempty"`
+	// absolute expiration time, mutually exclusive with TTL
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// link lifetime as a Go duration, e.g. "72h"
+	TTL string `json:"ttl,omitempty"`
+	// verified custom domain of the workspace the link is served on, the default domain if empty
+	Domain string `json:"domain,omitempty" validate:"omitempty,fqdn"`
+	// return the active link of the workspace to the same normalized url instead of creating one,
+	// only for requests without an alias
+	Dedupe bool `json:"dedupe,omitempty"`
 }
 
+// ReservedAliases are taken by the routes next to GET /{alias} and GET /url/{alias},
+// links with them could never be reached
+var ReservedAliases = []string{"admin", "domains", "export", "url", "w"}
+
+// ErrGeneratedAlias rejects custom aliases the sequential strategies will generate later
+var ErrGeneratedAlias = errors.New("field alias is reserved for generated aliases")
+
 type Response struct {
 	responseModel.Response
 	Alias string `json:"alias,omitempty"`
+	// fully-qualified short url on the domain of the link
+	ShortURL  string     `json:"short_url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 //go:generate mockery --name=URLSaver
 type URLSaver interface {
-	SaveURL(urlToSave string, alias string) (int64, error)
+	SaveURL(link domain.Link) (int64, error)
+	SaveOrGetURL(link domain.Link) (domain.Link, bool, error)
+	GetWorkspace(id int64) (domain.Workspace, error)
+}
+
+//go:generate mockery --name=AliasStrategy
+type AliasStrategy interface {
+	// Generate returns a random alias or the code of the next id of a sequence
+	// for the attempt of the save, attempts start at 1
+	Generate(attempt int) (string, error)
+	// Reserved reports whether a custom alias may be generated later
+	Reserved(alias string) bool
 }
 
-func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
+// New saves the link and returns its short url built by shortURLs.
+// Links without an alias get one from aliases, it is regenerated up to maxAttempts times while it is taken
+// (by an imported alias for the sequential strategies), custom aliases reserved by aliases are rejected. Deduplicated requests answer an existing link with 200.
+func New(log *slog.Logger, urlSaver URLSaver, shortURLs shorturl.Builder, aliases AliasStrategy, maxAttempts int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.url.save.new"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
 		var req Request
 
 		err := render.DecodeJSON(r.Body, &req)
 		if err != nil {
 			log.Error("can't decode request body", sl.Err(err))
-			render.JSON(w, r, responseModel.Error("can't decode request body"))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "can't decode request body")
 			return
 		}
 		log.Info("request body decoded", slog.Any("request", req))
 
-		if err := validator.New().Struct(req); err != nil {
-			validErrs := err.(validator.ValidationErrors)
-
+		link, err := req.Link(workspaceID, time.Now())
+		var validErrs validator.ValidationErrors
+		if errors.As(err, &validErrs) {
 			log.Error("invalid request body", sl.Err(err))
 
-			render.JSON(w, r, responseModel.ValidationError(validErrs))
+			responseModel.RenderValidationError(w, r, validErrs)
+			return
+		}
+		if err != nil {
+			log.Info("invalid request", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, err.Error())
 			return
 		}
+		if req.Alias != "" && aliases.Reserved(req.Alias) {
+			log.Info("custom alias is reserved", slog.String("alias", req.Alias))
+			responseModel.RenderError(w, r, http.StatusBadRequest, ErrGeneratedAlias.Error())
+			return
+		}
+		//the root key has no id and isn't recorded
+		if key, _ := mwAuth.APIKeyFromContext(r.Context()); key.ID != 0 {
+			link.APIKeyID = &key.ID
+		}
 
-		alias := req.Alias
-		if alias == "" {
-			alias = random.NewRandomString(aliasLength)
+		//aliases of other workspaces on the default domain are under their slug
+		var slug string
+		if link.Domain == "" && workspaceID != domain.DefaultWorkspaceID {
+			ws, err := urlSaver.GetWorkspace(workspaceID)
+			if err != nil {
+				log.Error("failed to get workspace", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to add url")
+				return
+			}
+			slug = ws.Slug
 		}
 
-		id, err := urlSaver.SaveURL(req.URL, req.Alias)
+		//collisions of generated aliases are retried, a taken custom alias is a conflict
+		generated := req.Alias == ""
+		dedupe := req.Dedupe && generated
+		var saved domain.Link
+		created := true
+		for attempt := 1; ; attempt++ {
+			if generated {
+				link.Alias, err = aliases.Generate(attempt)
+				if err != nil {
+					log.Error("failed to generate alias", sl.Err(err))
+					responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to add url")
+					return
+				}
+			}
+			if dedupe {
+				saved, created, err = urlSaver.SaveOrGetURL(link)
+			} else {
+				saved = link
+				saved.ID, err = urlSaver.SaveURL(link)
+			}
+			if !generated || !errors.Is(err, storage.ErrURLExists) || attempt >= maxAttempts {
+				break
+			}
+			log.Info("generated alias exists, retrying", slog.String("alias", link.Alias), slog.Int("attempt", attempt))
+		}
+		if errors.Is(err, storage.ErrURLExists) && generated {
+			log.Error("failed to generate a unique alias", slog.Int("attempts", maxAttempts))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to generate a unique alias")
+			return
+		}
 		if errors.Is(err, storage.ErrURLExists) {
 			log.Info("url already exists", slog.String("url", req.URL))
-			render.JSON(w, r, responseModel.Error("url already exists"))
+			responseModel.RenderError(w, r, http.StatusConflict, "url already exists")
+			return
+		}
+		if errors.Is(err, storage.ErrDomainNotFound) {
+			log.Info("domain not found", slog.String("domain", link.Domain))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "domain not found or not verified")
+			return
+		}
+		if errors.Is(err, storage.ErrQuotaExceeded) {
+			log.Info("monthly link quota exceeded", slog.Int64("workspace_id", workspaceID))
+			//the quotas are reset at the start of the next month
+			w.Header().Set("Retry-After", mwRateLimit.RetryAfter(time.Until(domain.NextUsagePeriod(time.Now()))))
+			responseModel.RenderError(w, r, http.StatusTooManyRequests, "monthly link quota exceeded")
 			return
 		}
 		if err != nil {
 			log.Error("failed to add url", sl.Err(err))
-			render.JSON(w, r, responseModel.Error("failed to add url"))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to add url")
+			return
+		}
+		if !created {
+			log.Info("existing url returned", slog.Int64("id", saved.ID), slog.String("alias", saved.Alias))
+			ResponseOK(w, r, saved.Alias, shortURLs.Build(saved, slug), saved.ExpiresAt)
 			return
 		}
-		log.Info("id is added", slog.Int64("id", id))
+		log.Info("id is added", slog.Int64("id", saved.ID))
 
-		ResponseOK(w, r, alias)
+		responseModel.Status(r, http.StatusCreated)
+		ResponseOK(w, r, saved.Alias, shortURLs.Build(saved, slug), saved.ExpiresAt)
 	}
 }
 
-func ResponseOK(w http.ResponseWriter, r *http.Request, alias string) {
+// Link validates the request and builds the link of the workspace. Invalid requests get
+// validator.ValidationErrors or the error of the alias or the expiration, its message is meant for the client.
+func (req Request) Link(workspaceID int64, now time.Time) (domain.Link, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return domain.Link{}, err
+	}
+	if strings.Contains(req.Alias, "/") {
+		return domain.Link{}, errors.New("field alias can't contain /")
+	}
+	if slices.Contains(ReservedAliases, req.Alias) {
+		return domain.Link{}, fmt.Errorf("field alias %q is reserved", req.Alias)
+	}
+
+	expiresAt, err := expiration.Resolve(req.ExpiresAt, req.TTL, now)
+	if err != nil {
+		return domain.Link{}, err
+	}
+
+	return domain.Link{
+		WorkspaceID: workspaceID,
+		URL:         req.URL,
+		Domain:      strings.ToLower(req.Domain),
+		Alias:       req.Alias,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func ResponseOK(w http.ResponseWriter, r *http.Request, alias string, shortURL string, expiresAt *time.Time) {
 	render.JSON(w, r, Response{
-		Response: responseModel.OK(),
-		Alias:    alias,
+		Response:  responseModel.OK(),
+		Alias:     alias,
+		ShortURL:  shortURL,
+		ExpiresAt: expiresAt,
 	})
 }
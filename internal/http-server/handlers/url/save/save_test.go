@@ -4,22 +4,51 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"short-url/internal/http-server/handlers/url/save"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
 	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/lib/shorturl"
+	"short-url/internal/storage"
+	"strings"
 	"testing"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
+// testKey authenticates the requests, links are looked up in its workspace
+var testKey = domain.APIKey{ID: 1, WorkspaceID: 3, Scopes: []string{domain.ScopeAdmin}}
+
+var shortURLs = shorturl.Builder{DefaultURL: "https://sho.rt", Scheme: "https"}
+
+// newAliases is a strategy reserving only the reserved custom aliases
+func newAliases(t *testing.T, reserved ...string) *save.MockAliasStrategy {
+	m := save.NewMockAliasStrategy(t)
+	for _, alias := range reserved {
+		m.On("Reserved", alias).Return(true).Maybe()
+	}
+	m.On("Reserved", mock.Anything).Return(false).Maybe()
+	return m
+}
+
 func TestSaveHandler(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
 	cases := []struct {
 		name      string
 		alias     string
 		url       string
+		domain    string
+		ttl       string
+		expiresAt *time.Time
+		expires   bool
+		respCode  int
 		respError string
 		mockError error
 	}{
@@ -37,18 +66,106 @@ func TestSaveHandler(t *testing.T) {
 			name:      "Empty url",
 			url:       "",
 			alias:     "some_alias",
+			respCode:  http.StatusBadRequest,
 			respError: "invalid body,field URL is a required field",
 		},
 		{
 			name:      "Invalid url",
 			url:       "invalid url text",
 			alias:     "some_alias",
+			respCode:  http.StatusBadRequest,
 			respError: "invalid body,field URL is not in URL format",
 		},
+		{
+			name:    "TTL",
+			alias:   "ttl_alias",
+			url:     "http://google.com",
+			ttl:     "72h",
+			expires: true,
+		},
+		{
+			name:      "Expires at",
+			alias:     "expiring_alias",
+			url:       "http://google.com",
+			expiresAt: &future,
+			expires:   true,
+		},
+		{
+			name:      "Expires at in the past",
+			alias:     "some_alias",
+			url:       "http://google.com",
+			expiresAt: &past,
+			respCode:  http.StatusBadRequest,
+			respError: "field expires_at must be in the future",
+		},
+		{
+			name:      "Reserved alias",
+			alias:     "url",
+			url:       "http://google.com",
+			respCode:  http.StatusBadRequest,
+			respError: `field alias "url" is reserved`,
+		},
+		{
+			name:      "Invalid TTL",
+			alias:     "some_alias",
+			url:       "http://google.com",
+			ttl:       "-1h",
+			respCode:  http.StatusBadRequest,
+			respError: "field ttl must be a positive duration, e.g. 72h",
+		},
+		{
+			name:      "Both TTL and expires at",
+			alias:     "some_alias",
+			url:       "http://google.com",
+			ttl:       "1h",
+			expiresAt: &future,
+			respCode:  http.StatusBadRequest,
+			respError: "only one of expires_at and ttl is allowed",
+		},
+		{
+			name:   "Custom domain",
+			alias:  "domain_alias",
+			url:    "http://google.com",
+			domain: "Go.Example",
+		},
+		{
+			name:      "Invalid domain",
+			alias:     "some_alias",
+			url:       "http://google.com",
+			domain:    "not a domain",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid body,field Domain is not valid",
+		},
+		{
+			name:      "Unverified domain",
+			alias:     "some_alias",
+			url:       "http://google.com",
+			domain:    "go.example",
+			respCode:  http.StatusBadRequest,
+			respError: "domain not found or not verified",
+			mockError: storage.ErrDomainNotFound,
+		},
+		{
+			name:      "Alias exists",
+			url:       "http://google.com",
+			alias:     "some_alias",
+			respCode:  http.StatusConflict,
+			respError: "url already exists",
+			mockError: storage.ErrURLExists,
+		},
+		{
+			name:      "Quota exceeded",
+			url:       "http://google.com",
+			alias:     "some_alias",
+			respCode:  http.StatusTooManyRequests,
+			respError: "monthly link quota exceeded",
+			mockError: storage.ErrQuotaExceeded,
+		},
 		{
 			name:      "SaveURL Error",
 			url:       "http://google.com",
 			alias:     "some_alias",
+			respCode:  http.StatusInternalServerError,
 			respError: "failed to add url",
 			mockError: errors.New("unexpected error"),
 		},
@@ -61,6 +178,13 @@ func TestSaveHandler(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 			urlSaverMock := save.NewMockURLSaver(t)
+			aliasesMock := newAliases(t)
+
+			//requests without an alias are saved with the generated one
+			wantAlias := tc.alias
+			if wantAlias == "" {
+				wantAlias = "generated"
+			}
 
 			/*
 				explanation of the condition:
@@ -73,28 +197,363 @@ func TestSaveHandler(t *testing.T) {
 					mockError = nil - when we want to return err from storage
 			*/
 			if tc.respError == "" || tc.mockError != nil {
-				urlSaverMock.On("SaveURL", tc.url, mock.AnythingOfType("string")).Return(int64(1), tc.mockError).Once()
+				urlSaverMock.On("SaveURL", mock.MatchedBy(func(link domain.Link) bool {
+					return link.URL == tc.url && link.Alias == wantAlias && (link.ExpiresAt != nil) == tc.expires &&
+						link.Domain == strings.ToLower(tc.domain)
+				})).Return(int64(1), tc.mockError).Once()
+				if tc.alias == "" {
+					aliasesMock.On("Generate", 1).Return("generated", nil).Once()
+				}
+				//the short url of the default domain contains the workspace slug
+				if tc.domain == "" {
+					urlSaverMock.On("GetWorkspace", testKey.WorkspaceID).Return(domain.Workspace{ID: 3, Slug: "teamc"}, nil).Once()
+				}
 			}
 
-			handler := save.New(silentlog.NewSilentLogger(), urlSaverMock)
+			handler := save.New(silentlog.NewSilentLogger(), urlSaverMock, shortURLs, aliasesMock, 3)
 
-			input := fmt.Sprintf(`{"url": "%s", "alias": "%s"}`, tc.url, tc.alias)
+			input, err := json.Marshal(save.Request{
+				URL:       tc.url,
+				Alias:     tc.alias,
+				TTL:       tc.ttl,
+				ExpiresAt: tc.expiresAt,
+				Domain:    tc.domain,
+			})
+			require.NoError(t, err)
 
-			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(input)))
+			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader(input))
 
 			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
 
 			rr := httptest.NewRecorder()
 
 			handler.ServeHTTP(rr, req)
-			require.Equal(t, rr.Code, http.StatusOK)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+				require.Equal(t, responseModel.ContentTypeProblem, rr.Header().Get("Content-Type"))
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problemMessage(problem))
+				if tc.respCode == http.StatusTooManyRequests {
+					require.NotEmpty(t, rr.Header().Get("Retry-After"))
+				}
+				return
+			}
+			require.Equal(t, http.StatusCreated, rr.Code)
+
+			var resp save.Response
+
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			require.Equal(t, responseModel.StatusOK, resp.Status)
+			require.Equal(t, wantAlias, resp.Alias)
+			require.Equal(t, tc.expires, resp.ExpiresAt != nil)
+			if tc.domain != "" {
+				require.Equal(t, "https://go.example/"+tc.alias, resp.ShortURL)
+			} else {
+				require.Equal(t, "https://sho.rt/w/teamc/"+resp.Alias, resp.ShortURL)
+			}
+
+		})
+	}
+}
+
+func TestSaveHandler_APIKey(t *testing.T) {
+	urlSaverMock := save.NewMockURLSaver(t)
+	urlSaverMock.On("SaveURL", mock.MatchedBy(func(link domain.Link) bool {
+		return link.APIKeyID != nil && *link.APIKeyID == 7 && link.WorkspaceID == 3
+	})).Return(int64(1), nil).Once()
+	urlSaverMock.On("GetWorkspace", int64(3)).Return(domain.Workspace{ID: 3, Slug: "teamc"}, nil).Once()
+	aliasesMock := newAliases(t)
+	aliasesMock.On("Generate", 1).Return("generated", nil).Once()
+
+	handler := save.New(silentlog.NewSilentLogger(), urlSaverMock, shortURLs, aliasesMock, 3)
+
+	req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(`{"url": "http://google.com"}`)))
+	require.NoError(t, err)
+	req = req.WithContext(mwAuth.WithAPIKey(req.Context(), domain.APIKey{ID: 7, WorkspaceID: 3, Scopes: []string{domain.ScopeLinksWrite}}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestSaveHandler_DefaultWorkspace(t *testing.T) {
+	urlSaverMock := save.NewMockURLSaver(t)
+	urlSaverMock.On("SaveURL", mock.Anything).Return(int64(1), nil).Once()
+
+	handler := save.New(silentlog.NewSilentLogger(), urlSaverMock, shortURLs, newAliases(t), 3)
+
+	req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(`{"url": "http://google.com", "alias": "root"}`)))
+	require.NoError(t, err)
+	req = req.WithContext(mwAuth.WithAPIKey(req.Context(), mwAuth.RootKey))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	//aliases of the default workspace are served on the root of the default domain
+	var resp save.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "https://sho.rt/root", resp.ShortURL)
+}
+
+func TestSaveHandler_GeneratedAlias(t *testing.T) {
+	//the sequential strategy issues the code later, the custom alias would make its saves collide
+	handler := save.New(silentlog.NewSilentLogger(), save.NewMockURLSaver(t), shortURLs, newAliases(t, "b7"), 3)
+
+	req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(`{"url": "http://google.com", "alias": "b7"}`)))
+	require.NoError(t, err)
+	req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	var problem responseModel.Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	require.Equal(t, save.ErrGeneratedAlias.Error(), problem.Detail)
+}
+
+func TestSaveHandler_Legacy(t *testing.T) {
+	urlSaverMock := save.NewMockURLSaver(t)
+	urlSaverMock.On("SaveURL", mock.Anything).Return(int64(1), nil).Once()
+	urlSaverMock.On("GetWorkspace", testKey.WorkspaceID).Return(domain.Workspace{ID: 3, Slug: "teamc"}, nil).Once()
+
+	handler := save.New(silentlog.NewSilentLogger(), urlSaverMock, shortURLs, newAliases(t), 3)
+
+	for _, tc := range []struct {
+		body      string
+		respError string
+	}{
+		{body: `{"url": "http://google.com", "alias": "legacy"}`},
+		{body: `{"url": "invalid url text"}`, respError: "invalid body,field URL is not in URL format"},
+	} {
+		req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(tc.body)))
+		require.NoError(t, err)
+		req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+		req = req.WithContext(responseModel.WithLegacy(req.Context()))
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		//old clients always get 200 and the status/error body
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp save.Response
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Equal(t, tc.respError, resp.Error)
+	}
+}
+
+func TestSaveHandler_AliasCollision(t *testing.T) {
+	cases := []struct {
+		name  string
+		alias string
+		// aliases returned by the generator for the attempts
+		generated []string
+		// storage errors of the saves
+		saveErrors []error
+		genError   error
+		respCode   int
+		respError  string
+	}{
+		{
+			name:       "Retried",
+			generated:  []string{"gen1", "gen2"},
+			saveErrors: []error{storage.ErrURLExists, nil},
+			respCode:   http.StatusCreated,
+		},
+		{
+			name:       "Attempts exhausted",
+			generated:  []string{"gen1", "gen2", "gen3"},
+			saveErrors: []error{storage.ErrURLExists, storage.ErrURLExists, storage.ErrURLExists},
+			respCode:   http.StatusInternalServerError,
+			respError:  "failed to generate a unique alias",
+		},
+		{
+			name:       "Custom alias isn't retried",
+			alias:      "custom",
+			saveErrors: []error{storage.ErrURLExists},
+			respCode:   http.StatusConflict,
+			respError:  "url already exists",
+		},
+		{
+			name:      "Generator error",
+			generated: []string{""},
+			genError:  errors.New("unexpected error"),
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to add url",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			urlSaverMock := save.NewMockURLSaver(t)
+			aliasesMock := newAliases(t)
+			urlSaverMock.On("GetWorkspace", testKey.WorkspaceID).Return(domain.Workspace{ID: 3, Slug: "teamc"}, nil).Once()
+
+			for i, alias := range tc.generated {
+				aliasesMock.On("Generate", i+1).Return(alias, tc.genError).Once()
+			}
+			for i, saveErr := range tc.saveErrors {
+				alias := tc.alias
+				if alias == "" {
+					alias = tc.generated[i]
+				}
+				urlSaverMock.On("SaveURL", mock.MatchedBy(func(link domain.Link) bool {
+					return link.Alias == alias
+				})).Return(int64(1), saveErr).Once()
+			}
+
+			handler := save.New(silentlog.NewSilentLogger(), urlSaverMock, shortURLs, aliasesMock, 3)
+
+			input, err := json.Marshal(save.Request{URL: "http://google.com", Alias: tc.alias})
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader(input))
+			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respCode, rr.Code)
+			if tc.respError != "" {
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
 
 			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.generated[len(tc.generated)-1], resp.Alias)
+			require.Equal(t, "https://sho.rt/w/teamc/"+resp.Alias, resp.ShortURL)
+		})
+	}
+}
+
+func TestSaveHandler_Dedupe(t *testing.T) {
+	cases := []struct {
+		name     string
+		alias    string
+		existing string
+		respCode int
+		// alias of the response
+		respAlias string
+	}{
+		{
+			name:      "Existing link",
+			existing:  "old",
+			respCode:  http.StatusOK,
+			respAlias: "old",
+		},
+		{
+			name:      "New link",
+			respCode:  http.StatusCreated,
+			respAlias: "generated",
+		},
+		{
+			name:      "Custom alias isn't deduplicated",
+			alias:     "custom",
+			respCode:  http.StatusCreated,
+			respAlias: "custom",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			urlSaverMock := save.NewMockURLSaver(t)
+			aliasesMock := newAliases(t)
+			urlSaverMock.On("GetWorkspace", testKey.WorkspaceID).Return(domain.Workspace{ID: 3, Slug: "teamc"}, nil).Once()
+
+			if tc.alias != "" {
+				urlSaverMock.On("SaveURL", mock.MatchedBy(func(link domain.Link) bool {
+					return link.Alias == tc.alias
+				})).Return(int64(1), nil).Once()
+			} else {
+				aliasesMock.On("Generate", 1).Return("generated", nil).Once()
+				saved := domain.Link{ID: 1, WorkspaceID: 3, URL: "http://google.com", Alias: "generated"}
+				created := tc.existing == ""
+				if !created {
+					saved = domain.Link{ID: 7, WorkspaceID: 3, URL: "http://GOOGLE.com", Alias: tc.existing}
+				}
+				urlSaverMock.On("SaveOrGetURL", mock.MatchedBy(func(link domain.Link) bool {
+					return link.Alias == "generated" && link.URL == "http://google.com"
+				})).Return(saved, created, nil).Once()
+			}
+
+			handler := save.New(silentlog.NewSilentLogger(), urlSaverMock, shortURLs, aliasesMock, 3)
+
+			input, err := json.Marshal(save.Request{URL: "http://google.com", Alias: tc.alias, Dedupe: true})
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader(input))
+			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respCode, rr.Code)
 
+			var resp save.Response
 			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respAlias, resp.Alias)
+			require.Equal(t, "https://sho.rt/w/teamc/"+tc.respAlias, resp.ShortURL)
+		})
+	}
+}
 
-			require.Equal(t, tc.respError, resp.Error)
+// problemMessage joins the detail and field errors like the legacy error message
+func problemMessage(problem responseModel.Problem) string {
+	msgs := []string{problem.Detail}
+	for _, e := range problem.Errors {
+		msgs = append(msgs, e.Message)
+	}
+	return strings.Join(msgs, ",")
+}
 
+func TestRequest_Link(t *testing.T) {
+	cases := []struct {
+		name  string
+		alias string
+		err   string
+	}{
+		{name: "Custom alias", alias: "promo"},
+		{name: "Generated alias", alias: ""},
+		{name: "Alias with a route prefix", alias: "urls"},
+		{name: "Route prefix", alias: "url", err: `field alias "url" is reserved`},
+		{name: "Domains route", alias: "domains", err: `field alias "domains" is reserved`},
+		{name: "Admin route", alias: "admin", err: `field alias "admin" is reserved`},
+		{name: "Workspace route", alias: "w", err: `field alias "w" is reserved`},
+		{name: "Export route", alias: "export", err: `field alias "export" is reserved`},
+		{name: "Slash", alias: "a/b", err: "field alias can't contain /"},
+		{name: "Trailing slash", alias: "promo/", err: "field alias can't contain /"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := save.Request{URL: "https://example.com", Alias: tc.alias}
+			link, err := req.Link(testKey.WorkspaceID, time.Now())
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.alias, link.Alias)
 		})
 	}
 }
@@ -0,0 +1,142 @@
+package stats
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+const (
+	defaultRange = 7 * 24 * time.Hour
+	// hourly buckets of a longer range make the response too large
+	maxHourlyRange = 31 * 24 * time.Hour
+)
+
+type Response struct {
+	responseModel.Response
+	domain.ClickStats
+}
+
+//go:generate mockery --name=StatsGetter
+type StatsGetter interface {
+	GetLink(ref domain.LinkRef) (domain.Link, error)
+	GetClickStats(linkID int64, from, to time.Time, bucket string) (domain.ClickStats, error)
+}
+
+// New returns click stats of the alias. Query params: from and to in RFC 3339
+// (the last 7 days by default) and bucket=hour|day (day by default).
+func New(log *slog.Logger, statsGetter StatsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.stats.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias url param is empty")
+			responseModel.RenderError(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		//links of custom domains are selected by the domain query param
+		ref := domain.LinkRef{
+			WorkspaceID: workspaceID,
+			Domain:      strings.ToLower(r.URL.Query().Get("domain")),
+			Alias:       alias,
+		}
+
+		from, to, bucket, err := parseQuery(r, time.Now())
+		if err != nil {
+			log.Info("invalid query", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		//the link is looked up in the workspace first, so stats of other workspaces are not found
+		link, err := statsGetter.GetLink(ref)
+		if err != nil {
+			if errors.Is(err, storage.ErrURLNotFound) {
+				log.Info("url not found", slog.String("alias", alias))
+				responseModel.RenderError(w, r, http.StatusNotFound, "url not found")
+			} else {
+				log.Error("failed to get url", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to get stats")
+			}
+			return
+		}
+
+		stats, err := statsGetter.GetClickStats(link.ID, from, to, bucket)
+		if err != nil {
+			if errors.Is(err, storage.ErrURLNotFound) {
+				log.Info("url not found", slog.String("alias", alias))
+				responseModel.RenderError(w, r, http.StatusNotFound, "url not found")
+			} else {
+				log.Error("failed to get stats", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to get stats")
+			}
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response:   responseModel.OK(),
+			ClickStats: stats,
+		})
+	}
+}
+
+func parseQuery(r *http.Request, now time.Time) (from, to time.Time, bucket string, err error) {
+	q := r.URL.Query()
+
+	to = now
+	if raw := q.Get("to"); raw != "" {
+		if to, err = time.Parse(time.RFC3339, raw); err != nil {
+			return from, to, bucket, errors.New("field to is not in RFC 3339 format")
+		}
+	}
+	from = to.Add(-defaultRange)
+	if raw := q.Get("from"); raw != "" {
+		if from, err = time.Parse(time.RFC3339, raw); err != nil {
+			return from, to, bucket, errors.New("field from is not in RFC 3339 format")
+		}
+	}
+	if !from.Before(to) {
+		return from, to, bucket, errors.New("field from must be before to")
+	}
+
+	bucket = q.Get("bucket")
+	switch bucket {
+	case "":
+		bucket = domain.BucketDay
+	case domain.BucketDay:
+	case domain.BucketHour:
+		if to.Sub(from) > maxHourlyRange {
+			return from, to, bucket, errors.New("hour buckets are limited to 31 days")
+		}
+	default:
+		return from, to, bucket, errors.New("field bucket must be hour or day")
+	}
+
+	return from, to, bucket, nil
+}
@@ -0,0 +1,144 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/url/stats"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey authenticates the requests, links are looked up in its workspace
+var testKey = domain.APIKey{ID: 1, WorkspaceID: 3, Scopes: []string{domain.ScopeAdmin}}
+
+func TestStatsHandler(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	uniqueVisitors := 2
+
+	cases := []struct {
+		name      string
+		query     string
+		bucket    string
+		stats     domain.ClickStats
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:   "Success",
+			query:  "?from=2025-01-01T00:00:00Z&to=2025-01-03T00:00:00Z",
+			bucket: domain.BucketDay,
+			stats: domain.ClickStats{
+				Alias:          "abc",
+				Total:          3,
+				UniqueVisitors: &uniqueVisitors,
+				Buckets: []domain.ClickBucket{
+					{Start: from, Clicks: 2, UniqueVisitors: 1},
+					{Start: from.Add(24 * time.Hour), Clicks: 1, UniqueVisitors: 1},
+				},
+			},
+		},
+		{
+			name:   "Hour buckets",
+			query:  "?from=2025-01-01T00:00:00Z&to=2025-01-03T00:00:00Z&bucket=hour",
+			bucket: domain.BucketHour,
+			stats:  domain.ClickStats{Alias: "abc"},
+		},
+		{
+			name:      "Invalid from",
+			query:     "?from=yesterday",
+			respCode:  http.StatusBadRequest,
+			respError: "field from is not in RFC 3339 format",
+		},
+		{
+			name:      "From after to",
+			query:     "?from=2025-01-03T00:00:00Z&to=2025-01-01T00:00:00Z",
+			respCode:  http.StatusBadRequest,
+			respError: "field from must be before to",
+		},
+		{
+			name:      "Invalid bucket",
+			query:     "?bucket=week",
+			respCode:  http.StatusBadRequest,
+			respError: "field bucket must be hour or day",
+		},
+		{
+			name:      "Hour buckets too long range",
+			query:     "?from=2024-01-01T00:00:00Z&to=2025-01-01T00:00:00Z&bucket=hour",
+			respCode:  http.StatusBadRequest,
+			respError: "hour buckets are limited to 31 days",
+		},
+		{
+			name:      "Unknown alias",
+			query:     "?from=2025-01-01T00:00:00Z&to=2025-01-03T00:00:00Z",
+			bucket:    domain.BucketDay,
+			respCode:  http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrURLNotFound,
+		},
+		{
+			name:      "Storage error",
+			query:     "?from=2025-01-01T00:00:00Z&to=2025-01-03T00:00:00Z",
+			bucket:    domain.BucketDay,
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to get stats",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			statsGetterMock := stats.NewMockStatsGetter(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				if errors.Is(tc.mockError, storage.ErrURLNotFound) {
+					statsGetterMock.On("GetLink", domain.LinkRef{WorkspaceID: testKey.WorkspaceID, Alias: "abc"}).Return(domain.Link{}, tc.mockError).Once()
+				} else {
+					statsGetterMock.On("GetLink", domain.LinkRef{WorkspaceID: testKey.WorkspaceID, Alias: "abc"}).Return(domain.Link{ID: 11, Alias: "abc"}, nil).Once()
+					statsGetterMock.On("GetClickStats", int64(11), mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), tc.bucket).
+						Return(tc.stats, tc.mockError).Once()
+				}
+			}
+
+			//here using chi becouse there is URL param {alias}
+			r := chi.NewRouter()
+			r.Get("/url/{alias}/stats", stats.New(silentlog.NewSilentLogger(), statsGetterMock))
+
+			req, err := http.NewRequest(http.MethodGet, "/url/abc/stats"+tc.query, nil)
+			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp stats.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.stats.Total, resp.Total)
+			require.Equal(t, tc.stats.UniqueVisitors, resp.UniqueVisitors)
+			require.Len(t, resp.Buckets, len(tc.stats.Buckets))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package update
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/expiration"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// Request is a partial update, omitted fields are left unchanged
+type Request struct {
+	URL *string `json:"url,omitempty" validate:"omitempty,url"`
+	// absolute expiration time, mutually exclusive with TTL and NeverExpires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// link lifetime from now as a Go duration, e.g. "72h"
+	TTL string `json:"ttl,omitempty"`
+	// removes the expiration of the link
+	NeverExpires bool  `json:"never_expires,omitempty"`
+	Disabled     *bool `json:"disabled,omitempty"`
+}
+
+type Response struct {
+	responseModel.Response
+	domain.Link
+}
+
+//go:generate mockery --name=LinkUpdater
+type LinkUpdater interface {
+	UpdateLink(ref domain.LinkRef, update domain.LinkUpdate) (domain.Link, error)
+}
+
+// New changes the destination, expiration or disabled flag of the link.
+func New(log *slog.Logger, linkUpdater LinkUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.update.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := mwAuth.WorkspaceID(r.Context())
+		if !ok {
+			log.Error("request isn't authenticated")
+			mwAuth.Unauthorized(w, r)
+			return
+		}
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias url param is empty")
+			responseModel.RenderError(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		//links of custom domains are selected by the domain query param
+		ref := domain.LinkRef{
+			WorkspaceID: workspaceID,
+			Domain:      strings.ToLower(r.URL.Query().Get("domain")),
+			Alias:       alias,
+		}
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("can't decode request body", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "can't decode request body")
+			return
+		}
+		log.Info("request body decoded", slog.Any("request", req))
+
+		update, err := req.Update(time.Now())
+		var validErrs validator.ValidationErrors
+		if errors.As(err, &validErrs) {
+			log.Error("invalid request body", sl.Err(err))
+
+			responseModel.RenderValidationError(w, r, validErrs)
+			return
+		}
+		if err != nil {
+			log.Info("invalid update", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		link, err := linkUpdater.UpdateLink(ref, update)
+		if err != nil {
+			if errors.Is(err, storage.ErrURLNotFound) {
+				log.Info("url not found", slog.String("alias", alias))
+				responseModel.RenderError(w, r, http.StatusNotFound, "url not found")
+			} else {
+				log.Error("failed to update url", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to update url")
+			}
+			return
+		}
+
+		log.Info("url updated", slog.String("alias", alias))
+		render.JSON(w, r, Response{
+			Response: responseModel.OK(),
+			Link:     link,
+		})
+	}
+}
+
+// Update validates the request and builds the update of the link. Invalid requests get
+// validator.ValidationErrors or an error whose message is meant for the client.
+func (req Request) Update(now time.Time) (domain.LinkUpdate, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return domain.LinkUpdate{}, err
+	}
+	if req.URL == nil && req.ExpiresAt == nil && req.TTL == "" && !req.NeverExpires && req.Disabled == nil {
+		return domain.LinkUpdate{}, errors.New("nothing to update")
+	}
+	if req.NeverExpires && (req.ExpiresAt != nil || req.TTL != "") {
+		return domain.LinkUpdate{}, errors.New("never_expires can't be combined with expires_at or ttl")
+	}
+
+	expiresAt, err := expiration.Resolve(req.ExpiresAt, req.TTL, now)
+	if err != nil {
+		return domain.LinkUpdate{}, err
+	}
+
+	return domain.LinkUpdate{
+		URL:          req.URL,
+		ExpiresAt:    expiresAt,
+		NeverExpires: req.NeverExpires,
+		Disabled:     req.Disabled,
+	}, nil
+}
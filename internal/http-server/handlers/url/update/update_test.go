@@ -0,0 +1,132 @@
+package update_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/url/update"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey authenticates the requests, links are looked up in its workspace
+var testKey = domain.APIKey{ID: 1, WorkspaceID: 3, Scopes: []string{domain.ScopeAdmin}}
+
+func TestUpdateHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		check     func(u domain.LinkUpdate) bool
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name: "Change url",
+			body: `{"url": "https://example.org"}`,
+			check: func(u domain.LinkUpdate) bool {
+				return u.URL != nil && *u.URL == "https://example.org" && u.ExpiresAt == nil && u.Disabled == nil
+			},
+		},
+		{
+			name: "TTL and disable",
+			body: `{"ttl": "1h", "disabled": true}`,
+			check: func(u domain.LinkUpdate) bool {
+				return u.URL == nil && u.ExpiresAt != nil && time.Until(*u.ExpiresAt) > 59*time.Minute &&
+					u.Disabled != nil && *u.Disabled
+			},
+		},
+		{
+			name: "Never expires",
+			body: `{"never_expires": true}`,
+			check: func(u domain.LinkUpdate) bool {
+				return u.NeverExpires && u.ExpiresAt == nil
+			},
+		},
+		{
+			name:      "Empty update",
+			body:      `{}`,
+			respCode:  http.StatusBadRequest,
+			respError: "nothing to update",
+		},
+		{
+			name:      "Invalid url",
+			body:      `{"url": "not a url"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid body",
+		},
+		{
+			name:      "Never expires with ttl",
+			body:      `{"never_expires": true, "ttl": "1h"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "never_expires can't be combined with expires_at or ttl",
+		},
+		{
+			name:      "Not found",
+			body:      `{"url": "https://example.org"}`,
+			respCode:  http.StatusNotFound,
+			respError: "url not found",
+			mockError: storage.ErrURLNotFound,
+		},
+		{
+			name:      "Storage error",
+			body:      `{"url": "https://example.org"}`,
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to update url",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			linkUpdaterMock := update.NewMockLinkUpdater(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				check := tc.check
+				if check == nil {
+					check = func(domain.LinkUpdate) bool { return true }
+				}
+				linkUpdaterMock.On("UpdateLink", domain.LinkRef{WorkspaceID: testKey.WorkspaceID, Alias: "abc"}, mock.MatchedBy(check)).
+					Return(domain.Link{Alias: "abc", URL: "https://example.org"}, tc.mockError).Once()
+			}
+
+			//here using chi becouse there is URL param {alias}
+			r := chi.NewRouter()
+			r.Patch("/url/{alias}", update.New(silentlog.NewSilentLogger(), linkUpdaterMock))
+
+			req, err := http.NewRequest(http.MethodPatch, "/url/abc", bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), testKey))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp update.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, "abc", resp.Alias)
+		})
+	}
+}
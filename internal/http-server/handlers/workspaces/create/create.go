@@ -0,0 +1,90 @@
+package create
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Request struct {
+	Name string `json:"name" validate:"required"`
+	Slug string `json:"slug" validate:"required,alphanum,lowercase,max=32"`
+	// links the workspace may create per month, unlimited if omitted
+	MonthlyLinkQuota *int64 `json:"monthly_link_quota,omitempty" validate:"omitempty,min=0"`
+}
+
+type Response struct {
+	responseModel.Response
+	domain.Workspace
+}
+
+//go:generate mockery --name=WorkspaceCreator
+type WorkspaceCreator interface {
+	CreateWorkspace(ws domain.Workspace) (int64, error)
+}
+
+// New creates a workspace, its members are added by creating api keys with its workspace_id
+// and its custom domains by its members.
+func New(log *slog.Logger, creator WorkspaceCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.workspaces.create.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("can't decode request body", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "can't decode request body")
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validErrs := err.(validator.ValidationErrors)
+
+			log.Error("invalid request body", sl.Err(err))
+
+			responseModel.RenderValidationError(w, r, validErrs)
+			return
+		}
+
+		ws := domain.Workspace{
+			Name:             req.Name,
+			Slug:             req.Slug,
+			MonthlyLinkQuota: req.MonthlyLinkQuota,
+			CreatedAt:        time.Now().UTC(),
+		}
+
+		ws.ID, err = creator.CreateWorkspace(ws)
+		if errors.Is(err, storage.ErrWorkspaceExists) {
+			log.Info("workspace already exists", slog.String("slug", req.Slug))
+			responseModel.RenderError(w, r, http.StatusConflict, "workspace slug already exists")
+			return
+		}
+		if err != nil {
+			log.Error("failed to create workspace", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to create workspace")
+			return
+		}
+		log.Info("workspace created", slog.Int64("id", ws.ID), slog.String("slug", ws.Slug))
+
+		responseModel.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{
+			Response:  responseModel.OK(),
+			Workspace: ws,
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package create_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/workspaces/create"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"testing"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name: "Success",
+			body: `{"name": "Team B", "slug": "teamb"}`,
+		},
+		{
+			name:      "Invalid slug",
+			body:      `{"name": "Team B", "slug": "team/b"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid body",
+		},
+		{
+			name: "Quota",
+			body: `{"name": "Team B", "slug": "teamb", "monthly_link_quota": 100}`,
+		},
+		{
+			name:      "Negative quota",
+			body:      `{"name": "Team B", "slug": "teamb", "monthly_link_quota": -1}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid body",
+		},
+		{
+			name:      "Exists",
+			body:      `{"name": "Team B", "slug": "teamb"}`,
+			respCode:  http.StatusConflict,
+			respError: "workspace slug already exists",
+			mockError: storage.ErrWorkspaceExists,
+		},
+		{
+			name:      "Storage error",
+			body:      `{"name": "Team B", "slug": "teamb"}`,
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to create workspace",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			creatorMock := create.NewMockWorkspaceCreator(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				creatorMock.On("CreateWorkspace", mock.MatchedBy(func(ws domain.Workspace) bool {
+					return ws.Slug == "teamb" && ws.Name == "Team B"
+				})).Return(int64(2), tc.mockError).Once()
+			}
+
+			handler := create.New(silentlog.NewSilentLogger(), creatorMock)
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/workspaces", bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+			require.Equal(t, http.StatusCreated, rr.Code)
+
+			var resp create.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, responseModel.StatusOK, resp.Status)
+			require.Equal(t, int64(2), resp.ID)
+		})
+	}
+}
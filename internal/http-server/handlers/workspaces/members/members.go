@@ -0,0 +1,64 @@
+package members
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	responseModel.Response
+	Members []domain.APIKey `json:"members"`
+}
+
+//go:generate mockery --name=MemberLister
+type MemberLister interface {
+	ListAPIKeys(workspaceID int64) ([]domain.APIKey, error)
+}
+
+// New lists the api keys of the workspace with the {id} url param,
+// admins of other workspaces than the default one only see their own.
+func New(log *slog.Logger, lister MemberLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.workspaces.members.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Info("invalid workspace id", slog.String("id", chi.URLParam(r, "id")))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		if !mwAuth.CanManage(r.Context(), id) {
+			log.Info("workspace of another api key", slog.Int64("id", id))
+			responseModel.RenderError(w, r, http.StatusNotFound, "workspace not found")
+			return
+		}
+
+		keys, err := lister.ListAPIKeys(id)
+		if err != nil {
+			log.Error("failed to list api keys", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to list members")
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response: responseModel.OK(),
+			Members:  keys,
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package members_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/workspaces/members"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMembersHandler(t *testing.T) {
+	workspaceAdmin := domain.APIKey{ID: 3, WorkspaceID: 2, Scopes: []string{domain.ScopeAdmin}}
+
+	cases := []struct {
+		name string
+		id   string
+		//key of the request, the root key if empty
+		apiKey      domain.APIKey
+		workspaceID int64
+		keys        []domain.APIKey
+		respCode    int
+		respError   string
+		mockError   error
+	}{
+		{
+			name:        "Success",
+			id:          "2",
+			workspaceID: 2,
+			keys:        []domain.APIKey{{ID: 5, WorkspaceID: 2, Name: "ci", Scopes: []string{domain.ScopeLinksWrite}}},
+		},
+		{
+			name:        "Workspace admin lists its workspace",
+			id:          "2",
+			apiKey:      workspaceAdmin,
+			workspaceID: 2,
+			keys:        []domain.APIKey{{ID: 5, WorkspaceID: 2, Name: "ci", Scopes: []string{domain.ScopeLinksWrite}}},
+		},
+		{
+			name:      "Workspace admin lists another workspace",
+			id:        "1",
+			apiKey:    workspaceAdmin,
+			respCode:  http.StatusNotFound,
+			respError: "workspace not found",
+		},
+		{
+			name:      "Invalid id",
+			id:        "abc",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid request",
+		},
+		{
+			name:        "Storage error",
+			id:          "2",
+			workspaceID: 2,
+			respCode:    http.StatusInternalServerError,
+			respError:   "failed to list members",
+			mockError:   errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			listerMock := members.NewMockMemberLister(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				listerMock.On("ListAPIKeys", tc.workspaceID).Return(tc.keys, tc.mockError).Once()
+			}
+
+			//here using chi becouse there is URL param {id}
+			r := chi.NewRouter()
+			r.Get("/admin/workspaces/{id}/members", members.New(silentlog.NewSilentLogger(), listerMock))
+
+			req, err := http.NewRequest(http.MethodGet, "/admin/workspaces/"+tc.id+"/members", nil)
+			require.NoError(t, err)
+			apiKey := tc.apiKey
+			if apiKey.WorkspaceID == 0 {
+				apiKey = mwAuth.RootKey
+			}
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), apiKey))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+
+			var resp members.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, responseModel.StatusOK, resp.Status)
+			require.Len(t, resp.Members, 1)
+			require.Equal(t, "ci", resp.Members[0].Name)
+		})
+	}
+}
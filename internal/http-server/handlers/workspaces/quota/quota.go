@@ -0,0 +1,100 @@
+package quota
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Request struct {
+	// links the workspace may create per month, null removes the quota
+	MonthlyLinkQuota *int64 `json:"monthly_link_quota" validate:"omitempty,min=0"`
+}
+
+type Response struct {
+	responseModel.Response
+	MonthlyLinkQuota *int64 `json:"monthly_link_quota"`
+	// links created in the current month
+	Usage domain.WorkspaceUsage `json:"usage"`
+}
+
+//go:generate mockery --name=QuotaSetter
+type QuotaSetter interface {
+	SetWorkspaceQuota(id int64, quota *int64) error
+	GetWorkspaceUsage(workspaceID int64, period string) (domain.WorkspaceUsage, error)
+}
+
+// New sets the monthly link quota of the workspace with the {id} url param,
+// links over the quota are rejected until the next month.
+func New(log *slog.Logger, setter QuotaSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.workspaces.quota.new"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Info("invalid workspace id", slog.String("id", chi.URLParam(r, "id")))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		var req Request
+
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			log.Error("can't decode request body", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusBadRequest, "can't decode request body")
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			validErrs := err.(validator.ValidationErrors)
+
+			log.Error("invalid request body", sl.Err(err))
+
+			responseModel.RenderValidationError(w, r, validErrs)
+			return
+		}
+
+		err = setter.SetWorkspaceQuota(id, req.MonthlyLinkQuota)
+		if errors.Is(err, storage.ErrWorkspaceNotFound) {
+			log.Info("workspace not found", slog.Int64("id", id))
+			responseModel.RenderError(w, r, http.StatusNotFound, "workspace not found")
+			return
+		}
+		if err != nil {
+			log.Error("failed to set quota", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to set quota")
+			return
+		}
+		log.Info("quota set", slog.Int64("id", id))
+
+		usage, err := setter.GetWorkspaceUsage(id, domain.UsagePeriod(time.Now()))
+		if err != nil {
+			log.Error("failed to get usage", sl.Err(err))
+			responseModel.RenderError(w, r, http.StatusInternalServerError, "failed to get usage")
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response:         responseModel.OK(),
+			MonthlyLinkQuota: req.MonthlyLinkQuota,
+			Usage:            usage,
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package quota_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/handlers/workspaces/quota"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaHandler(t *testing.T) {
+	limit := int64(100)
+
+	cases := []struct {
+		name      string
+		id        string
+		body      string
+		quota     *int64
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:  "Success",
+			id:    "2",
+			body:  `{"monthly_link_quota": 100}`,
+			quota: &limit,
+		},
+		{
+			name: "Unlimited",
+			id:   "2",
+			body: `{"monthly_link_quota": null}`,
+		},
+		{
+			name:      "Invalid id",
+			id:        "abc",
+			body:      `{"monthly_link_quota": 100}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid request",
+		},
+		{
+			name:      "Negative quota",
+			id:        "2",
+			body:      `{"monthly_link_quota": -1}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid body",
+		},
+		{
+			name:      "Not found",
+			id:        "2",
+			body:      `{"monthly_link_quota": 100}`,
+			quota:     &limit,
+			respCode:  http.StatusNotFound,
+			respError: "workspace not found",
+			mockError: storage.ErrWorkspaceNotFound,
+		},
+		{
+			name:      "Storage error",
+			id:        "2",
+			body:      `{"monthly_link_quota": 100}`,
+			quota:     &limit,
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to set quota",
+			mockError: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			setterMock := quota.NewMockQuotaSetter(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				setterMock.On("SetWorkspaceQuota", int64(2), tc.quota).Return(tc.mockError).Once()
+			}
+			if tc.respError == "" {
+				setterMock.On("GetWorkspaceUsage", int64(2), mock.Anything).
+					Return(domain.WorkspaceUsage{WorkspaceID: 2, Period: "2025-01", LinksCreated: 7}, nil).Once()
+			}
+
+			//here using chi becouse there is URL param {id}
+			r := chi.NewRouter()
+			r.Put("/admin/workspaces/{id}/quota", quota.New(silentlog.NewSilentLogger(), setterMock))
+
+			req, err := http.NewRequest(http.MethodPut, "/admin/workspaces/"+tc.id+"/quota", bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tc.respError != "" {
+				require.Equal(t, tc.respCode, rr.Code)
+
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var resp quota.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, responseModel.StatusOK, resp.Status)
+			require.Equal(t, tc.quota, resp.MonthlyLinkQuota)
+			require.Equal(t, int64(7), resp.Usage.LinksCreated)
+		})
+	}
+}
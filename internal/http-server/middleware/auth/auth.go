@@ -0,0 +1,169 @@
+package mwAuth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/apikey"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+//go:generate mockery --name=APIKeyGetter
+type APIKeyGetter interface {
+	GetAPIKeyByHash(keyHash string) (domain.APIKey, error)
+}
+
+// RootKey is used for requests authenticated with the configured basic auth credentials,
+// so the first API keys can be created. It has no id, links saved with it have no api key.
+var RootKey = domain.APIKey{WorkspaceID: domain.DefaultWorkspaceID, Name: "root", Scopes: []string{domain.ScopeAdmin}}
+
+type keyCtx struct{}
+
+func WithAPIKey(ctx context.Context, key domain.APIKey) context.Context {
+	return context.WithValue(ctx, keyCtx{}, key)
+}
+
+// APIKeyFromContext returns the key the request was authenticated with
+func APIKeyFromContext(ctx context.Context) (domain.APIKey, bool) {
+	key, ok := ctx.Value(keyCtx{}).(domain.APIKey)
+	return key, ok
+}
+
+// WorkspaceID returns the workspace of the key the request was authenticated with,
+// handlers only see and change the links of this workspace
+func WorkspaceID(ctx context.Context) (int64, bool) {
+	key, ok := APIKeyFromContext(ctx)
+	return key.WorkspaceID, ok && key.WorkspaceID != 0
+}
+
+// New authenticates requests with an "Authorization: Bearer <key>" header.
+// Basic auth with the root user and password is accepted as the admin RootKey.
+func New(log *slog.Logger, keys APIKeyGetter, rootUser, rootPassword string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/auth"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := log.With(slog.String("request_id", middleware.GetReqID(r.Context())))
+
+			var key domain.APIKey
+			if user, password, ok := r.BasicAuth(); ok {
+				if rootUser == "" ||
+					subtle.ConstantTimeCompare([]byte(user), []byte(rootUser)) != 1 ||
+					subtle.ConstantTimeCompare([]byte(password), []byte(rootPassword)) != 1 {
+					log.Info("invalid basic auth credentials")
+					Unauthorized(w, r)
+					return
+				}
+				key = RootKey
+			} else {
+				token, ok := bearerToken(r)
+				if !ok {
+					log.Info("api key is missing")
+					Unauthorized(w, r)
+					return
+				}
+
+				var err error
+				key, err = keys.GetAPIKeyByHash(apikey.Hash(token))
+				if err != nil {
+					if errors.Is(err, storage.ErrAPIKeyNotFound) {
+						log.Info("unknown or revoked api key")
+						Unauthorized(w, r)
+					} else {
+						log.Error("failed to get api key", sl.Err(err))
+						responseModel.RenderError(w, r, http.StatusInternalServerError, "internal error")
+					}
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithAPIKey(r.Context(), key)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RequireScope rejects requests whose api key lacks the scope, it must be used after New.
+func RequireScope(scope string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := APIKeyFromContext(r.Context())
+			if !ok {
+				Unauthorized(w, r)
+				return
+			}
+			if !key.HasScope(scope) {
+				responseModel.RenderError(w, r, http.StatusForbidden, "api key lacks the "+scope+" scope")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IsGlobalAdmin reports whether the key administers the whole service: the root key and the admin keys
+// of the default workspace. Admin keys of other workspaces only administer their own workspace.
+func IsGlobalAdmin(key domain.APIKey) bool {
+	return key.WorkspaceID == domain.DefaultWorkspaceID && key.HasScope(domain.ScopeAdmin)
+}
+
+// RequireGlobalAdmin rejects requests whose api key isn't a global admin, it must be used after New.
+func RequireGlobalAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := APIKeyFromContext(r.Context())
+		if !ok {
+			Unauthorized(w, r)
+			return
+		}
+		if !IsGlobalAdmin(key) {
+			responseModel.RenderError(w, r, http.StatusForbidden, "api key isn't a global admin")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ManagedWorkspace returns the workspace whose keys the request may manage: the workspace of its key,
+// or storage.AnyWorkspace for global admins
+func ManagedWorkspace(ctx context.Context) (int64, bool) {
+	key, ok := APIKeyFromContext(ctx)
+	if !ok || key.WorkspaceID == 0 {
+		return 0, false
+	}
+	if IsGlobalAdmin(key) {
+		return storage.AnyWorkspace, true
+	}
+	return key.WorkspaceID, true
+}
+
+// CanManage reports whether the request may manage the workspace, see ManagedWorkspace
+func CanManage(ctx context.Context, workspaceID int64) bool {
+	managed, ok := ManagedWorkspace(ctx)
+	return ok && (managed == storage.AnyWorkspace || managed == workspaceID)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Unauthorized answers 401 with a Bearer challenge
+func Unauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="short-url"`)
+	responseModel.RenderError(w, r, http.StatusUnauthorized, "invalid or missing api key")
+}
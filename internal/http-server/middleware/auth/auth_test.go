@@ -0,0 +1,197 @@
+package mwAuth_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/apikey"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth(t *testing.T) {
+	const token = "sk_test"
+	readKey := domain.APIKey{ID: 7, Name: "reader", Scopes: []string{domain.ScopeLinksRead}}
+
+	cases := []struct {
+		name          string
+		authorization string
+		user          string
+		password      string
+		scope         string
+		key           domain.APIKey
+		mockError     error
+		// lookup is false when the storage must not be called
+		lookup    bool
+		respCode  int
+		respError string
+		keyID     int64
+	}{
+		{
+			name:          "Success",
+			authorization: "Bearer " + token,
+			scope:         domain.ScopeLinksRead,
+			key:           readKey,
+			lookup:        true,
+			respCode:      http.StatusOK,
+			keyID:         7,
+		},
+		{
+			name:          "Lowercase scheme",
+			authorization: "bearer " + token,
+			scope:         domain.ScopeLinksRead,
+			key:           readKey,
+			lookup:        true,
+			respCode:      http.StatusOK,
+			keyID:         7,
+		},
+		{
+			name:      "Missing key",
+			scope:     domain.ScopeLinksRead,
+			respCode:  http.StatusUnauthorized,
+			respError: "invalid or missing api key",
+		},
+		{
+			name:          "Unknown key",
+			authorization: "Bearer " + token,
+			scope:         domain.ScopeLinksRead,
+			mockError:     storage.ErrAPIKeyNotFound,
+			lookup:        true,
+			respCode:      http.StatusUnauthorized,
+			respError:     "invalid or missing api key",
+		},
+		{
+			name:          "Storage error",
+			authorization: "Bearer " + token,
+			scope:         domain.ScopeLinksRead,
+			mockError:     errors.New("unexpected error"),
+			lookup:        true,
+			respCode:      http.StatusInternalServerError,
+			respError:     "internal error",
+		},
+		{
+			name:          "Missing scope",
+			authorization: "Bearer " + token,
+			scope:         domain.ScopeLinksWrite,
+			key:           readKey,
+			lookup:        true,
+			respCode:      http.StatusForbidden,
+			respError:     "api key lacks the links:write scope",
+		},
+		{
+			name:     "Root basic auth",
+			user:     "admin",
+			password: "secret",
+			scope:    domain.ScopeAdmin,
+			respCode: http.StatusOK,
+		},
+		{
+			name:      "Wrong basic auth",
+			user:      "admin",
+			password:  "wrong",
+			scope:     domain.ScopeAdmin,
+			respCode:  http.StatusUnauthorized,
+			respError: "invalid or missing api key",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			keyGetterMock := mwAuth.NewMockAPIKeyGetter(t)
+			if tc.lookup {
+				keyGetterMock.On("GetAPIKeyByHash", apikey.Hash(token)).Return(tc.key, tc.mockError).Once()
+			}
+
+			var gotKey domain.APIKey
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotKey, _ = mwAuth.APIKeyFromContext(r.Context())
+			})
+			handler := mwAuth.New(silentlog.NewSilentLogger(), keyGetterMock, "admin", "secret")(
+				mwAuth.RequireScope(tc.scope)(next))
+
+			req, err := http.NewRequest(http.MethodGet, "/url", nil)
+			require.NoError(t, err)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			if tc.user != "" {
+				req.SetBasicAuth(tc.user, tc.password)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respCode, rr.Code)
+			if tc.respError != "" {
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				if tc.respCode == http.StatusUnauthorized {
+					require.NotEmpty(t, rr.Header().Get("WWW-Authenticate"))
+				}
+				return
+			}
+			require.Equal(t, tc.keyID, gotKey.ID)
+		})
+	}
+}
+
+func TestRequireGlobalAdmin(t *testing.T) {
+	cases := []struct {
+		name     string
+		key      domain.APIKey
+		respCode int
+		managed  int64
+	}{
+		{name: "Root key", key: mwAuth.RootKey, respCode: http.StatusOK, managed: storage.AnyWorkspace},
+		{
+			name:     "Default workspace admin",
+			key:      domain.APIKey{ID: 1, WorkspaceID: domain.DefaultWorkspaceID, Scopes: []string{domain.ScopeAdmin}},
+			respCode: http.StatusOK,
+			managed:  storage.AnyWorkspace,
+		},
+		{
+			name:     "Workspace admin",
+			key:      domain.APIKey{ID: 2, WorkspaceID: 7, Scopes: []string{domain.ScopeAdmin}},
+			respCode: http.StatusForbidden,
+			managed:  7,
+		},
+		{
+			name:     "Default workspace writer",
+			key:      domain.APIKey{ID: 3, WorkspaceID: domain.DefaultWorkspaceID, Scopes: []string{domain.ScopeLinksWrite}},
+			respCode: http.StatusForbidden,
+			managed:  domain.DefaultWorkspaceID,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			handler := mwAuth.RequireGlobalAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), tc.key))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respCode, rr.Code)
+			managed, ok := mwAuth.ManagedWorkspace(req.Context())
+			require.True(t, ok)
+			require.Equal(t, tc.managed, managed)
+			require.True(t, mwAuth.CanManage(req.Context(), tc.key.WorkspaceID))
+			require.Equal(t, tc.managed == storage.AnyWorkspace, mwAuth.CanManage(req.Context(), 99))
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package mwIdempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/sl"
+	"short-url/internal/storage"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	// MaxKeyLength is the longest key accepted, clients usually send UUIDs
+	MaxKeyLength = 255
+)
+
+//go:generate mockery --name=KeyStore
+type KeyStore interface {
+	CreateIdempotencyKey(key domain.IdempotencyKey) error
+	GetIdempotencyKey(scope, key string) (domain.IdempotencyKey, error)
+	CompleteIdempotencyKey(scope, key string, status int, contentType string, body []byte) error
+	DeleteIdempotencyKey(scope, key string) error
+}
+
+// New makes the requests with an Idempotency-Key header safe to retry: the response of the first request
+// is stored for the window and replayed to the retries with the Idempotent-Replayed header.
+// A key reused with another method, path or body gets 422, a retry of a request in progress gets 409.
+// Server errors and 429 aren't stored, the key is released so the request may be retried.
+// Keys are scoped by the API key, requests without a key (or with the root key, shared by every admin) by client ip.
+// Bodies are read into memory to hash them, ones longer than maxBodyBytes get 413.
+func New(log *slog.Logger, store KeyStore, window time.Duration, maxBodyBytes int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if window <= 0 {
+			return next
+		}
+
+		log := log.With(
+			slog.String("component", "middleware/idempotency"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderKey)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log := log.With(slog.String("request_id", middleware.GetReqID(r.Context())))
+
+			if len(key) > MaxKeyLength {
+				responseModel.RenderError(w, r, http.StatusBadRequest,
+					"idempotency key is longer than "+strconv.Itoa(MaxKeyLength)+" characters")
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				log.Info("request body is too large", slog.Int64("limit", maxBodyBytes))
+				responseModel.RenderError(w, r, http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("request body with an idempotency key is longer than %d bytes", maxBodyBytes))
+				return
+			}
+			if err != nil {
+				log.Error("failed to read request body", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusBadRequest, "failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scope := keyScope(r)
+			now := time.Now()
+			record := domain.IdempotencyKey{
+				Scope:       scope,
+				Key:         key,
+				RequestHash: requestHash(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(window),
+			}
+
+			err = store.CreateIdempotencyKey(record)
+			if errors.Is(err, storage.ErrIdempotencyKeyExists) {
+				replay(log, store, w, r, record)
+				return
+			}
+			if err != nil {
+				log.Error("failed to save idempotency key", sl.Err(err))
+				responseModel.RenderError(w, r, http.StatusInternalServerError, "internal error")
+				return
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			var resp bytes.Buffer
+			ww.Tee(&resp)
+
+			completed := false
+			defer func() {
+				//the handler panicked or failed, the request may be retried with the key
+				if !completed {
+					if err := store.DeleteIdempotencyKey(scope, key); err != nil {
+						log.Error("failed to release idempotency key", sl.Err(err))
+					}
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+				return
+			}
+
+			err = store.CompleteIdempotencyKey(scope, key, status, ww.Header().Get("Content-Type"), resp.Bytes())
+			if err != nil {
+				log.Error("failed to save idempotent response", sl.Err(err))
+				return
+			}
+			completed = true
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// replay answers a retry with the stored response of the key
+func replay(log *slog.Logger, store KeyStore, w http.ResponseWriter, r *http.Request, record domain.IdempotencyKey) {
+	stored, err := store.GetIdempotencyKey(record.Scope, record.Key)
+	if errors.Is(err, storage.ErrIdempotencyKeyNotFound) {
+		//released by the first request after it failed
+		responseModel.RenderError(w, r, http.StatusConflict, "request with the idempotency key is in progress")
+		return
+	}
+	if err != nil {
+		log.Error("failed to get idempotency key", sl.Err(err))
+		responseModel.RenderError(w, r, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	if stored.RequestHash != record.RequestHash {
+		responseModel.RenderError(w, r, http.StatusUnprocessableEntity, "idempotency key was used with a different request")
+		return
+	}
+	if !stored.Completed() {
+		responseModel.RenderError(w, r, http.StatusConflict, "request with the idempotency key is in progress")
+		return
+	}
+
+	if stored.ContentType != "" {
+		w.Header().Set("Content-Type", stored.ContentType)
+	}
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(stored.Status)
+	_, _ = w.Write(stored.Body)
+}
+
+// requestHash identifies the request sent with a key: its method, path and body
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + "\n" + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func keyScope(r *http.Request) string {
+	if key, ok := mwAuth.APIKeyFromContext(r.Context()); ok && key.ID != 0 {
+		return "key:" + strconv.FormatInt(key.ID, 10)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
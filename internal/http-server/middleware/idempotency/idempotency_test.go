@@ -0,0 +1,253 @@
+package mwIdempotency_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	mwIdempotency "short-url/internal/http-server/middleware/idempotency"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/render"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const reqBody = `{"url":"https://example.com"}`
+
+func hash(body string) string {
+	sum := sha256.Sum256([]byte("POST\n/url\n" + body))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestIdempotency(t *testing.T) {
+	cases := []struct {
+		name          string
+		handlerStatus int
+		createError   error
+		stored        *domain.IdempotencyKey
+		complete      bool
+		release       bool
+		respCode      int
+		respBody      string
+		replayed      bool
+		respError     string
+	}{
+		{
+			name:          "First request",
+			handlerStatus: http.StatusCreated,
+			complete:      true,
+			respCode:      http.StatusCreated,
+			respBody:      `{"alias":"new"}`,
+		},
+		{
+			name:          "Client error is stored",
+			handlerStatus: http.StatusConflict,
+			complete:      true,
+			respCode:      http.StatusConflict,
+			respBody:      `{"alias":"new"}`,
+		},
+		{
+			name:          "Server error releases the key",
+			handlerStatus: http.StatusInternalServerError,
+			release:       true,
+			respCode:      http.StatusInternalServerError,
+			respBody:      `{"alias":"new"}`,
+		},
+		{
+			name:        "Replay",
+			createError: storage.ErrIdempotencyKeyExists,
+			stored: &domain.IdempotencyKey{
+				RequestHash: hash(reqBody), Status: http.StatusCreated,
+				ContentType: "application/json", Body: []byte(`{"alias":"old"}`),
+			},
+			respCode: http.StatusCreated,
+			respBody: `{"alias":"old"}`,
+			replayed: true,
+		},
+		{
+			name:        "Different request",
+			createError: storage.ErrIdempotencyKeyExists,
+			stored:      &domain.IdempotencyKey{RequestHash: hash(`{"url":"https://other.com"}`), Status: http.StatusCreated},
+			respCode:    http.StatusUnprocessableEntity,
+			respError:   "idempotency key was used with a different request",
+		},
+		{
+			name:        "In progress",
+			createError: storage.ErrIdempotencyKeyExists,
+			stored:      &domain.IdempotencyKey{RequestHash: hash(reqBody)},
+			respCode:    http.StatusConflict,
+			respError:   "request with the idempotency key is in progress",
+		},
+		{
+			name:        "Store error",
+			createError: errors.New("unexpected error"),
+			respCode:    http.StatusInternalServerError,
+			respError:   "internal error",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			storeMock := mwIdempotency.NewMockKeyStore(t)
+			storeMock.On("CreateIdempotencyKey", mock.MatchedBy(func(k domain.IdempotencyKey) bool {
+				return k.Scope == "key:7" && k.Key == "k1" && k.RequestHash == hash(reqBody) &&
+					k.ExpiresAt.Sub(k.CreatedAt) == time.Hour
+			})).Return(tc.createError).Once()
+			if tc.stored != nil {
+				storeMock.On("GetIdempotencyKey", "key:7", "k1").Return(*tc.stored, nil).Once()
+			}
+			if tc.complete {
+				storeMock.On("CompleteIdempotencyKey", "key:7", "k1", tc.handlerStatus, "application/json", []byte(tc.respBody+"\n")).
+					Return(nil).Once()
+			}
+			if tc.release {
+				storeMock.On("DeleteIdempotencyKey", "key:7", "k1").Return(nil).Once()
+			}
+
+			called := false
+			handler := mwIdempotency.New(silentlog.NewSilentLogger(), storeMock, time.Hour, 1024)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					called = true
+					body, err := io.ReadAll(r.Body)
+					require.NoError(t, err)
+					require.Equal(t, reqBody, string(body))
+
+					render.Status(r, tc.handlerStatus)
+					render.JSON(w, r, map[string]string{"alias": "new"})
+				}),
+			)
+
+			req := httptest.NewRequest(http.MethodPost, "/url", strings.NewReader(reqBody))
+			req.Header.Set(mwIdempotency.HeaderKey, "k1")
+			req = req.WithContext(mwAuth.WithAPIKey(req.Context(), domain.APIKey{ID: 7, WorkspaceID: 3}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respCode, rr.Code)
+			require.Equal(t, tc.handlerStatus != 0, called)
+			if tc.respError != "" {
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, tc.respError, problem.Detail)
+				return
+			}
+			require.JSONEq(t, tc.respBody, rr.Body.String())
+			require.Equal(t, "application/json", strings.Split(rr.Header().Get("Content-Type"), ";")[0])
+			if tc.replayed {
+				require.Equal(t, "true", rr.Header().Get(mwIdempotency.HeaderReplayed))
+			}
+		})
+	}
+}
+
+func TestIdempotency_NoKey(t *testing.T) {
+	storeMock := mwIdempotency.NewMockKeyStore(t)
+
+	handler := mwIdempotency.New(silentlog.NewSilentLogger(), storeMock, time.Hour, 1024)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}),
+	)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/url", strings.NewReader(reqBody)))
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/url", strings.NewReader(reqBody))
+	req.Header.Set(mwIdempotency.HeaderKey, strings.Repeat("k", mwIdempotency.MaxKeyLength+1))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestIdempotency_BodyTooLarge(t *testing.T) {
+	storeMock := mwIdempotency.NewMockKeyStore(t)
+
+	handler := mwIdempotency.New(silentlog.NewSilentLogger(), storeMock, time.Hour, int64(len(reqBody)-1))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("request with a too large body reached the handler")
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/url", strings.NewReader(reqBody))
+	req.Header.Set(mwIdempotency.HeaderKey, "k1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	var problem responseModel.Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	require.Equal(t, "request body with an idempotency key is longer than 28 bytes", problem.Detail)
+}
+
+func TestIdempotency_Scope(t *testing.T) {
+	cases := []struct {
+		name      string
+		key       *domain.APIKey
+		wantScope string
+	}{
+		{
+			name:      "API key",
+			key:       &domain.APIKey{ID: 7, WorkspaceID: 3},
+			wantScope: "key:7",
+		},
+		{
+			name:      "Anonymous by ip",
+			wantScope: "ip:203.0.113.5",
+		},
+		{
+			//every admin authenticates with the root key, they don't share its keys
+			name:      "Root key by ip",
+			key:       &mwAuth.RootKey,
+			wantScope: "ip:203.0.113.5",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			storeMock := mwIdempotency.NewMockKeyStore(t)
+			storeMock.On("CreateIdempotencyKey", mock.MatchedBy(func(k domain.IdempotencyKey) bool {
+				return k.Scope == tc.wantScope
+			})).Return(storage.ErrIdempotencyKeyExists).Once()
+			storeMock.On("GetIdempotencyKey", tc.wantScope, "k1").Return(domain.IdempotencyKey{
+				RequestHash: hash(reqBody), Status: http.StatusOK, Body: []byte("ok"),
+			}, nil).Once()
+
+			handler := mwIdempotency.New(silentlog.NewSilentLogger(), storeMock, time.Hour, 1024)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("replayed request reached the handler")
+				}),
+			)
+
+			req := httptest.NewRequest(http.MethodPost, "/url", strings.NewReader(reqBody))
+			req.Header.Set(mwIdempotency.HeaderKey, "k1")
+			req.RemoteAddr = "203.0.113.5:4000"
+			if tc.key != nil {
+				req = req.WithContext(mwAuth.WithAPIKey(req.Context(), *tc.key))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, "ok", rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package mwLegacy
+
+import (
+	"net/http"
+
+	responseModel "short-url/internal/http-server/model/response"
+)
+
+// New answers errors with the legacy response.Response shape and 200 status codes when enabled,
+// for clients that were written before the problem+json responses.
+func New(enabled bool) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(responseModel.WithLegacy(r.Context())))
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package mwRateLimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/ratelimit"
+	"short-url/internal/lib/sl"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+//go:generate mockery --name=Limiter
+type Limiter interface {
+	Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error)
+}
+
+// New limits the requests of every API key, requests without a key (or with the root key) are limited by client ip.
+// The name separates the buckets of the limits sharing a store.
+// Requests over the limit get 429 with Retry-After, requests are let through when the store fails.
+func New(log *slog.Logger, limiter Limiter, name string, limit ratelimit.Limit) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !limit.Enabled() {
+			return next
+		}
+
+		log := log.With(
+			slog.String("component", "middleware/ratelimit"),
+			slog.String("limit", name),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := name + ":" + clientKey(r)
+
+			res, err := limiter.Take(r.Context(), key, limit)
+			if err != nil {
+				log.Error("failed to take rate limit token",
+					sl.Err(err),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !res.Allowed {
+				log.Info("rate limit exceeded",
+					slog.String("key", key),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+				w.Header().Set("Retry-After", RetryAfter(res.RetryAfter))
+				responseModel.RenderError(w, r, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RetryAfter formats the wait as Retry-After seconds, rounded up so the client doesn't retry too early.
+func RetryAfter(wait time.Duration) string {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
+
+func clientKey(r *http.Request) string {
+	if key, ok := mwAuth.APIKeyFromContext(r.Context()); ok && key.ID != 0 {
+		return "key:" + strconv.FormatInt(key.ID, 10)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,111 @@
+package mwRateLimit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	mwAuth "short-url/internal/http-server/middleware/auth"
+	mwRateLimit "short-url/internal/http-server/middleware/ratelimit"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/lib/ratelimit"
+	"testing"
+	"time"
+
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	limit := ratelimit.PerPeriod(10, time.Minute, 5)
+
+	cases := []struct {
+		name       string
+		key        *domain.APIKey
+		wantKey    string
+		result     ratelimit.Result
+		mockError  error
+		respCode   int
+		retryAfter string
+	}{
+		{
+			name:     "Allowed",
+			key:      &domain.APIKey{ID: 7, WorkspaceID: 3},
+			wantKey:  "create:key:7",
+			result:   ratelimit.Result{Allowed: true},
+			respCode: http.StatusOK,
+		},
+		{
+			name:       "Exceeded",
+			key:        &domain.APIKey{ID: 7, WorkspaceID: 3},
+			wantKey:    "create:key:7",
+			result:     ratelimit.Result{RetryAfter: 1500 * time.Millisecond},
+			respCode:   http.StatusTooManyRequests,
+			retryAfter: "2",
+		},
+		{
+			name:     "Anonymous by ip",
+			wantKey:  "create:ip:203.0.113.5",
+			result:   ratelimit.Result{Allowed: true},
+			respCode: http.StatusOK,
+		},
+		{
+			name:     "Root key by ip",
+			key:      &mwAuth.RootKey,
+			wantKey:  "create:ip:203.0.113.5",
+			result:   ratelimit.Result{Allowed: true},
+			respCode: http.StatusOK,
+		},
+		{
+			name:      "Store error",
+			wantKey:   "create:ip:203.0.113.5",
+			mockError: errors.New("unexpected error"),
+			respCode:  http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			limiterMock := mwRateLimit.NewMockLimiter(t)
+			limiterMock.On("Take", mock.Anything, tc.wantKey, limit).Return(tc.result, tc.mockError).Once()
+
+			handler := mwRateLimit.New(silentlog.NewSilentLogger(), limiterMock, "create", limit)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+			)
+
+			req := httptest.NewRequest(http.MethodPost, "/url", nil)
+			req.RemoteAddr = "203.0.113.5:4000"
+			if tc.key != nil {
+				req = req.WithContext(mwAuth.WithAPIKey(req.Context(), *tc.key))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respCode, rr.Code)
+			require.Equal(t, tc.retryAfter, rr.Header().Get("Retry-After"))
+			if tc.respCode == http.StatusTooManyRequests {
+				var problem responseModel.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				require.Equal(t, "rate limit exceeded", problem.Detail)
+			}
+		})
+	}
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	limiterMock := mwRateLimit.NewMockLimiter(t)
+
+	handler := mwRateLimit.New(silentlog.NewSilentLogger(), limiterMock, "redirect", ratelimit.Limit{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/alias", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,94 @@
+package mwRealIP_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	mwRealIP "short-url/internal/http-server/middleware/realip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted, err := mwRealIP.ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name           string
+		remoteAddr     string
+		forwardedFor   []string
+		wantRemoteAddr string
+	}{
+		{
+			name:           "Untrusted remote",
+			remoteAddr:     "203.0.113.5:4000",
+			forwardedFor:   []string{"198.51.100.1"},
+			wantRemoteAddr: "203.0.113.5:4000",
+		},
+		{
+			name:           "Trusted proxy",
+			remoteAddr:     "10.1.2.3:4000",
+			forwardedFor:   []string{"198.51.100.1"},
+			wantRemoteAddr: "198.51.100.1:0",
+		},
+		{
+			name:           "Spoofed leftmost address",
+			remoteAddr:     "10.1.2.3:4000",
+			forwardedFor:   []string{"1.1.1.1, 198.51.100.1, 192.168.1.1"},
+			wantRemoteAddr: "198.51.100.1:0",
+		},
+		{
+			name:           "Multiple headers",
+			remoteAddr:     "192.168.1.1:4000",
+			forwardedFor:   []string{"198.51.100.1", "10.0.0.2"},
+			wantRemoteAddr: "198.51.100.1:0",
+		},
+		{
+			name:           "Only proxies",
+			remoteAddr:     "10.1.2.3:4000",
+			forwardedFor:   []string{"10.0.0.2"},
+			wantRemoteAddr: "10.1.2.3:4000",
+		},
+		{
+			name:           "Invalid address",
+			remoteAddr:     "10.1.2.3:4000",
+			forwardedFor:   []string{"unknown"},
+			wantRemoteAddr: "10.1.2.3:4000",
+		},
+		{
+			name:           "No header",
+			remoteAddr:     "10.1.2.3:4000",
+			wantRemoteAddr: "10.1.2.3:4000",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var remoteAddr string
+			handler := mwRealIP.New(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				remoteAddr = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/alias", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for _, value := range tc.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			require.Equal(t, tc.wantRemoteAddr, remoteAddr)
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	_, err := mwRealIP.ParseTrustedProxies([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+
+	_, err = mwRealIP.ParseTrustedProxies([]string{"proxy.local"})
+	require.Error(t, err)
+}
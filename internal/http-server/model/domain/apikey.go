@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// API key scopes, admin grants every scope
+const (
+	ScopeLinksWrite = "links:write"
+	ScopeLinksRead  = "links:read"
+	ScopeAdmin      = "admin"
+)
+
+var Scopes = []string{ScopeLinksWrite, ScopeLinksRead, ScopeAdmin}
+
+// APIKey authenticates API clients, only the hash of the secret key is stored
+type APIKey struct {
+	ID int64 `json:"id"`
+	// keys are the members of a workspace and only see its links
+	WorkspaceID int64  `json:"workspace_id"`
+	Name        string `json:"name"`
+	// first characters of the key to recognize it in lists
+	Prefix    string     `json:"prefix"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, ScopeAdmin) || slices.Contains(k.Scopes, scope)
+}
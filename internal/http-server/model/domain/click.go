@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+// Click is a single resolved redirect
+type Click struct {
+	LinkID      int64
+	WorkspaceID int64
+	// hostname of the custom domain of the link, empty on the default domain
+	Domain    string
+	Alias     string
+	ClickedAt time.Time
+	Referrer  string
+	UserAgent string
+	// host of the referrer and browser family, used by the rollup breakdowns
+	ReferrerHost string
+	Browser      string
+	// ISO 3166 country code set by the CDN or proxy, empty if unknown
+	Country string
+	// client ip, only used to compute IPHash and never stored
+	IP     string
+	IPHash string
+	// sampled clicks are also written to the outbox as url_clicked events
+	Sampled bool
+}
+
+const (
+	BucketHour = "hour"
+	BucketDay  = "day"
+)
+
+// sources of the click stats
+const (
+	StatsSourceRaw    = "raw"
+	StatsSourceRollup = "rollup"
+)
+
+// breakdown dimensions of the click rollups
+const (
+	DimensionReferrer = "referrer"
+	DimensionBrowser  = "browser"
+	DimensionCountry  = "country"
+)
+
+// ClickStats are the clicks of an alias in [From, To)
+type ClickStats struct {
+	Alias  string    `json:"alias"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Bucket string    `json:"bucket"`
+	// rollup when the range is aligned to buckets that are already aggregated, raw otherwise
+	Source string `json:"source"`
+	Total  int    `json:"total"`
+	// distinct visitors of the whole range, counted from the raw clicks. It is omitted for rollup stats
+	// of ranges whose raw clicks are partly purged, the buckets still have their own unique visitors.
+	UniqueVisitors *int          `json:"unique_visitors,omitempty"`
+	Buckets        []ClickBucket `json:"buckets"`
+	// top values of each dimension by clicks
+	Referrers []ClickCount `json:"referrers"`
+	Browsers  []ClickCount `json:"browsers"`
+	Countries []ClickCount `json:"countries"`
+}
+
+type ClickBucket struct {
+	Start          time.Time `json:"start"`
+	Clicks         int       `json:"clicks"`
+	UniqueVisitors int       `json:"unique_visitors"`
+}
+
+type ClickCount struct {
+	Value  string `json:"value"`
+	Clicks int    `json:"clicks"`
+}
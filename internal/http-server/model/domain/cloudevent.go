@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	// prefix of the CloudEvents type attribute, e.g. short-url.url_saved
+	CloudEventTypePrefix = "short-url."
+)
+
+// CloudEvent is the CloudEvents 1.0 structured-mode envelope every event is published in,
+// see https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/spec.md
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// NewCloudEvent wraps the outbox event, source identifies this service instance.
+func NewCloudEvent(event Event, source string) CloudEvent {
+	data := json.RawMessage(event.Payload)
+	if !json.Valid(data) {
+		//rows written before the payloads were typed may hold invalid json, deliver them as a string
+		data, _ = json.Marshal(event.Payload)
+	}
+
+	return CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              strconv.Itoa(event.ID),
+		Source:          source,
+		Type:            CloudEventTypePrefix + event.EventType,
+		Time:            event.CreatedAt.UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
@@ -0,0 +1,55 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"short-url/internal/http-server/model/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCloudEvent(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		name    string
+		payload string
+		data    string
+	}{
+		{
+			name:    "json payload",
+			payload: `{"schema_version":1,"id":1,"url":"https://a.b/\"q\"","alias":"x"}`,
+			data:    `{"schema_version":1,"id":1,"url":"https://a.b/\"q\"","alias":"x"}`,
+		},
+		{
+			name:    "legacy malformed payload",
+			payload: `{"id": "1", "url": "https://a.b/"q"", "alias": "x"}`,
+			data:    `"{\"id\": \"1\", \"url\": \"https://a.b/\"q\"\", \"alias\": \"x\"}"`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ce := domain.NewCloudEvent(domain.Event{
+				ID:        42,
+				EventType: domain.EventURLSaved,
+				Payload:   tc.payload,
+				CreatedAt: createdAt,
+			}, "/short-url")
+
+			require.Equal(t, "1.0", ce.SpecVersion)
+			require.Equal(t, "42", ce.ID)
+			require.Equal(t, "short-url.url_saved", ce.Type)
+			require.Equal(t, createdAt, ce.Time)
+
+			body, err := json.Marshal(ce)
+			require.NoError(t, err)
+			require.True(t, json.Valid(body))
+			require.JSONEq(t, tc.data, string(ce.Data))
+		})
+	}
+}
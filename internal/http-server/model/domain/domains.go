@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Domain is a custom host of a workspace, its links are redirected on it once the domain is verified
+type Domain struct {
+	ID          int64  `json:"id"`
+	WorkspaceID int64  `json:"workspace_id"`
+	Hostname    string `json:"hostname"`
+	// expected in the DNS TXT record proving the ownership of the hostname
+	VerificationToken string `json:"verification_token"`
+	// nil until the TXT record is found
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (d Domain) Verified() bool {
+	return d.VerifiedAt != nil
+}
+
+// LinkRef identifies a link: aliases are unique per custom domain,
+// and per workspace on the default domain
+type LinkRef struct {
+	WorkspaceID int64
+	// hostname of the custom domain, empty for the default domain
+	Domain string
+	Alias  string
+}
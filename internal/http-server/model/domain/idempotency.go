@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// IdempotencyKey is a request sent with an Idempotency-Key header,
+// its response is replayed to the retries of the request until the key expires
+type IdempotencyKey struct {
+	// api key or client the key belongs to, keys of different clients don't clash
+	Scope string
+	Key   string
+	// hash of the method, path and body of the request
+	RequestHash string
+	// status of the stored response, 0 while the request is in progress
+	Status      int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the response of the request is stored
+func (k IdempotencyKey) Completed() bool {
+	return k.Status != 0
+}
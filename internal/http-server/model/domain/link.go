@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+// Link is a short alias of a url
+type Link struct {
+	ID          int64 `json:"id"`
+	WorkspaceID int64 `json:"workspace_id"`
+	// hostname of the custom domain the link is served on, empty for the default domain
+	Domain string `json:"domain,omitempty"`
+	// aliases are unique within the domain, see LinkRef
+	Alias string `json:"alias"`
+	URL   string `json:"url"`
+	// nil for links that never expire
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// disabled links are kept but not redirected
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// key that created the link, nil for links created before api keys or by the root user
+	APIKeyID *int64 `json:"api_key_id,omitempty"`
+}
+
+// LinkUpdate is a partial update of a link, nil fields are left unchanged
+type LinkUpdate struct {
+	URL       *string
+	ExpiresAt *time.Time
+	// removes the expiration, ExpiresAt must be nil
+	NeverExpires bool
+	Disabled     *bool
+}
+
+// Apply changes the link fields set in the update
+func (l *Link) Apply(update LinkUpdate) {
+	if update.URL != nil {
+		l.URL = *update.URL
+	}
+	if update.ExpiresAt != nil {
+		l.ExpiresAt = update.ExpiresAt
+	}
+	if update.NeverExpires {
+		l.ExpiresAt = nil
+	}
+	if update.Disabled != nil {
+		l.Disabled = *update.Disabled
+	}
+}
+
+const (
+	LinkSortCreatedAt = "created_at"
+	LinkSortAlias     = "alias"
+)
+
+// LinkQuery filters, sorts and pages links
+type LinkQuery struct {
+	WorkspaceID int64
+	// alias prefix, case-sensitive
+	Prefix string
+	// creation time range [CreatedFrom, CreatedTo)
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	SortBy      string
+	Desc        bool
+	// the last link of the previous page, nil for the first page
+	After *LinkCursor
+	Limit int
+}
+
+// LinkCursor holds the sort keys of a link to continue the listing after it
+type LinkCursor struct {
+	ID        int64     `json:"id"`
+	Alias     string    `json:"alias"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,8 +1,39 @@
 package domain
 
+import "time"
+
 // some domain event format consumable by anther service(db event record -> domain event)
 type Event struct {
-	ID        int
-	EventType string
-	Payload   string
+	ID        int       `json:"id"`
+	EventType string    `json:"event_type"`
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+	// failed delivery attempts so far, not a part of the published event
+	Attempts int `json:"-"`
+}
+
+// statuses of OutboxEvent, pending events are waiting for their delivery or being delivered
+const (
+	EventStatusPending = "pending"
+	EventStatusDead    = "dead"
+	EventStatusDone    = "done"
+)
+
+var EventStatuses = []string{EventStatusPending, EventStatusDead, EventStatusDone}
+
+// OutboxEvent is an event of the outbox with its delivery state
+type OutboxEvent struct {
+	Event
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error"`
+	// failed pending events are retried after it
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// DeadEvent is an event that ran out of delivery attempts
+type DeadEvent struct {
+	Event
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error"`
 }
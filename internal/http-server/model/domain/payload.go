@@ -0,0 +1,71 @@
+package domain
+
+import "time"
+
+// event types written to the outbox
+const (
+	EventURLSaved   = "url_saved"
+	EventURLUpdated = "url_updated"
+	EventURLDeleted = "url_deleted"
+	EventURLClicked = "url_clicked"
+	EventURLExpired = "url_expired"
+)
+
+// PayloadSchemaVersion is bumped on every incompatible change of the payload structs
+const PayloadSchemaVersion = 1
+
+// URLSavedPayload is the payload of the url_saved event
+type URLSavedPayload struct {
+	SchemaVersion int        `json:"schema_version"`
+	ID            int64      `json:"id"`
+	WorkspaceID   int64      `json:"workspace_id"`
+	Domain        string     `json:"domain,omitempty"`
+	URL           string     `json:"url"`
+	Alias         string     `json:"alias"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+// URLUpdatedPayload is the payload of the url_updated event
+type URLUpdatedPayload struct {
+	SchemaVersion int        `json:"schema_version"`
+	ID            int64      `json:"id"`
+	WorkspaceID   int64      `json:"workspace_id"`
+	Domain        string     `json:"domain,omitempty"`
+	URL           string     `json:"url"`
+	PreviousURL   string     `json:"previous_url"`
+	Alias         string     `json:"alias"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Disabled      bool       `json:"disabled"`
+}
+
+// URLDeletedPayload is the payload of the url_deleted event
+type URLDeletedPayload struct {
+	SchemaVersion int    `json:"schema_version"`
+	ID            int64  `json:"id"`
+	WorkspaceID   int64  `json:"workspace_id"`
+	Domain        string `json:"domain,omitempty"`
+	URL           string `json:"url"`
+	Alias         string `json:"alias"`
+}
+
+// URLClickedPayload is the payload of the sampled url_clicked event
+type URLClickedPayload struct {
+	SchemaVersion int       `json:"schema_version"`
+	ID            int64     `json:"id"`
+	WorkspaceID   int64     `json:"workspace_id"`
+	Domain        string    `json:"domain,omitempty"`
+	Alias         string    `json:"alias"`
+	ClickedAt     time.Time `json:"clicked_at"`
+}
+
+// URLExpiredPayload is the payload of the url_expired event written by the janitor
+type URLExpiredPayload struct {
+	SchemaVersion int       `json:"schema_version"`
+	ID            int64     `json:"id"`
+	WorkspaceID   int64     `json:"workspace_id"`
+	Domain        string    `json:"domain,omitempty"`
+	URL           string    `json:"url"`
+	Alias         string    `json:"alias"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Archived      bool      `json:"archived"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// DefaultWorkspaceID is the workspace of the links created before workspaces,
+// its aliases are served on the root of the default domain
+const DefaultWorkspaceID int64 = 1
+
+// Workspace is a tenant with its own links and alias namespace, its members are api keys
+type Workspace struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// path namespace of the redirects: /w/{slug}/{alias}
+	Slug string `json:"slug"`
+	// links the workspace may create per calendar month, nil is unlimited
+	MonthlyLinkQuota *int64    `json:"monthly_link_quota,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// WorkspaceUsage is the number of links created by the workspace in a usage period
+type WorkspaceUsage struct {
+	WorkspaceID  int64  `json:"workspace_id"`
+	Period       string `json:"period"`
+	LinksCreated int64  `json:"links_created"`
+}
+
+// UsagePeriod returns the calendar month (UTC) of t the quotas are counted in, e.g. "2025-01"
+func UsagePeriod(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// NextUsagePeriod returns the start of the month after t, when the quotas are reset
+func NextUsagePeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,108 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// ContentTypeProblem is the media type of RFC 7807 problem details
+const ContentTypeProblem = "application/problem+json"
+
+// Problem is an RFC 7807 error response
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// per-field validation errors
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// problem types by status, relative to the API root
+var problemTypes = map[int]string{
+	http.StatusBadRequest:            "/problems/bad-request",
+	http.StatusUnauthorized:          "/problems/unauthorized",
+	http.StatusForbidden:             "/problems/forbidden",
+	http.StatusNotFound:              "/problems/not-found",
+	http.StatusConflict:              "/problems/conflict",
+	http.StatusGone:                  "/problems/gone",
+	http.StatusRequestEntityTooLarge: "/problems/payload-too-large",
+	http.StatusUnprocessableEntity:   "/problems/unprocessable-entity",
+	http.StatusTooManyRequests:       "/problems/too-many-requests",
+	http.StatusInternalServerError:   "/problems/internal-error",
+	http.StatusBadGateway:            "/problems/bad-gateway",
+}
+
+func NewProblem(r *http.Request, status int, detail string) Problem {
+	problemType, ok := problemTypes[status]
+	if !ok {
+		problemType = "about:blank"
+	}
+	return Problem{
+		Type:     problemType,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	}
+}
+
+type legacyKey struct{}
+
+// WithLegacy makes the request answered with the legacy Response shape and 200 status codes
+func WithLegacy(ctx context.Context) context.Context {
+	return context.WithValue(ctx, legacyKey{}, true)
+}
+
+func IsLegacy(ctx context.Context) bool {
+	legacy, _ := ctx.Value(legacyKey{}).(bool)
+	return legacy
+}
+
+// Status sets the status of a successful response, legacy clients always get 200.
+func Status(r *http.Request, status int) {
+	if !IsLegacy(r.Context()) {
+		render.Status(r, status)
+	}
+}
+
+// RenderError writes a problem with the status, or the legacy Response with the detail as error.
+func RenderError(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	if IsLegacy(r.Context()) {
+		render.JSON(w, r, Error(detail))
+		return
+	}
+	RenderProblem(w, r, NewProblem(r, status, detail))
+}
+
+// RenderValidationError writes a 400 problem listing the invalid fields.
+func RenderValidationError(w http.ResponseWriter, r *http.Request, errs validator.ValidationErrors) {
+	if IsLegacy(r.Context()) {
+		render.JSON(w, r, ValidationError(errs))
+		return
+	}
+	problem := NewProblem(r, http.StatusBadRequest, "invalid body")
+	for _, err := range errs {
+		problem.Errors = append(problem.Errors, FieldError{
+			Field:   err.Field(),
+			Message: fieldErrorMessage(err),
+		})
+	}
+	RenderProblem(w, r, problem)
+}
+
+func RenderProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
@@ -21,18 +21,22 @@ func ValidationError(errs validator.ValidationErrors) Response {
 	errMsgs := []string{"invalid body"}
 
 	for _, err := range errs {
-		switch err.ActualTag() {
-		case "required":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is a required field", err.Field()))
-		case "url":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not in URL format", err.Field()))
-		default:
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not valid", err.Field()))
-		}
+		errMsgs = append(errMsgs, fieldErrorMessage(err))
 	}
 	return Error(strings.Join(errMsgs, ","))
 }
 
+func fieldErrorMessage(err validator.FieldError) string {
+	switch err.ActualTag() {
+	case "required":
+		return fmt.Sprintf("field %s is a required field", err.Field())
+	case "url":
+		return fmt.Sprintf("field %s is not in URL format", err.Field())
+	default:
+		return fmt.Sprintf("field %s is not valid", err.Field())
+	}
+}
+
 func OK() Response {
 	return Response{
 		Status: StatusOK,
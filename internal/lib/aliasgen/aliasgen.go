@@ -0,0 +1,80 @@
+package aliasgen
+
+import (
+	"errors"
+	"fmt"
+	"short-url/internal/lib/random"
+	"sync/atomic"
+)
+
+// named alphabets of the config, other values are used as the characters of the alphabet
+var alphabets = map[string]string{
+	"base62":      random.AlphabetBase62,
+	"unambiguous": random.AlphabetUnambiguous,
+	"lowercase":   random.AlphabetLowercase,
+}
+
+// Alphabet returns the named alphabet, or name itself for a custom one
+func Alphabet(name string) string {
+	if alphabet, ok := alphabets[name]; ok {
+		return alphabet
+	}
+	return name
+}
+
+// Generator makes random aliases from crypto/rand. When a save collides growAfter times in a row
+// the keyspace of the length is getting dense and the length grows by one, up to maxLength.
+// The grown length is kept in memory, every replica starts again from the configured one.
+type Generator struct {
+	alphabet  string
+	maxLength int64
+	growAfter int
+
+	length atomic.Int64
+}
+
+func New(alphabet string, length, maxLength, growAfter int) (*Generator, error) {
+	const op = "lib.aliasgen.New"
+
+	if err := random.ValidateAlphabet(alphabet); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if length < 1 {
+		return nil, fmt.Errorf("%s: %w", op, errors.New("length must be positive"))
+	}
+	if maxLength < length {
+		maxLength = length
+	}
+	if growAfter < 1 {
+		return nil, fmt.Errorf("%s: %w", op, errors.New("grow after must be positive"))
+	}
+
+	g := &Generator{
+		alphabet:  alphabet,
+		maxLength: int64(maxLength),
+		growAfter: growAfter,
+	}
+	g.length.Store(int64(length))
+	return g, nil
+}
+
+// Generate returns an alias for the attempt of a save, attempts start at 1 and grow with every collision.
+func (g *Generator) Generate(attempt int) (string, error) {
+	length := g.length.Load()
+	if attempt == g.growAfter+1 && length < g.maxLength {
+		//concurrent saves colliding at the same length grow it only once
+		g.length.CompareAndSwap(length, length+1)
+		length = g.length.Load()
+	}
+	return random.NewString(g.alphabet, int(length))
+}
+
+// Reserved is false, random aliases only collide with custom ones by chance and are regenerated
+func (g *Generator) Reserved(string) bool {
+	return false
+}
+
+// Length returns the current length of the aliases
+func (g *Generator) Length() int {
+	return int(g.length.Load())
+}
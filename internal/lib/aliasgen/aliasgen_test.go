@@ -0,0 +1,105 @@
+package aliasgen
+
+import (
+	"strings"
+	"testing"
+
+	"short-url/internal/lib/idcode"
+	"short-url/internal/lib/random"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Grow(t *testing.T) {
+	g, err := New(Alphabet("unambiguous"), 6, 8, 2)
+	require.NoError(t, err)
+
+	alias, err := g.Generate(1)
+	require.NoError(t, err)
+	require.Len(t, alias, 6)
+	require.False(t, strings.ContainsAny(alias, "0Oo1lI"))
+
+	//the retries within the grow limit keep the length
+	alias, err = g.Generate(2)
+	require.NoError(t, err)
+	require.Len(t, alias, 6)
+
+	//the third attempt grows it for every following save
+	alias, err = g.Generate(3)
+	require.NoError(t, err)
+	require.Len(t, alias, 7)
+	alias, err = g.Generate(4)
+	require.NoError(t, err)
+	require.Len(t, alias, 7)
+	alias, err = g.Generate(1)
+	require.NoError(t, err)
+	require.Len(t, alias, 7)
+
+	_, err = g.Generate(3)
+	require.NoError(t, err)
+	_, err = g.Generate(3)
+	require.NoError(t, err)
+	require.Equal(t, 8, g.Length())
+}
+
+func TestNew(t *testing.T) {
+	require.Equal(t, random.AlphabetBase62, Alphabet("base62"))
+	require.Equal(t, "abcdef", Alphabet("abcdef"))
+
+	_, err := New("aa", 6, 12, 2)
+	require.ErrorIs(t, err, random.ErrInvalidAlphabet)
+	_, err = New(random.AlphabetBase62, 0, 12, 2)
+	require.Error(t, err)
+	_, err = New(random.AlphabetBase62, 6, 12, 0)
+	require.Error(t, err)
+
+	//the max length is at least the length
+	g, err := New(random.AlphabetBase62, 6, 0, 1)
+	require.NoError(t, err)
+	_, err = g.Generate(2)
+	require.NoError(t, err)
+	require.Equal(t, 6, g.Length())
+}
+
+// counter is a Sequence in memory
+type counter struct {
+	next int64
+}
+
+func (c *counter) NextAliasID() (int64, error) {
+	c.next++
+	return c.next, nil
+}
+
+func TestSequential(t *testing.T) {
+	codec, err := idcode.NewBase62(random.AlphabetBase62, "secret")
+	require.NoError(t, err)
+	s := NewSequential(&counter{next: 61}, codec)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		alias, err := s.Generate(1)
+		require.NoError(t, err)
+		require.False(t, seen[alias])
+		seen[alias] = true
+
+		id, err := codec.Decode(alias)
+		require.NoError(t, err)
+		require.Equal(t, int64(62+i), id)
+	}
+}
+
+func TestReserved(t *testing.T) {
+	codec, err := idcode.NewSqids(random.AlphabetBase62, "secret", 0)
+	require.NoError(t, err)
+	s := NewSequential(&counter{}, codec)
+
+	//codes of the sequence can't be taken by custom aliases, other aliases can
+	require.True(t, s.Reserved(codec.Encode(1000)))
+	require.False(t, s.Reserved("my-alias"))
+	require.False(t, s.Reserved(""))
+
+	g, err := New(random.AlphabetBase62, 6, 6, 1)
+	require.NoError(t, err)
+	require.False(t, g.Reserved("abcdef"))
+}
@@ -0,0 +1,41 @@
+package aliasgen
+
+import (
+	"fmt"
+	"short-url/internal/lib/idcode"
+)
+
+// Sequence hands out increasing ids shared by the replicas
+type Sequence interface {
+	NextAliasID() (int64, error)
+}
+
+// Sequential encodes the next id of the sequence, so the codes are unique without a lookup
+// and as short as the number of links allows. Custom aliases that are codes are reserved,
+// an id whose code was taken anyway (by an import) collides on save and the retry takes the next one.
+type Sequential struct {
+	seq   Sequence
+	codec idcode.Codec
+}
+
+func NewSequential(seq Sequence, codec idcode.Codec) *Sequential {
+	return &Sequential{seq: seq, codec: codec}
+}
+
+// Generate returns the code of the next id, the attempt doesn't matter
+func (s *Sequential) Generate(_ int) (string, error) {
+	const op = "lib.aliasgen.Sequential.Generate"
+
+	id, err := s.seq.NextAliasID()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return s.codec.Encode(id), nil
+}
+
+// Reserved reports whether the alias is the code of an id, a custom alias taking it would collide
+// with the generated alias of that id
+func (s *Sequential) Reserved(alias string) bool {
+	id, err := s.codec.Decode(alias)
+	return err == nil && s.codec.Encode(id) == alias
+}
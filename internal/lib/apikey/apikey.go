@@ -0,0 +1,32 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	// keyPrefix makes the keys recognizable by secret scanners
+	keyPrefix = "sk_"
+	keyBytes  = 32
+	// length of the key prefix shown in lists
+	displayLength = len(keyPrefix) + 6
+)
+
+// Generate returns a new random key and its display prefix.
+func Generate() (key string, prefix string, err error) {
+	b := make([]byte, keyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:displayLength], nil
+}
+
+// Hash is stored instead of the key, keys are random enough for a plain sha256.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,23 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	key, prefix, err := Generate()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, keyPrefix))
+	require.True(t, strings.HasPrefix(key, prefix))
+	require.Len(t, prefix, displayLength)
+
+	other, _, err := Generate()
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+
+	require.Equal(t, Hash(key), Hash(key))
+	require.NotEqual(t, Hash(key), Hash(other))
+}
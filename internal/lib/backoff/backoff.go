@@ -0,0 +1,29 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Exponential returns the delay before the given attempt (starting from 1):
+// base*2^(attempt-1) capped by max, with a random jitter in the upper half
+// so that events failed together don't retry together.
+func Exponential(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	if d <= 1 {
+		return d
+	}
+
+	half := d / 2
+	return half + rand.N(d-half)
+}
@@ -0,0 +1,35 @@
+package backoff_test
+
+import (
+	"short-url/internal/lib/backoff"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExponential(t *testing.T) {
+	cases := []struct {
+		name     string
+		attempt  int
+		min, max time.Duration
+	}{
+		{name: "first attempt", attempt: 1, min: 500 * time.Millisecond, max: time.Second},
+		{name: "third attempt", attempt: 3, min: 2 * time.Second, max: 4 * time.Second},
+		{name: "capped", attempt: 30, min: 30 * time.Second, max: time.Minute},
+		{name: "zero attempt", attempt: 0, min: 500 * time.Millisecond, max: time.Second},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			for i := 0; i < 100; i++ {
+				d := backoff.Exponential(tc.attempt, time.Second, time.Minute)
+				require.GreaterOrEqual(t, d, tc.min)
+				require.Less(t, d, tc.max)
+			}
+		})
+	}
+}
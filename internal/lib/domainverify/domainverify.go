@@ -0,0 +1,75 @@
+package domainverify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	// recordPrefix is the label of the TXT record below the verified hostname
+	recordPrefix = "_short-url-verification."
+	valuePrefix  = "short-url-verification="
+	tokenBytes   = 16
+)
+
+var ErrNotVerified = errors.New("verification record not found")
+
+// Resolver looks up DNS TXT records, *net.Resolver implements it and FakeResolver replaces it in tests
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Verifier proves the ownership of a hostname by a TXT record containing its token
+type Verifier struct {
+	resolver Resolver
+}
+
+func New(resolver Resolver) *Verifier {
+	return &Verifier{resolver: resolver}
+}
+
+// NewToken returns a random verification token.
+func NewToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// RecordName is the name of the TXT record the owner of hostname has to create.
+func RecordName(hostname string) string {
+	return recordPrefix + hostname
+}
+
+// RecordValue is the content of the TXT record.
+func RecordValue(token string) string {
+	return valuePrefix + token
+}
+
+// Verify returns ErrNotVerified if no TXT record of hostname contains the token.
+func (v *Verifier) Verify(ctx context.Context, hostname string, token string) error {
+	const op = "lib.domainverify.Verify"
+
+	records, err := v.resolver.LookupTXT(ctx, RecordName(hostname))
+	if err != nil {
+		//a missing record is an answer, not a failure of the lookup
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return fmt.Errorf("%s: %w", op, ErrNotVerified)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	want := RecordValue(token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %w", op, ErrNotVerified)
+}
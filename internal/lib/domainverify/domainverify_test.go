@@ -0,0 +1,67 @@
+package domainverify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingResolver struct{}
+
+func (failingResolver) LookupTXT(context.Context, string) ([]string, error) {
+	return nil, errors.New("timeout")
+}
+
+func TestVerify(t *testing.T) {
+	token, err := NewToken()
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		resolver Resolver
+		wantErr  error
+		// the lookup failed, the domain may still be verified later
+		lookupFailed bool
+	}{
+		{
+			name:     "Record found",
+			resolver: FakeResolver{"_short-url-verification.go.example": {"v=spf1 -all", RecordValue(token)}},
+		},
+		{
+			name:     "Other token",
+			resolver: FakeResolver{"_short-url-verification.go.example": {RecordValue("other")}},
+			wantErr:  ErrNotVerified,
+		},
+		{
+			name:     "No record",
+			resolver: FakeResolver{},
+			wantErr:  ErrNotVerified,
+		},
+		{
+			name:         "Lookup error",
+			resolver:     failingResolver{},
+			lookupFailed: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := New(tc.resolver).Verify(context.Background(), "go.example", token)
+			switch {
+			case tc.wantErr != nil:
+				require.ErrorIs(t, err, tc.wantErr)
+			case tc.lookupFailed:
+				require.Error(t, err)
+				require.NotErrorIs(t, err, ErrNotVerified)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package domainverify
+
+import (
+	"context"
+	"net"
+)
+
+// FakeResolver answers TXT lookups from the map of record names, for tests and local runs
+type FakeResolver map[string][]string
+
+func (f FakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := f[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
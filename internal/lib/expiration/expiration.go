@@ -0,0 +1,28 @@
+package expiration
+
+import (
+	"errors"
+	"time"
+)
+
+// Resolve turns expires_at or ttl of a request into an absolute time, nil means the link never expires.
+func Resolve(expiresAt *time.Time, ttl string, now time.Time) (*time.Time, error) {
+	switch {
+	case expiresAt != nil && ttl != "":
+		return nil, errors.New("only one of expires_at and ttl is allowed")
+	case expiresAt != nil:
+		if !expiresAt.After(now) {
+			return nil, errors.New("field expires_at must be in the future")
+		}
+		return expiresAt, nil
+	case ttl != "":
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			return nil, errors.New("field ttl must be a positive duration, e.g. 72h")
+		}
+		res := now.Add(d)
+		return &res, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,70 @@
+package idcode
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Base62 writes the id in the base of the alphabet size, the codes have the minimal length
+type Base62 struct {
+	alphabet string
+}
+
+func NewBase62(alphabet, salt string) (*Base62, error) {
+	const op = "lib.idcode.NewBase62"
+
+	alphabet, err := saltedAlphabet(alphabet, salt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &Base62{alphabet: alphabet}, nil
+}
+
+func (b *Base62) Encode(id int64) string {
+	return toCode(uint64(id), b.alphabet)
+}
+
+func (b *Base62) Decode(code string) (int64, error) {
+	//codes never start with the zero digit, except the code of 0
+	if code == "" || (len(code) > 1 && code[0] == b.alphabet[0]) {
+		return 0, ErrInvalidCode
+	}
+	n, ok := toNumber(code, b.alphabet)
+	if !ok || n > 1<<63-1 {
+		return 0, ErrInvalidCode
+	}
+	return int64(n), nil
+}
+
+func toCode(n uint64, alphabet string) string {
+	base := uint64(len(alphabet))
+	var code []byte
+	for {
+		code = append(code, alphabet[n%base])
+		n /= base
+		if n == 0 {
+			break
+		}
+	}
+	for i, j := 0, len(code)-1; i < j; i, j = i+1, j-1 {
+		code[i], code[j] = code[j], code[i]
+	}
+	return string(code)
+}
+
+func toNumber(code, alphabet string) (uint64, bool) {
+	base := uint64(len(alphabet))
+	var n uint64
+	for i := 0; i < len(code); i++ {
+		digit := strings.IndexByte(alphabet, code[i])
+		if digit < 0 {
+			return 0, false
+		}
+		if n > (math.MaxUint64-uint64(digit))/base {
+			return 0, false
+		}
+		n = n*base + uint64(digit)
+	}
+	return n, true
+}
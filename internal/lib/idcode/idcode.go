@@ -0,0 +1,55 @@
+package idcode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"short-url/internal/lib/random"
+)
+
+var ErrInvalidCode = errors.New("invalid code")
+
+// Codec turns ids into short codes and back, every id has one code
+type Codec interface {
+	Encode(id int64) string
+	Decode(code string) (int64, error)
+}
+
+// saltedAlphabet permutes the alphabet with a Fisher-Yates shuffle driven by HMAC-SHA256 of the salt,
+// so the codes of consecutive ids can't be guessed without it. An empty salt keeps the alphabet.
+func saltedAlphabet(alphabet, salt string) (string, error) {
+	const op = "lib.idcode.saltedAlphabet"
+
+	if err := random.ValidateAlphabet(alphabet); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	chars := []byte(alphabet)
+	if salt == "" {
+		return alphabet, nil
+	}
+
+	mac := hmac.New(sha256.New, []byte(salt))
+	var block []byte
+	var counter uint64
+	next := func() uint64 {
+		if len(block) < 8 {
+			var c [8]byte
+			binary.BigEndian.PutUint64(c[:], counter)
+			counter++
+			mac.Reset()
+			mac.Write(c[:])
+			block = mac.Sum(nil)
+		}
+		v := binary.BigEndian.Uint64(block[:8])
+		block = block[8:]
+		return v
+	}
+
+	for i := len(chars) - 1; i > 0; i-- {
+		j := next() % uint64(i+1)
+		chars[i], chars[j] = chars[j], chars[i]
+	}
+	return string(chars), nil
+}
@@ -0,0 +1,89 @@
+package idcode
+
+import (
+	"testing"
+
+	"short-url/internal/lib/random"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqids_SpecVectors(t *testing.T) {
+	//codes of the unsalted default alphabet from the Sqids spec
+	s, err := NewSqids(random.AlphabetBase62, "", 0)
+	require.NoError(t, err)
+
+	for id, code := range []string{"bM", "Uk", "gb", "Ef", "Vq", "uw", "OI", "AX", "p6", "nJ"} {
+		require.Equal(t, code, s.Encode(int64(id)))
+	}
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	base62, err := NewBase62(random.AlphabetBase62, "secret")
+	require.NoError(t, err)
+	sqids, err := NewSqids(random.AlphabetBase62, "secret", 0)
+	require.NoError(t, err)
+	padded, err := NewSqids(random.AlphabetUnambiguous, "secret", 8)
+	require.NoError(t, err)
+
+	for _, codec := range []Codec{base62, sqids, padded} {
+		seen := make(map[string]bool)
+		for _, id := range []int64{0, 1, 2, 61, 62, 63, 1000, 238327, 238328, 1<<40 + 7, 1<<63 - 1} {
+			code := codec.Encode(id)
+			require.False(t, seen[code], "code %q is repeated", code)
+			seen[code] = true
+
+			decoded, err := codec.Decode(code)
+			require.NoError(t, err)
+			require.Equal(t, id, decoded)
+		}
+	}
+
+	//base62 codes have the minimal length
+	require.Len(t, base62.Encode(61), 1)
+	require.Len(t, base62.Encode(62), 2)
+	require.Len(t, base62.Encode(238327), 3)
+	require.Len(t, padded.Encode(1), 8)
+}
+
+func TestCodecs_Salt(t *testing.T) {
+	a, err := NewSqids(random.AlphabetBase62, "salt-a", 0)
+	require.NoError(t, err)
+	b, err := NewSqids(random.AlphabetBase62, "salt-b", 0)
+	require.NoError(t, err)
+	require.NotEqual(t, a.Encode(12345), b.Encode(12345))
+
+	//the code of another salt doesn't decode to the same id
+	id, err := b.Decode(a.Encode(12345))
+	if err == nil {
+		require.NotEqual(t, int64(12345), id)
+	}
+
+	x, err := NewBase62(random.AlphabetBase62, "salt-a")
+	require.NoError(t, err)
+	y, err := NewBase62(random.AlphabetBase62, "salt-b")
+	require.NoError(t, err)
+	require.NotEqual(t, x.Encode(12345), y.Encode(12345))
+}
+
+func TestCodecs_Invalid(t *testing.T) {
+	base62, err := NewBase62(random.AlphabetBase62, "secret")
+	require.NoError(t, err)
+	sqids, err := NewSqids(random.AlphabetBase62, "secret", 0)
+	require.NoError(t, err)
+
+	for _, codec := range []Codec{base62, sqids} {
+		for _, code := range []string{"", "with-dash", "zzzzzzzzzzzzzzzzzzzzzzzzzzzzzz"} {
+			_, err := codec.Decode(code)
+			require.ErrorIs(t, err, ErrInvalidCode, code)
+		}
+	}
+
+	//non-canonical codes with the zero digit in front are rejected
+	zero := base62.Encode(0)
+	_, err = base62.Decode(zero + base62.Encode(5))
+	require.ErrorIs(t, err, ErrInvalidCode)
+
+	_, err = NewSqids("ab", "", 0)
+	require.Error(t, err)
+}
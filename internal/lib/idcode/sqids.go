@@ -0,0 +1,97 @@
+package idcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sqids encodes ids with the Sqids algorithm (https://sqids.org) without its blocklist:
+// consecutive ids get unrelated looking codes, padded to minLength.
+type Sqids struct {
+	alphabet  string
+	minLength int
+}
+
+func NewSqids(alphabet, salt string, minLength int) (*Sqids, error) {
+	const op = "lib.idcode.NewSqids"
+
+	alphabet, err := saltedAlphabet(alphabet, salt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(alphabet) < 3 {
+		return nil, fmt.Errorf("%s: %w", op, errors.New("sqids alphabet must have at least 3 characters"))
+	}
+	if minLength < 0 || minLength > 255 {
+		return nil, fmt.Errorf("%s: %w", op, errors.New("min length must be between 0 and 255"))
+	}
+	return &Sqids{alphabet: shuffle(alphabet), minLength: minLength}, nil
+}
+
+func (s *Sqids) Encode(id int64) string {
+	n := uint64(id)
+	alphabet := s.alphabet
+
+	//one number: the offset is its digit in the alphabet plus the count of numbers
+	offset := (int(alphabet[n%uint64(len(alphabet))]) + 1) % len(alphabet)
+	alphabet = alphabet[offset:] + alphabet[:offset]
+	prefix := alphabet[0]
+	alphabet = reverse(alphabet)
+
+	code := string(prefix) + toCode(n, alphabet[1:])
+
+	if len(code) < s.minLength {
+		code += alphabet[:1]
+		for len(code) < s.minLength {
+			alphabet = shuffle(alphabet)
+			code += alphabet[:min(s.minLength-len(code), len(alphabet))]
+		}
+	}
+	return code
+}
+
+func (s *Sqids) Decode(code string) (int64, error) {
+	if len(code) < 2 {
+		return 0, ErrInvalidCode
+	}
+	offset := strings.IndexByte(s.alphabet, code[0])
+	if offset < 0 {
+		return 0, ErrInvalidCode
+	}
+	alphabet := s.alphabet[offset:] + s.alphabet[:offset]
+	alphabet = reverse(alphabet)
+
+	//the separator ends the number, the padding after it is ignored
+	chunk, _, _ := strings.Cut(code[1:], alphabet[:1])
+	if chunk == "" {
+		return 0, ErrInvalidCode
+	}
+	n, ok := toNumber(chunk, alphabet[1:])
+	if !ok || n > 1<<63-1 {
+		return 0, ErrInvalidCode
+	}
+	//every id has one canonical code
+	if s.Encode(int64(n)) != code {
+		return 0, ErrInvalidCode
+	}
+	return int64(n), nil
+}
+
+// shuffle is the deterministic alphabet shuffle of the Sqids spec
+func shuffle(alphabet string) string {
+	chars := []byte(alphabet)
+	for i, j := 0, len(chars)-1; j > 0; i, j = i+1, j-1 {
+		r := (i*j + int(chars[i]) + int(chars[j])) % len(chars)
+		chars[i], chars[r] = chars[r], chars[i]
+	}
+	return string(chars)
+}
+
+func reverse(s string) string {
+	chars := []byte(s)
+	for i, j := 0, len(chars)-1; i < j; i, j = i+1, j-1 {
+		chars[i], chars[j] = chars[j], chars[i]
+	}
+	return string(chars)
+}
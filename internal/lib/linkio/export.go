@@ -0,0 +1,78 @@
+package linkio
+
+import (
+	"short-url/internal/http-server/model/domain"
+)
+
+// exportPageSize is the number of links read from the storage at once
+const exportPageSize = 500
+
+type Lister interface {
+	ListLinks(query domain.LinkQuery) ([]domain.Link, error)
+	CountClicks(linkIDs []int64) (map[int64]int64, error)
+}
+
+// Export writes all links of the workspace ordered by creation and returns their number,
+// with clicks the total clicks of every link are written too. The writer is flushed after every page.
+func Export(st Lister, w *Writer, workspaceID int64, clicks bool) (int, error) {
+	query := domain.LinkQuery{
+		WorkspaceID: workspaceID,
+		SortBy:      domain.LinkSortCreatedAt,
+		Limit:       exportPageSize,
+	}
+
+	total := 0
+	for {
+		links, err := st.ListLinks(query)
+		if err != nil {
+			return total, err
+		}
+
+		var totals map[int64]int64
+		if clicks && len(links) > 0 {
+			ids := make([]int64, len(links))
+			for i, link := range links {
+				ids[i] = link.ID
+			}
+			if totals, err = st.CountClicks(ids); err != nil {
+				return total, err
+			}
+		}
+
+		for _, link := range links {
+			rec := NewRecord(link)
+			if clicks {
+				n := totals[link.ID]
+				rec.Clicks = &n
+			}
+			if err := w.Write(rec); err != nil {
+				return total, err
+			}
+		}
+		total += len(links)
+		if err := w.Flush(); err != nil {
+			return total, err
+		}
+
+		if len(links) < exportPageSize {
+			return total, nil
+		}
+		last := links[len(links)-1]
+		query.After = &domain.LinkCursor{ID: last.ID, Alias: last.Alias, CreatedAt: last.CreatedAt}
+	}
+}
+
+// NewRecord returns the record of an exported link
+func NewRecord(link domain.Link) Record {
+	createdAt, updatedAt := link.CreatedAt, link.UpdatedAt
+	return Record{
+		ID:        link.ID,
+		Alias:     link.Alias,
+		URL:       link.URL,
+		Domain:    link.Domain,
+		ExpiresAt: link.ExpiresAt,
+		Disabled:  link.Disabled,
+		CreatedAt: &createdAt,
+		UpdatedAt: &updatedAt,
+	}
+}
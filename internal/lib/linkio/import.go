@@ -0,0 +1,229 @@
+package linkio
+
+import (
+	"errors"
+	"io"
+	"short-url/internal/http-server/handlers/url/save"
+	"short-url/internal/http-server/model/domain"
+	responseModel "short-url/internal/http-server/model/response"
+	"short-url/internal/storage"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// what happens to the rows whose alias is taken
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	// nothing is imported if a row is invalid or taken
+	ConflictFail = "fail"
+)
+
+var ErrTooManyRows = errors.New("too many rows")
+
+type Importer interface {
+	SaveURLs(links []domain.Link, opts storage.SaveOptions) ([]storage.SaveResult, error)
+	UpdateLink(ref domain.LinkRef, update domain.LinkUpdate) (domain.Link, error)
+}
+
+type ImportOptions struct {
+	WorkspaceID int64
+	// key recorded as the creator of the links, nil for the root key
+	APIKeyID *int64
+	// skip, overwrite or fail
+	OnConflict string
+	// the rows are saved in a transaction that is rolled back, the summary tells what would be imported
+	DryRun bool
+	// rows saved in one transaction, dry runs and imports failing on conflicts use one transaction
+	ChunkSize int
+	// 0 is unlimited
+	MaxRows int
+}
+
+// Summary is the report of an import
+type Summary struct {
+	Rows    int  `json:"rows"`
+	Created int  `json:"created"`
+	Updated int  `json:"updated"`
+	Skipped int  `json:"skipped"`
+	Failed  int  `json:"failed"`
+	DryRun  bool `json:"dry_run"`
+	// nothing is imported because a row failed with the fail conflict policy
+	Aborted bool `json:"aborted"`
+	// rows that weren't imported
+	Errors []RowError `json:"errors"`
+}
+
+type RowError struct {
+	Line  int    `json:"line"`
+	Alias string `json:"alias,omitempty"`
+	Error string `json:"error"`
+}
+
+func (s *Summary) fail(line int, alias string, msg string) {
+	s.Failed++
+	s.Errors = append(s.Errors, RowError{Line: line, Alias: alias, Error: msg})
+}
+
+// row is a valid record waiting to be saved
+type row struct {
+	line int
+	link domain.Link
+}
+
+// Import validates the records like POST /url does and saves the valid ones in the workspace,
+// invalid and rejected rows are reported in the summary. Returns ErrTooManyRows once opts.MaxRows is exceeded,
+// nothing is saved then. Storage errors stop the import, the chunks saved before stay saved.
+func Import(st Importer, r *Reader, opts ImportOptions) (Summary, error) {
+	sum := Summary{DryRun: opts.DryRun, Errors: []RowError{}}
+
+	var rows []row
+	now := time.Now()
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var recErr *RecordError
+		if err != nil && !errors.As(err, &recErr) {
+			return sum, err
+		}
+		sum.Rows++
+		if opts.MaxRows > 0 && sum.Rows > opts.MaxRows {
+			return sum, ErrTooManyRows
+		}
+		if recErr != nil {
+			sum.fail(recErr.Line, "", recErr.Err.Error())
+			continue
+		}
+
+		link, err := validate(rec, opts.WorkspaceID, now)
+		if err != nil {
+			sum.fail(r.Line(), rec.Alias, err.Error())
+			continue
+		}
+		link.APIKeyID = opts.APIKeyID
+		rows = append(rows, row{line: r.Line(), link: link})
+	}
+
+	if opts.OnConflict == ConflictFail {
+		return sum, importAll(st, rows, opts.DryRun, &sum)
+	}
+
+	chunkSize := opts.ChunkSize
+	if opts.DryRun || chunkSize <= 0 {
+		chunkSize = len(rows)
+	}
+	for start := 0; start < len(rows); start += chunkSize {
+		chunk := rows[start:min(start+chunkSize, len(rows))]
+
+		results, err := st.SaveURLs(links(chunk), storage.SaveOptions{DryRun: opts.DryRun})
+		if err != nil {
+			return sum, err
+		}
+		for i, res := range results {
+			link := chunk[i].link
+			switch {
+			case res.Err == nil:
+				sum.Created++
+			case errors.Is(res.Err, storage.ErrURLExists) && opts.OnConflict == ConflictOverwrite:
+				if !opts.DryRun {
+					if _, err := st.UpdateLink(linkRef(link), overwrite(link)); err != nil {
+						return sum, err
+					}
+				}
+				sum.Updated++
+			case errors.Is(res.Err, storage.ErrURLExists):
+				sum.Skipped++
+			default:
+				sum.fail(chunk[i].line, link.Alias, rejectionMessage(res.Err))
+			}
+		}
+	}
+	return sum, nil
+}
+
+// importAll saves all the rows in one transaction, nothing is saved if a row is invalid or rejected
+func importAll(st Importer, rows []row, dryRun bool, sum *Summary) error {
+	if sum.Failed > 0 {
+		sum.Aborted = true
+		return nil
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	results, err := st.SaveURLs(links(rows), storage.SaveOptions{Atomic: true, DryRun: dryRun})
+	if err != nil {
+		return err
+	}
+	for i, res := range results {
+		if res.Err != nil {
+			sum.fail(rows[i].line, rows[i].link.Alias, rejectionMessage(res.Err))
+			sum.Aborted = true
+			return nil
+		}
+	}
+	sum.Created = len(rows)
+	return nil
+}
+
+// validate builds the link of the record with the validation of POST /url, imported links keep their alias
+func validate(rec Record, workspaceID int64, now time.Time) (domain.Link, error) {
+	if rec.Alias == "" {
+		return domain.Link{}, errors.New("field Alias is a required field")
+	}
+	req := save.Request{
+		URL:       rec.URL,
+		Alias:     rec.Alias,
+		ExpiresAt: rec.ExpiresAt,
+		Domain:    rec.Domain,
+	}
+	link, err := req.Link(workspaceID, now)
+	var validErrs validator.ValidationErrors
+	if errors.As(err, &validErrs) {
+		return domain.Link{}, errors.New(responseModel.ValidationError(validErrs).Error)
+	}
+	if err != nil {
+		return domain.Link{}, err
+	}
+	link.Disabled = rec.Disabled
+	return link, nil
+}
+
+// overwrite is the update replacing the link with the same alias
+func overwrite(link domain.Link) domain.LinkUpdate {
+	return domain.LinkUpdate{
+		URL:          &link.URL,
+		ExpiresAt:    link.ExpiresAt,
+		NeverExpires: link.ExpiresAt == nil,
+		Disabled:     &link.Disabled,
+	}
+}
+
+func linkRef(link domain.Link) domain.LinkRef {
+	return domain.LinkRef{WorkspaceID: link.WorkspaceID, Domain: link.Domain, Alias: link.Alias}
+}
+
+func links(rows []row) []domain.Link {
+	res := make([]domain.Link, len(rows))
+	for i, r := range rows {
+		res[i] = r.link
+	}
+	return res
+}
+
+// rejectionMessage returns the error POST /url answers a rejected link with
+func rejectionMessage(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrURLExists):
+		return "url already exists"
+	case errors.Is(err, storage.ErrDomainNotFound):
+		return "domain not found or not verified"
+	case errors.Is(err, storage.ErrQuotaExceeded):
+		return "monthly link quota exceeded"
+	default:
+		return "failed to add url"
+	}
+}
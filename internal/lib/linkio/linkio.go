@@ -0,0 +1,274 @@
+package linkio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+var (
+	ErrUnknownFormat = errors.New("format must be csv or ndjson")
+	// ErrMalformed is a file that can't be read further, like a CSV row with a bare quote
+	ErrMalformed = errors.New("malformed file")
+)
+
+// Record is a link of an export or import, its fields are the columns of the CSV files.
+// The id, timestamps and clicks of exports are ignored on import.
+type Record struct {
+	ID        int64      `json:"id,omitempty"`
+	Alias     string     `json:"alias"`
+	URL       string     `json:"url"`
+	Domain    string     `json:"domain,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Disabled  bool       `json:"disabled"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// total clicks, exported on request
+	Clicks *int64 `json:"clicks,omitempty"`
+}
+
+var columns = []string{"id", "alias", "url", "domain", "expires_at", "disabled", "created_at", "updated_at", "clicks"}
+
+// ContentType returns the media type of the format
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+// Writer writes records as CSV rows with a header or as NDJSON lines
+type Writer struct {
+	csv    *csv.Writer
+	ndjson *bufio.Writer
+	clicks bool
+	header bool
+}
+
+// NewWriter returns ErrUnknownFormat for formats other than csv and ndjson.
+// The clicks column is only written with clicks.
+func NewWriter(w io.Writer, format string, clicks bool) (*Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &Writer{csv: csv.NewWriter(w), clicks: clicks}, nil
+	case FormatNDJSON:
+		return &Writer{ndjson: bufio.NewWriter(w), clicks: clicks}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func (w *Writer) Write(rec Record) error {
+	if !w.clicks {
+		rec.Clicks = nil
+	}
+	if w.ndjson != nil {
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		_, err = w.ndjson.Write(append(data, '\n'))
+		return err
+	}
+
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	row := []string{
+		strconv.FormatInt(rec.ID, 10),
+		rec.Alias,
+		rec.URL,
+		rec.Domain,
+		formatTime(rec.ExpiresAt),
+		strconv.FormatBool(rec.Disabled),
+		formatTime(rec.CreatedAt),
+		formatTime(rec.UpdatedAt),
+	}
+	if w.clicks {
+		var clicks int64
+		if rec.Clicks != nil {
+			clicks = *rec.Clicks
+		}
+		row = append(row, strconv.FormatInt(clicks, 10))
+	}
+	return w.csv.Write(row)
+}
+
+// Flush writes the buffered records, the CSV header is written even if there are none
+func (w *Writer) Flush() error {
+	if w.ndjson != nil {
+		return w.ndjson.Flush()
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *Writer) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+	cols := columns
+	if !w.clicks {
+		cols = columns[:len(columns)-1]
+	}
+	return w.csv.Write(cols)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// RecordError is an invalid record, the next ones may still be read
+type RecordError struct {
+	// line of the record, 1 is the CSV header
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads the records of a CSV file with a header (alias and url columns are required,
+// unknown ones are ignored) or of NDJSON lines
+type Reader struct {
+	csv    *csv.Reader
+	ndjson *bufio.Scanner
+	// column indexes of the CSV file by name
+	index map[string]int
+	line  int
+}
+
+// NewReader returns ErrUnknownFormat for formats other than csv and ndjson,
+// the CSV header is read right away.
+func NewReader(r io.Reader, format string) (*Reader, error) {
+	switch format {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		header, err := cr.Read()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: csv header is missing", ErrMalformed)
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		index := make(map[string]int, len(header))
+		for i, name := range header {
+			index[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		for _, name := range []string{"alias", "url"} {
+			if _, ok := index[name]; !ok {
+				return nil, fmt.Errorf("%w: csv header has no %s column", ErrMalformed, name)
+			}
+		}
+		return &Reader{csv: cr, index: index, line: 1}, nil
+	case FormatNDJSON:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &Reader{ndjson: sc}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// Read returns the next record and io.EOF after the last one.
+// Invalid records are reported as *RecordError, other errors are fatal.
+func (r *Reader) Read() (Record, error) {
+	if r.ndjson != nil {
+		return r.readNDJSON()
+	}
+	return r.readCSV()
+}
+
+// Line returns the line of the last record read
+func (r *Reader) Line() int {
+	return r.line
+}
+
+func (r *Reader) readNDJSON() (Record, error) {
+	for r.ndjson.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.ndjson.Text())
+		if line == "" {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return Record{}, &RecordError{Line: r.line, Err: errors.New("invalid json")}
+		}
+		return rec, nil
+	}
+	if err := r.ndjson.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return Record{}, fmt.Errorf("%w: line %d is too long", ErrMalformed, r.line+1)
+	} else if err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+func (r *Reader) readCSV() (Record, error) {
+	row, err := r.csv.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Record{}, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	r.line, _ = r.csv.FieldPos(0)
+
+	field := func(name string) string {
+		i, ok := r.index[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	rec := Record{
+		Alias:  field("alias"),
+		URL:    field("url"),
+		Domain: field("domain"),
+	}
+	if v := field("expires_at"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return Record{}, &RecordError{Line: r.line, Err: errors.New("field expires_at must be an RFC 3339 time")}
+		}
+		rec.ExpiresAt = &t
+	}
+	if v := field("disabled"); v != "" {
+		rec.Disabled, err = strconv.ParseBool(v)
+		if err != nil {
+			return Record{}, &RecordError{Line: r.line, Err: errors.New("field disabled must be true or false")}
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,244 @@
+package linkio_test
+
+import (
+	"bytes"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/lib/linkio"
+	"short-url/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memStorage keeps the links by alias
+type memStorage struct {
+	links   map[string]domain.Link
+	updated []string
+	chunks  []int
+}
+
+func newMemStorage(aliases ...string) *memStorage {
+	st := &memStorage{links: map[string]domain.Link{}}
+	for i, alias := range aliases {
+		st.links[alias] = domain.Link{ID: int64(i + 1), Alias: alias, URL: "https://old.example", CreatedAt: time.Now()}
+	}
+	return st
+}
+
+func (s *memStorage) SaveURLs(links []domain.Link, opts storage.SaveOptions) ([]storage.SaveResult, error) {
+	s.chunks = append(s.chunks, len(links))
+	saved := map[string]domain.Link{}
+	results := make([]storage.SaveResult, len(links))
+	for i, link := range links {
+		_, taken := s.links[link.Alias]
+		_, takenNow := saved[link.Alias]
+		if taken || takenNow {
+			if opts.Atomic {
+				results = make([]storage.SaveResult, len(links))
+				results[i].Err = storage.ErrURLExists
+				return results, nil
+			}
+			results[i].Err = storage.ErrURLExists
+			continue
+		}
+		link.ID = int64(len(s.links) + len(saved) + 1)
+		saved[link.Alias] = link
+		results[i].ID = link.ID
+	}
+	if !opts.DryRun {
+		for alias, link := range saved {
+			s.links[alias] = link
+		}
+	}
+	return results, nil
+}
+
+func (s *memStorage) UpdateLink(ref domain.LinkRef, update domain.LinkUpdate) (domain.Link, error) {
+	link, ok := s.links[ref.Alias]
+	if !ok {
+		return domain.Link{}, storage.ErrURLNotFound
+	}
+	link.Apply(update)
+	s.links[ref.Alias] = link
+	s.updated = append(s.updated, ref.Alias)
+	return link, nil
+}
+
+func (s *memStorage) ListLinks(query domain.LinkQuery) ([]domain.Link, error) {
+	if query.After != nil {
+		return nil, nil
+	}
+	var links []domain.Link
+	for _, link := range s.links {
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func (s *memStorage) CountClicks(linkIDs []int64) (map[int64]int64, error) {
+	totals := map[int64]int64{}
+	for _, id := range linkIDs {
+		totals[id] = id * 10
+	}
+	return totals, nil
+}
+
+const importCSV = `alias,url,expires_at,disabled,clicks
+new,https://new.example,,true,3
+taken,https://taken.example,,,
+,https://no-alias.example,,,
+bad,not a url,,,
+late,https://late.example,2000-01-01T00:00:00Z,,
+time,https://time.example,tomorrow,,
+`
+
+func TestImport(t *testing.T) {
+	cases := []struct {
+		name       string
+		onConflict string
+		dryRun     bool
+		want       linkio.Summary
+		saved      []string
+		updated    []string
+	}{
+		{
+			name:       "Skip",
+			onConflict: linkio.ConflictSkip,
+			want:       linkio.Summary{Rows: 6, Created: 1, Skipped: 1, Failed: 4},
+			saved:      []string{"new"},
+		},
+		{
+			name:       "Overwrite",
+			onConflict: linkio.ConflictOverwrite,
+			want:       linkio.Summary{Rows: 6, Created: 1, Updated: 1, Failed: 4},
+			saved:      []string{"new"},
+			updated:    []string{"taken"},
+		},
+		{
+			name:       "Dry run",
+			onConflict: linkio.ConflictOverwrite,
+			dryRun:     true,
+			want:       linkio.Summary{Rows: 6, Created: 1, Updated: 1, Failed: 4, DryRun: true},
+		},
+		{
+			name:       "Fail",
+			onConflict: linkio.ConflictFail,
+			want:       linkio.Summary{Rows: 6, Failed: 4, Aborted: true},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			st := newMemStorage("taken")
+
+			r, err := linkio.NewReader(strings.NewReader(importCSV), linkio.FormatCSV)
+			require.NoError(t, err)
+
+			sum, err := linkio.Import(st, r, linkio.ImportOptions{
+				WorkspaceID: domain.DefaultWorkspaceID,
+				OnConflict:  tc.onConflict,
+				DryRun:      tc.dryRun,
+				ChunkSize:   100,
+			})
+			require.NoError(t, err)
+
+			require.Len(t, sum.Errors, tc.want.Failed)
+			sum.Errors = nil
+			require.Equal(t, tc.want, sum)
+
+			for _, alias := range tc.saved {
+				require.Contains(t, st.links, alias)
+			}
+			require.Len(t, st.links, 1+len(tc.saved))
+			require.Equal(t, tc.updated, st.updated)
+			if len(tc.updated) > 0 {
+				require.Equal(t, "https://taken.example", st.links["taken"].URL)
+			}
+			if len(tc.saved) > 0 {
+				require.True(t, st.links["new"].Disabled)
+			}
+		})
+	}
+}
+
+func TestImport_Errors(t *testing.T) {
+	st := newMemStorage()
+
+	r, err := linkio.NewReader(strings.NewReader(importCSV), linkio.FormatCSV)
+	require.NoError(t, err)
+	sum, err := linkio.Import(st, r, linkio.ImportOptions{OnConflict: linkio.ConflictSkip})
+	require.NoError(t, err)
+
+	require.Equal(t, []linkio.RowError{
+		{Line: 4, Error: "field Alias is a required field"},
+		{Line: 5, Alias: "bad", Error: "invalid body,field URL is not in URL format"},
+		{Line: 6, Alias: "late", Error: "field expires_at must be in the future"},
+		{Line: 7, Error: "field expires_at must be an RFC 3339 time"},
+	}, sum.Errors)
+
+	r, err = linkio.NewReader(strings.NewReader(importCSV), linkio.FormatCSV)
+	require.NoError(t, err)
+	_, err = linkio.Import(st, r, linkio.ImportOptions{OnConflict: linkio.ConflictSkip, MaxRows: 5})
+	require.ErrorIs(t, err, linkio.ErrTooManyRows)
+}
+
+func TestImport_Chunks(t *testing.T) {
+	st := newMemStorage()
+
+	ndjson := strings.Repeat(`{"alias":"a","url":"https://a.example"}`+"\n", 5)
+	r, err := linkio.NewReader(strings.NewReader(ndjson), linkio.FormatNDJSON)
+	require.NoError(t, err)
+	sum, err := linkio.Import(st, r, linkio.ImportOptions{OnConflict: linkio.ConflictSkip, ChunkSize: 2})
+	require.NoError(t, err)
+
+	require.Equal(t, []int{2, 2, 1}, st.chunks)
+	require.Equal(t, 1, sum.Created)
+	require.Equal(t, 4, sum.Skipped)
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	for _, format := range []string{linkio.FormatCSV, linkio.FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+			st := newMemStorage()
+			st.links["a"] = domain.Link{ID: 1, Alias: "a", URL: "https://a.example", Domain: "go.example.com", ExpiresAt: &expiresAt, Disabled: true}
+
+			var buf bytes.Buffer
+			w, err := linkio.NewWriter(&buf, format, true)
+			require.NoError(t, err)
+			n, err := linkio.Export(st, w, domain.DefaultWorkspaceID, true)
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+			require.Contains(t, buf.String(), "10")
+
+			r, err := linkio.NewReader(&buf, format)
+			require.NoError(t, err)
+			rec, err := r.Read()
+			require.NoError(t, err)
+			require.Equal(t, "a", rec.Alias)
+			require.Equal(t, "https://a.example", rec.URL)
+			require.Equal(t, "go.example.com", rec.Domain)
+			require.True(t, expiresAt.Equal(*rec.ExpiresAt))
+			require.True(t, rec.Disabled)
+		})
+	}
+}
+
+func TestReader_Errors(t *testing.T) {
+	_, err := linkio.NewReader(strings.NewReader(""), "xml")
+	require.ErrorIs(t, err, linkio.ErrUnknownFormat)
+
+	_, err = linkio.NewReader(strings.NewReader("id,url\n"), linkio.FormatCSV)
+	require.ErrorIs(t, err, linkio.ErrMalformed)
+	require.EqualError(t, err, "malformed file: csv header has no alias column")
+
+	r, err := linkio.NewReader(strings.NewReader("alias,url\na,\"https://a.example\n"), linkio.FormatCSV)
+	require.NoError(t, err)
+	_, err = r.Read()
+	require.ErrorIs(t, err, linkio.ErrMalformed)
+}
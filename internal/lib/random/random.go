@@ -1,17 +1,75 @@
 package random
 
 import (
-	"math/rand"
-	"time"
+	"crypto/rand"
+	"errors"
+	"fmt"
 )
 
-const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+const (
+	// AlphabetBase62 is the default alphabet of the aliases
+	AlphabetBase62 = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	// AlphabetUnambiguous leaves out the look-alike characters 0/O/o, 1/l/I
+	AlphabetUnambiguous = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	// AlphabetLowercase is for aliases read out loud or typed on phones
+	AlphabetLowercase = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+var ErrInvalidAlphabet = errors.New("alphabet must have 2 to 128 unique ascii characters")
+
+// ValidateAlphabet returns ErrInvalidAlphabet if the alphabet can't be used by NewString
+func ValidateAlphabet(alphabet string) error {
+	if len(alphabet) < 2 || len(alphabet) > 128 {
+		return ErrInvalidAlphabet
+	}
+	var seen [128]bool
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if c > 127 || seen[c] {
+			return ErrInvalidAlphabet
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// NewString returns a random string of the alphabet read from crypto/rand,
+// every character is equally likely.
+func NewString(alphabet string, length int) (string, error) {
+	const op = "lib.random.NewString"
+
+	if err := ValidateAlphabet(alphabet); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	//bytes above the largest multiple of the alphabet size are rejected, so no character is favoured
+	n := len(alphabet)
+	limit := 256 - 256%n
+
+	result := make([]byte, 0, length)
+	buf := make([]byte, length+length/2)
+	for len(result) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			result = append(result, alphabet[int(b)%n])
+			if len(result) == length {
+				break
+			}
+		}
+	}
+	return string(result), nil
+}
 
+// NewRandomString returns a random base62 string, it panics if crypto/rand fails.
 func NewRandomString(length int) string {
-	seededRand := rand.New(rand.NewSource(time.Now().UnixNano()))
-	result := make([]byte, length)
-	for i := range result {
-		result[i] = charset[seededRand.Intn(len(charset))]
+	s, err := NewString(AlphabetBase62, length)
+	if err != nil {
+		panic(err)
 	}
-	return string(result)
+	return s
 }
@@ -0,0 +1,54 @@
+package random
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewString(t *testing.T) {
+	for _, alphabet := range []string{AlphabetBase62, AlphabetUnambiguous, AlphabetLowercase, "ab"} {
+		s, err := NewString(alphabet, 64)
+		require.NoError(t, err)
+		require.Len(t, s, 64)
+		for _, c := range s {
+			require.True(t, strings.ContainsRune(alphabet, c), "%q isn't in %q", c, alphabet)
+		}
+	}
+
+	//every character of the alphabet shows up
+	s, err := NewString("abc", 300)
+	require.NoError(t, err)
+	for _, c := range "abc" {
+		require.Contains(t, s, string(c))
+	}
+
+	//two strings are practically never equal
+	a, err := NewString(AlphabetBase62, 16)
+	require.NoError(t, err)
+	b, err := NewString(AlphabetBase62, 16)
+	require.NoError(t, err)
+	require.NotEqual(t, a, b)
+}
+
+func TestValidateAlphabet(t *testing.T) {
+	require.NoError(t, ValidateAlphabet(AlphabetUnambiguous))
+	require.ErrorIs(t, ValidateAlphabet("a"), ErrInvalidAlphabet)
+	require.ErrorIs(t, ValidateAlphabet("abca"), ErrInvalidAlphabet)
+	require.ErrorIs(t, ValidateAlphabet("abcé"), ErrInvalidAlphabet)
+
+	//every ascii character once is the longest alphabet
+	var ascii []byte
+	for c := 0; c < 128; c++ {
+		ascii = append(ascii, byte(c))
+	}
+	require.NoError(t, ValidateAlphabet(string(ascii)))
+	require.ErrorIs(t, ValidateAlphabet(string(ascii)+"a"), ErrInvalidAlphabet)
+	s, err := NewString(string(ascii), 6)
+	require.NoError(t, err)
+	require.Len(t, s, 6)
+
+	_, err = NewString("a", 6)
+	require.ErrorIs(t, err, ErrInvalidAlphabet)
+}
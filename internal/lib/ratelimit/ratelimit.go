@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Rate tokens per second are refilled up to Burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerPeriod allows requests per period with bursts of up to burst requests,
+// the limit is disabled if requests or period isn't positive.
+func PerPeriod(requests int, period time.Duration, burst int) Limit {
+	if requests <= 0 || period <= 0 {
+		return Limit{}
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return Limit{Rate: float64(requests) / period.Seconds(), Burst: burst}
+}
+
+// Enabled reports whether the limit allows a finite rate, zero limits disable limiting
+func (l Limit) Enabled() bool {
+	return l.Rate > 0
+}
+
+// Result of taking a token
+type Result struct {
+	Allowed bool
+	// time until the next token is refilled, set if the request isn't allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. MemoryStore keeps them in the process,
+// a store shared by the replicas makes the limits global.
+type Store interface {
+	// Take removes a token from the bucket of key
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// the bucket is refilled to the burst by then and can be dropped
+	fullAt time.Time
+}
+
+// MemoryStore is a Store local to the process
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	// buckets are swept every sweepEvery takes
+	takes int
+}
+
+const sweepEvery = 10000
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.takes++
+	if s.takes%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updatedAt).Seconds()*limit.Rate)
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		return Result{RetryAfter: refillTime(1-b.tokens, limit)}, nil
+	}
+	b.tokens--
+	b.fullAt = now.Add(refillTime(float64(limit.Burst)-b.tokens, limit))
+	return Result{Allowed: true}, nil
+}
+
+func refillTime(tokens float64, limit Limit) time.Duration {
+	return time.Duration(tokens / limit.Rate * float64(time.Second))
+}
+
+// sweep drops the buckets refilled to the full burst, they are the same as new ones
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	//2 requests per second with bursts of 3
+	limit := PerPeriod(2, time.Second, 3)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		res, err := s.Take(ctx, "a", limit)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	}
+	res, err := s.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	//buckets are independent
+	res, err = s.Take(ctx, "b", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	//a token is refilled every 500ms
+	now = now.Add(500 * time.Millisecond)
+	res, err = s.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	res, err = s.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+
+	//the bucket never holds more than the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		res, err = s.Take(ctx, "a", limit)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	}
+	res, err = s.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	limit := PerPeriod(1, time.Second, 1)
+
+	_, err := s.Take(context.Background(), "a", limit)
+	require.NoError(t, err)
+	_, err = s.Take(context.Background(), "b", PerPeriod(1, time.Minute, 1))
+	require.NoError(t, err)
+
+	//the bucket of the slower limit isn't refilled yet
+	now = now.Add(time.Second)
+	s.sweep(now)
+	require.Len(t, s.buckets, 1)
+	require.Contains(t, s.buckets, "b")
+}
+
+func TestPerPeriod_Disabled(t *testing.T) {
+	require.False(t, PerPeriod(0, time.Minute, 10).Enabled())
+	require.False(t, PerPeriod(10, 0, 10).Enabled())
+	require.True(t, PerPeriod(10, time.Minute, 0).Enabled())
+}
@@ -0,0 +1,29 @@
+package shorturl
+
+import (
+	"net/url"
+	"short-url/internal/http-server/model/domain"
+	"strings"
+)
+
+// Builder makes the fully-qualified short urls of links
+type Builder struct {
+	// base url of the default domain, e.g. https://sho.rt
+	DefaultURL string
+	// scheme of the custom domains
+	Scheme string
+}
+
+// Build returns the short url of the link: on its custom domain, or on the default domain
+// where the aliases of other workspaces than the default one are under /w/{slug}/.
+func (b Builder) Build(link domain.Link, workspaceSlug string) string {
+	alias := url.PathEscape(link.Alias)
+	if link.Domain != "" {
+		return b.Scheme + "://" + link.Domain + "/" + alias
+	}
+	base := strings.TrimRight(b.DefaultURL, "/")
+	if link.WorkspaceID != domain.DefaultWorkspaceID {
+		base += "/w/" + workspaceSlug
+	}
+	return base + "/" + alias
+}
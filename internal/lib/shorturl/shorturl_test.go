@@ -0,0 +1,21 @@
+package shorturl
+
+import (
+	"short-url/internal/http-server/model/domain"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	b := Builder{DefaultURL: "http://localhost:9000/", Scheme: "https"}
+
+	require.Equal(t, "http://localhost:9000/ex",
+		b.Build(domain.Link{WorkspaceID: domain.DefaultWorkspaceID, Alias: "ex"}, "default"))
+	require.Equal(t, "http://localhost:9000/w/teamb/ex",
+		b.Build(domain.Link{WorkspaceID: 2, Alias: "ex"}, "teamb"))
+	require.Equal(t, "https://go.example/ex",
+		b.Build(domain.Link{WorkspaceID: 2, Domain: "go.example", Alias: "ex"}, "teamb"))
+	require.Equal(t, "https://go.example/a%20b",
+		b.Build(domain.Link{WorkspaceID: 2, Domain: "go.example", Alias: "a b"}, "teamb"))
+}
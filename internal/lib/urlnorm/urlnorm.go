@@ -0,0 +1,48 @@
+package urlnorm
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalize returns the form of the url links are deduplicated by: lowercase scheme and host,
+// no default port, "/" for an empty path and query params sorted by name.
+// Urls that can't be parsed are returned as is.
+func Normalize(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Opaque != "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Host)
+	if h, port, err := net.SplitHostPort(host); err == nil && defaultPorts[u.Scheme] == port {
+		host = h
+		//the brackets of ipv6 addresses are dropped by SplitHostPort
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+	}
+	u.Host = host
+
+	if u.Path == "" && u.Host != "" {
+		u.Path = "/"
+	}
+
+	//values of the same name keep their order, it can be meaningful
+	if u.RawQuery != "" {
+		query, err := url.ParseQuery(u.RawQuery)
+		if err == nil {
+			u.RawQuery = query.Encode()
+		}
+	}
+	u.ForceQuery = false
+
+	return u.String()
+}
@@ -0,0 +1,75 @@
+package urlnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "Already normalized",
+			url:  "https://example.com/path?a=1&b=2",
+			want: "https://example.com/path?a=1&b=2",
+		},
+		{
+			name: "Scheme and host case",
+			url:  "HTTPS://Example.COM/Path",
+			want: "https://example.com/Path",
+		},
+		{
+			name: "Default port",
+			url:  "http://example.com:80/a",
+			want: "http://example.com/a",
+		},
+		{
+			name: "Default port of https",
+			url:  "https://example.com:443",
+			want: "https://example.com/",
+		},
+		{
+			name: "Other port",
+			url:  "https://example.com:8443/a",
+			want: "https://example.com:8443/a",
+		},
+		{
+			name: "IPv6 default port",
+			url:  "http://[::1]:80/a",
+			want: "http://[::1]/a",
+		},
+		{
+			name: "Sorted query",
+			url:  "https://example.com/?b=2&a=1&a=0",
+			want: "https://example.com/?a=1&a=0&b=2",
+		},
+		{
+			name: "Empty query",
+			url:  "https://example.com/?",
+			want: "https://example.com/",
+		},
+		{
+			name: "Fragment",
+			url:  "https://example.com/a?z=1&y=2#Top",
+			want: "https://example.com/a?y=2&z=1#Top",
+		},
+		{
+			name: "Unparsable",
+			url:  "http://[::1",
+			want: "http://[::1",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.want, Normalize(tc.url))
+		})
+	}
+}
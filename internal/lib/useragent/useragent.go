@@ -0,0 +1,50 @@
+package useragent
+
+import "strings"
+
+const (
+	BrowserBot     = "bot"
+	BrowserEdge    = "edge"
+	BrowserOpera   = "opera"
+	BrowserSamsung = "samsung"
+	BrowserChrome  = "chrome"
+	BrowserFirefox = "firefox"
+	BrowserSafari  = "safari"
+	BrowserOther   = "other"
+)
+
+// tokens are checked in order, because most browsers also mention the engines of the others
+var browserTokens = []struct {
+	token   string
+	browser string
+}{
+	{"bot", BrowserBot},
+	{"crawler", BrowserBot},
+	{"spider", BrowserBot},
+	{"edg/", BrowserEdge},
+	{"edga/", BrowserEdge},
+	{"edgios/", BrowserEdge},
+	{"opr/", BrowserOpera},
+	{"opera", BrowserOpera},
+	{"samsungbrowser/", BrowserSamsung},
+	{"firefox/", BrowserFirefox},
+	{"fxios/", BrowserFirefox},
+	{"crios/", BrowserChrome},
+	{"chrome/", BrowserChrome},
+	{"chromium/", BrowserChrome},
+	{"safari/", BrowserSafari},
+}
+
+// Browser returns the browser family of the User-Agent header, empty for an empty header.
+func Browser(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+	ua := strings.ToLower(userAgent)
+	for _, t := range browserTokens {
+		if strings.Contains(ua, t.token) {
+			return t.browser
+		}
+	}
+	return BrowserOther
+}
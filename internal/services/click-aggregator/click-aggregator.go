@@ -0,0 +1,108 @@
+package clickaggregator
+
+import (
+	"context"
+	"log/slog"
+	"short-url/internal/config"
+	"short-url/internal/lib/sl"
+	"time"
+)
+
+type ClickStorage interface {
+	RollupClicks(to time.Time) (time.Time, error)
+	PurgeClicks(before time.Time, limit int) (int, error)
+}
+
+// Aggregator periodically rolls raw clicks up into the hourly and daily rollups
+// and deletes the raw clicks older than the retention window.
+type Aggregator struct {
+	storage ClickStorage
+	log     *slog.Logger
+	cfg     config.ClickAggregator
+	done    chan struct{}
+}
+
+func New(storage ClickStorage, log *slog.Logger, cfg config.ClickAggregator) *Aggregator {
+	return &Aggregator{
+		storage: storage,
+		log:     log,
+		cfg:     cfg,
+		done:    make(chan struct{}),
+	}
+}
+
+func (a *Aggregator) StartAggregate(ctx context.Context) {
+	const op = "click-aggregator.StartAggregate"
+	log := a.log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(a.cfg.Period)
+
+	go func() {
+		defer close(a.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("context done, stopping click aggregator")
+				return
+			case <-ticker.C:
+			}
+			a.aggregate(ctx, time.Now())
+		}
+	}()
+}
+
+// Wait blocks until the aggregator stops after ctx of StartAggregate is done.
+func (a *Aggregator) Wait() {
+	<-a.done
+}
+
+// aggregate rolls up the clicks of the hours finished before the lag, then applies the retention.
+// The rollup never revisits an hour, so the lag keeps it behind the clicks still buffered by the tracker.
+func (a *Aggregator) aggregate(ctx context.Context, now time.Time) {
+	const op = "click-aggregator.aggregate"
+	log := a.log.With(slog.String("op", op))
+
+	rolledUpTo, err := a.storage.RollupClicks(now.Add(-a.cfg.Lag))
+	if err != nil {
+		log.Error("error rolling up clicks", sl.Err(err))
+		return
+	}
+	if rolledUpTo.IsZero() {
+		return
+	}
+
+	purged := a.purge(ctx, retentionCutoff(now, a.cfg.Retention, rolledUpTo))
+	if purged > 0 {
+		log.Info("raw clicks purged", slog.Int("count", purged))
+	}
+}
+
+// purge deletes raw clicks older than before batch by batch until none are left.
+func (a *Aggregator) purge(ctx context.Context, before time.Time) int {
+	const op = "click-aggregator.purge"
+
+	total := 0
+	for ctx.Err() == nil {
+		n, err := a.storage.PurgeClicks(before, a.cfg.BatchSize)
+		if err != nil {
+			a.log.Error("error purging raw clicks", slog.String("op", op), sl.Err(err))
+			return total
+		}
+		total += n
+		if n < a.cfg.BatchSize {
+			return total
+		}
+	}
+	return total
+}
+
+// retentionCutoff keeps the raw clicks of the retention window and of the day being rolled up,
+// because its daily rollup is recomputed from them until the day is over.
+func retentionCutoff(now time.Time, retention time.Duration, rolledUpTo time.Time) time.Time {
+	cutoff := now.UTC().Add(-retention)
+	if day := rolledUpTo.UTC().Truncate(24 * time.Hour); day.Before(cutoff) {
+		return day
+	}
+	return cutoff
+}
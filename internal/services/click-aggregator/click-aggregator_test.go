@@ -0,0 +1,69 @@
+package clickaggregator
+
+import (
+	"context"
+	"short-url/internal/config"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memStorage struct {
+	rolledUpTo time.Time
+	clicks     []time.Time
+	calls      int
+}
+
+func (s *memStorage) RollupClicks(to time.Time) (time.Time, error) {
+	s.rolledUpTo = to.Truncate(time.Hour)
+	return s.rolledUpTo, nil
+}
+
+func (s *memStorage) PurgeClicks(before time.Time, limit int) (int, error) {
+	s.calls++
+	kept := s.clicks[:0]
+	n := 0
+	for _, c := range s.clicks {
+		if n < limit && c.Before(before) {
+			n++
+			continue
+		}
+		kept = append(kept, c)
+	}
+	s.clicks = kept
+	return n, nil
+}
+
+func TestAggregator_Retention(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+	st := &memStorage{}
+	for i := range 30 {
+		st.clicks = append(st.clicks, now.Add(-time.Duration(i)*24*time.Hour))
+	}
+	a := New(st, silentlog.NewSilentLogger(), config.ClickAggregator{
+		Lag:       time.Hour,
+		Retention: 7 * 24 * time.Hour,
+		BatchSize: 10,
+	})
+
+	a.aggregate(context.Background(), now)
+
+	//the hour before now is still open for late clicks
+	require.Equal(t, now.Add(-time.Hour).Truncate(time.Hour), st.rolledUpTo)
+	require.Len(t, st.clicks, 8)
+	require.Equal(t, 3, st.calls)
+}
+
+func TestRetentionCutoff(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+
+	//the retention window ends before the rolled up day
+	require.Equal(t, now.Add(-48*time.Hour), retentionCutoff(now, 48*time.Hour, now.Truncate(time.Hour)))
+
+	//raw clicks of a day that is still rolled up are kept
+	require.Equal(t,
+		time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		retentionCutoff(now, time.Hour, now.Truncate(time.Hour)))
+}
@@ -0,0 +1,141 @@
+package clicktracker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math/rand/v2"
+	"net/url"
+	"short-url/internal/config"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/lib/sl"
+	"short-url/internal/lib/useragent"
+	"strings"
+	"time"
+)
+
+type ClickStorage interface {
+	SaveClicks(clicks []domain.Click) error
+}
+
+// Tracker records redirects to the clicks table in the background,
+// so the redirect latency doesn't depend on the storage.
+type Tracker struct {
+	storage ClickStorage
+	log     *slog.Logger
+	cfg     config.ClickTracker
+	clicks  chan domain.Click
+	done    chan struct{}
+}
+
+func New(storage ClickStorage, log *slog.Logger, cfg config.ClickTracker) *Tracker {
+	return &Tracker{
+		storage: storage,
+		log:     log,
+		cfg:     cfg,
+		clicks:  make(chan domain.Click, cfg.BufferSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// Track never blocks, the click is dropped if the buffer is full.
+func (t *Tracker) Track(click domain.Click) {
+	//the raw ip is never stored, only its salted hash to count unique visitors
+	if click.IP != "" {
+		click.IPHash = t.hashIP(click.IP)
+		click.IP = ""
+	}
+	click.ReferrerHost = referrerHost(click.Referrer)
+	click.Browser = useragent.Browser(click.UserAgent)
+	//only a sample of clicks becomes url_clicked events
+	click.Sampled = t.cfg.EventSampleRate > 0 && rand.Float64() < t.cfg.EventSampleRate
+
+	select {
+	case t.clicks <- click:
+	default:
+		t.log.Warn("click buffer is full, click dropped", slog.String("alias", click.Alias))
+	}
+}
+
+// StartProcessClicks flushes tracked clicks every cfg.FlushInterval or when a batch is full.
+func (t *Tracker) StartProcessClicks(ctx context.Context) {
+	const op = "click-tracker.StartProcessClicks"
+	log := t.log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(t.cfg.FlushInterval)
+
+	go func() {
+		defer close(t.done)
+		defer ticker.Stop()
+
+		batch := make([]domain.Click, 0, t.cfg.BatchSize)
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("context done, stopping click tracker")
+				t.flushBuffered(batch)
+				return
+			case click := <-t.clicks:
+				batch = append(batch, click)
+				if len(batch) < t.cfg.BatchSize {
+					continue
+				}
+			case <-ticker.C:
+				if len(batch) == 0 {
+					continue
+				}
+			}
+			t.flush(batch)
+			batch = batch[:0]
+		}
+	}()
+}
+
+// Wait blocks until the tracker stops and flushes the buffered clicks.
+func (t *Tracker) Wait() {
+	<-t.done
+}
+
+// flushBuffered writes the batch and everything left in the buffer.
+func (t *Tracker) flushBuffered(batch []domain.Click) {
+	for {
+		select {
+		case click := <-t.clicks:
+			batch = append(batch, click)
+			if len(batch) == t.cfg.BatchSize {
+				t.flush(batch)
+				batch = batch[:0]
+			}
+		default:
+			if len(batch) > 0 {
+				t.flush(batch)
+			}
+			return
+		}
+	}
+}
+
+func (t *Tracker) flush(batch []domain.Click) {
+	const op = "click-tracker.flush"
+
+	if err := t.storage.SaveClicks(batch); err != nil {
+		t.log.Error("error saving clicks", slog.String("op", op), sl.Err(err), slog.Int("count", len(batch)))
+	}
+}
+
+func (t *Tracker) hashIP(ip string) string {
+	mac := hmac.New(sha256.New, []byte(t.cfg.IPHashSalt))
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// referrerHost returns the lowercased host of the Referer header, empty if it isn't a url
+func referrerHost(referrer string) string {
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,116 @@
+package clicktracker
+
+import (
+	"context"
+	"short-url/internal/config"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memStorage struct {
+	mu      sync.Mutex
+	batches [][]domain.Click
+}
+
+func (s *memStorage) SaveClicks(clicks []domain.Click) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]domain.Click(nil), clicks...))
+	return nil
+}
+
+func (s *memStorage) saved() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, b := range s.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func TestTracker_FlushBatches(t *testing.T) {
+	st := &memStorage{}
+	tr := New(st, silentlog.NewSilentLogger(), config.ClickTracker{
+		EventSampleRate: 1,
+		BufferSize:      10,
+		BatchSize:       2,
+		FlushInterval:   10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tr.StartProcessClicks(ctx)
+
+	for _, alias := range []string{"a", "b", "c"} {
+		tr.Track(domain.Click{Alias: alias, ClickedAt: time.Now()})
+	}
+
+	//two clicks are flushed as a full batch, the last one by the ticker
+	require.Eventually(t, func() bool { return st.saved() == 3 }, time.Second, 5*time.Millisecond)
+}
+
+func TestTracker_SamplingAndHashing(t *testing.T) {
+	tr := New(&memStorage{}, silentlog.NewSilentLogger(), config.ClickTracker{
+		EventSampleRate: 0,
+		BufferSize:      10,
+		IPHashSalt:      "salt",
+	})
+
+	tr.Track(domain.Click{
+		Alias:     "a",
+		IP:        "10.0.0.1",
+		Referrer:  "https://News.example.com/item?id=1",
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+	})
+	tr.Track(domain.Click{Alias: "b", IP: "10.0.0.1"})
+
+	a, b := <-tr.clicks, <-tr.clicks
+	require.False(t, a.Sampled)
+	require.Empty(t, a.IP)
+	require.NotEmpty(t, a.IPHash)
+	require.Equal(t, a.IPHash, b.IPHash)
+	require.NotEqual(t, "10.0.0.1", a.IPHash)
+	require.Equal(t, "news.example.com", a.ReferrerHost)
+	require.Equal(t, "firefox", a.Browser)
+	require.Empty(t, b.ReferrerHost)
+	require.Empty(t, b.Browser)
+}
+
+func TestTracker_FullBufferDoesNotBlock(t *testing.T) {
+	tr := New(&memStorage{}, silentlog.NewSilentLogger(), config.ClickTracker{
+		EventSampleRate: 1,
+		BufferSize:      1,
+	})
+
+	tr.Track(domain.Click{Alias: "a"})
+	tr.Track(domain.Click{Alias: "b"})
+
+	require.Equal(t, 1, len(tr.clicks))
+}
+
+func TestTracker_FlushOnStop(t *testing.T) {
+	st := &memStorage{}
+	tr := New(st, silentlog.NewSilentLogger(), config.ClickTracker{
+		EventSampleRate: 1,
+		BufferSize:      10,
+		BatchSize:       100,
+		FlushInterval:   time.Hour,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tr.StartProcessClicks(ctx)
+
+	tr.Track(domain.Click{Alias: "a"})
+	tr.Track(domain.Click{Alias: "b"})
+
+	cancel()
+	tr.Wait()
+
+	require.Equal(t, 2, st.saved())
+}
@@ -2,32 +2,70 @@ package eventsender
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"short-url/internal/config"
 	"short-url/internal/http-server/model/domain"
+	"short-url/internal/lib/backoff"
+	"short-url/internal/lib/sl"
 	"short-url/internal/storage"
-	"short-url/internal/storage/sqlite"
 	"time"
 )
 
+type EventStorage interface {
+	ClaimEvents(workerID string, limit int, lease time.Duration) ([]domain.Event, error)
+	MarkEventsAsDone(eventIDs []int) error
+	MarkEventFailed(eventID int, lastError string, nextAttemptAt time.Time) error
+	MarkEventDead(eventID int, lastError string) error
+}
+
+// Publisher delivers an event to another system, implementations are in the publisher package
+type Publisher interface {
+	Publish(ctx context.Context, event domain.CloudEvent) error
+}
+
 type Sender struct {
-	storage *sqlite.Storage
-	log     *slog.Logger
+	storage   EventStorage
+	publisher Publisher
+	log       *slog.Logger
+	workerID  string
+	cfg       config.EventSender
+	done      chan struct{}
 }
 
-func New(storage *sqlite.Storage, log *slog.Logger) *Sender {
+// New creates a sender that leases events for cfg.Lease,
+// after that the event returns to the pool and may be claimed by another replica.
+func New(storage EventStorage, publisher Publisher, log *slog.Logger, cfg config.EventSender) *Sender {
 	return &Sender{
-		storage: storage,
-		log:     log,
+		storage:   storage,
+		publisher: publisher,
+		log:       log,
+		workerID:  newWorkerID(),
+		cfg:       cfg,
+		done:      make(chan struct{}),
+	}
+}
+
+// newWorkerID identifies the replica in the events.worker_id column
+func newWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
 	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
 }
 
 func (s *Sender) StartProcessEvents(ctx context.Context, handelPeriod time.Duration) {
 	const op = "event-sender.StartProcessEvents"
-	log := s.log.With(slog.String("op", op))
+	log := s.log.With(slog.String("op", op), slog.String("worker_id", s.workerID))
 
 	ticker := time.NewTicker(handelPeriod)
 
 	go func() {
+		defer close(s.done)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ctx.Done():
@@ -35,30 +73,91 @@ func (s *Sender) StartProcessEvents(ctx context.Context, handelPeriod time.Durat
 				return
 			case <-ticker.C:
 			}
-			ev, err := s.storage.GetNewEvent()
-			if err != nil {
-				if err == storage.ErrEventNotFound {
-					log.Debug("no new events found, waiting for next tick")
-					continue
-				}
-				log.Error("error getting new event", slog.Any("error", err))
-				continue
+			sent := s.drain(ctx)
+			if sent > 0 {
+				log.Debug("events sent", slog.Int("count", sent))
 			}
+		}
+	}()
+}
+
+// Wait blocks until the sender stops after ctx of StartProcessEvents is done,
+// the batch in progress is always finished first.
+func (s *Sender) Wait() {
+	<-s.done
+}
+
+// drain processes batches until the backlog is empty, cfg.MaxDrainTime passes or ctx is done.
+// It returns the number of events marked as done.
+func (s *Sender) drain(ctx context.Context) int {
+	const op = "event-sender.drain"
+	log := s.log.With(slog.String("op", op), slog.String("worker_id", s.workerID))
 
-			s.stubSendEventMessage(ev)
+	//ctx only stops claiming new batches, a claimed batch is published and marked till the end
+	publishCtx := context.WithoutCancel(ctx)
 
-			if err := s.storage.MarkEventAsDone(ev.ID); err != nil {
-				log.Error("error marking event as done", slog.Any("error", err), slog.Int("event_id", ev.ID))
+	deadline := time.Now().Add(s.cfg.MaxDrainTime)
+	sent := 0
+	for ctx.Err() == nil && time.Now().Before(deadline) {
+		events, err := s.storage.ClaimEvents(s.workerID, s.cfg.BatchSize, s.cfg.Lease)
+		if err != nil {
+			if errors.Is(err, storage.ErrEventNotFound) {
+				log.Debug("no new events found, waiting for next tick")
+				return sent
+			}
+			log.Error("error claiming new events", slog.Any("error", err))
+			return sent
+		}
+
+		ids := make([]int, 0, len(events))
+		for _, ev := range events {
+			if err := s.publisher.Publish(publishCtx, domain.NewCloudEvent(ev, s.cfg.Source)); err != nil {
+				s.handleFailure(ev, err)
 				continue
 			}
+			ids = append(ids, ev.ID)
 		}
-	}()
+		if len(ids) == 0 {
+			return sent
+		}
+
+		if err := s.storage.MarkEventsAsDone(ids); err != nil {
+			//the lease expires and the batch is claimed again
+			log.Error("error marking events as done", slog.Any("error", err), slog.Any("event_ids", ids))
+			return sent
+		}
+		sent += len(ids)
+
+		//a partial batch means the backlog is drained
+		if len(events) < s.cfg.BatchSize {
+			return sent
+		}
+	}
+	return sent
 }
 
-func (s *Sender) stubSendEventMessage(event domain.Event) {
-	const op = "event-sender.StubSendEventMessage"
+// handleFailure schedules the next attempt with a backoff or moves the event to the dead state.
+func (s *Sender) handleFailure(ev domain.Event, publishErr error) {
+	const op = "event-sender.handleFailure"
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("event_id", ev.ID),
+		slog.String("publish_error", publishErr.Error()),
+	)
 
-	log := s.log.With(slog.String("op", op))
+	attempt := ev.Attempts + 1
+	if attempt >= s.cfg.MaxAttempts {
+		log.Error("event is dead, max attempts reached", slog.Int("attempts", attempt))
+		if err := s.storage.MarkEventDead(ev.ID, publishErr.Error()); err != nil {
+			//the lease expires and the event is published again
+			log.Error("error marking event as dead", sl.Err(err))
+		}
+		return
+	}
 
-	log.Info("Sending event message", slog.Any("event", event))
+	nextAttemptAt := time.Now().Add(backoff.Exponential(attempt, s.cfg.RetryBaseDelay, s.cfg.RetryMaxDelay))
+	log.Warn("error publishing event, retry scheduled", slog.Int("attempts", attempt), slog.Time("next_attempt_at", nextAttemptAt))
+	if err := s.storage.MarkEventFailed(ev.ID, publishErr.Error(), nextAttemptAt); err != nil {
+		log.Error("error marking event as failed", sl.Err(err))
+	}
 }
@@ -0,0 +1,160 @@
+package eventsender
+
+import (
+	"context"
+	"errors"
+	"short-url/internal/config"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"short-url/internal/storage"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memStorage is an in-memory outbox
+type memStorage struct {
+	mu     sync.Mutex
+	events []domain.Event
+	done   map[int]bool
+	failed map[int]time.Time
+	dead   map[int]bool
+	claims int
+}
+
+func newMemStorage(n int) *memStorage {
+	s := &memStorage{done: make(map[int]bool), failed: make(map[int]time.Time), dead: make(map[int]bool)}
+	for i := 1; i <= n; i++ {
+		s.events = append(s.events, domain.Event{ID: i, EventType: "url_saved"})
+	}
+	return s
+}
+
+func (s *memStorage) ClaimEvents(_ string, limit int, _ time.Duration) ([]domain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.claims++
+
+	var res []domain.Event
+	for _, ev := range s.events {
+		if len(res) == limit {
+			break
+		}
+		if !s.done[ev.ID] && !s.dead[ev.ID] {
+			res = append(res, ev)
+		}
+	}
+	if len(res) == 0 {
+		return nil, storage.ErrEventNotFound
+	}
+	return res, nil
+}
+
+func (s *memStorage) MarkEventsAsDone(ids []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.done[id] = true
+	}
+	return nil
+}
+
+func (s *memStorage) MarkEventFailed(id int, _ string, nextAttemptAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed[id] = nextAttemptAt
+	return nil
+}
+
+func (s *memStorage) MarkEventDead(id int, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dead[id] = true
+	return nil
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, domain.CloudEvent) error {
+	return errors.New("downstream is down")
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, domain.CloudEvent) error { return nil }
+
+func TestSender_DrainBacklog(t *testing.T) {
+	st := newMemStorage(250)
+	s := New(st, nopPublisher{}, silentlog.NewSilentLogger(), config.EventSender{
+		Lease:        time.Minute,
+		BatchSize:    100,
+		MaxDrainTime: time.Minute,
+	})
+
+	sent := s.drain(context.Background())
+
+	require.Equal(t, 250, sent)
+	require.Len(t, st.done, 250)
+	//100 + 100 + 50, the partial batch stops the loop
+	require.Equal(t, 3, st.claims)
+}
+
+func TestSender_DrainStopsOnCancel(t *testing.T) {
+	st := newMemStorage(10)
+	s := New(st, nopPublisher{}, silentlog.NewSilentLogger(), config.EventSender{
+		Lease:        time.Minute,
+		BatchSize:    1,
+		MaxDrainTime: time.Minute,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.Zero(t, s.drain(ctx))
+	require.Zero(t, st.claims)
+}
+
+func TestSender_PublishFailure(t *testing.T) {
+	st := newMemStorage(2)
+	//the second event has used all attempts but one
+	st.events[1].Attempts = 2
+
+	s := New(st, failingPublisher{}, silentlog.NewSilentLogger(), config.EventSender{
+		Lease:          time.Minute,
+		BatchSize:      10,
+		MaxDrainTime:   time.Minute,
+		MaxAttempts:    3,
+		RetryBaseDelay: time.Second,
+		RetryMaxDelay:  time.Minute,
+	})
+
+	require.Zero(t, s.drain(context.Background()))
+	require.Empty(t, st.done)
+
+	require.Contains(t, st.failed, 1)
+	require.True(t, st.failed[1].After(time.Now()))
+	require.True(t, st.dead[2])
+}
+
+func TestSender_Wait(t *testing.T) {
+	st := newMemStorage(3)
+	s := New(st, nopPublisher{}, silentlog.NewSilentLogger(), config.EventSender{
+		Lease:        time.Minute,
+		BatchSize:    10,
+		MaxDrainTime: time.Minute,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.StartProcessEvents(ctx, time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		return len(st.done) == 3
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	s.Wait()
+}
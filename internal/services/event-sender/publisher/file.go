@@ -0,0 +1,54 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"short-url/internal/http-server/model/domain"
+	"sync"
+)
+
+// File appends every event as one CloudEvent JSON line to a file.
+type File struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func NewFile(path string) (*File, error) {
+	const op = "event-sender.publisher.NewFile"
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &File{f: f}, nil
+}
+
+func (p *File) Publish(_ context.Context, event domain.CloudEvent) error {
+	const op = "event-sender.publisher.File.Publish"
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.f.Write(line); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	//the event is marked as done right after, so it must be on disk
+	if err := p.f.Sync(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *File) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.f.Close()
+}
@@ -0,0 +1,44 @@
+package publisher_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/services/event-sender/publisher"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_Publish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+
+	p, err := publisher.NewFile(path)
+	require.NoError(t, err)
+
+	events := []domain.CloudEvent{
+		domain.NewCloudEvent(domain.Event{ID: 1, EventType: "url_saved", Payload: `{"alias":"a"}`}, "/test"),
+		domain.NewCloudEvent(domain.Event{ID: 2, EventType: "url_saved", Payload: `{"alias":"b"}`}, "/test"),
+	}
+	for _, ev := range events {
+		require.NoError(t, p.Publish(context.Background(), ev))
+	}
+	require.NoError(t, p.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var got []domain.CloudEvent
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var ev domain.CloudEvent
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &ev))
+		got = append(got, ev)
+	}
+	require.NoError(t, sc.Err())
+	require.Equal(t, events, got)
+}
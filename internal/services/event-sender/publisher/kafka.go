@@ -0,0 +1,54 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"short-url/internal/http-server/model/domain"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Kafka produces every event as a structured-mode CloudEvent to a topic, the event id is the message key.
+type Kafka struct {
+	writer *kafka.Writer
+}
+
+func NewKafka(brokers []string, topic string, timeout time.Duration) *Kafka {
+	return &Kafka{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			WriteTimeout: timeout,
+		},
+	}
+}
+
+func (p *Kafka) Publish(ctx context.Context, event domain.CloudEvent) error {
+	const op = "event-sender.publisher.Kafka.Publish"
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.ID),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: "content-type", Value: []byte("application/cloudevents+json")},
+			{Key: "ce_type", Value: []byte(event.Type)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *Kafka) Close() error {
+	return p.writer.Close()
+}
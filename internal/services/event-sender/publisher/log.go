@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+	"log/slog"
+	"short-url/internal/http-server/model/domain"
+)
+
+// Log only writes events to the service log, useful for local runs.
+type Log struct {
+	log *slog.Logger
+}
+
+func NewLog(log *slog.Logger) *Log {
+	return &Log{log: log}
+}
+
+func (p *Log) Publish(_ context.Context, event domain.CloudEvent) error {
+	const op = "event-sender.publisher.Log.Publish"
+
+	p.log.With(slog.String("op", op)).Info("Sending event message", slog.Any("event", event))
+	return nil
+}
+
+func (p *Log) Close() error {
+	return nil
+}
@@ -0,0 +1,53 @@
+package publisher_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/services/event-sender/publisher"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Publish(t *testing.T) {
+	const secret = "secret"
+	event := domain.NewCloudEvent(domain.Event{ID: 7, EventType: "url_saved", Payload: `{"alias":"abc"}`}, "/test")
+
+	var got domain.CloudEvent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		signature := "sha256=" + publisher.Sign([]byte(secret), r.Header.Get(publisher.HeaderTimestamp), body)
+		require.Equal(t, signature, r.Header.Get(publisher.HeaderSignature))
+		require.Equal(t, "short-url.url_saved", r.Header.Get(publisher.HeaderEventType))
+		require.Equal(t, "application/cloudevents+json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.Unmarshal(body, &got))
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	p := publisher.NewWebhook(ts.URL, secret, time.Second)
+	defer p.Close()
+
+	require.NoError(t, p.Publish(context.Background(), event))
+	require.Equal(t, event, got)
+}
+
+func TestWebhook_PublishError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	p := publisher.NewWebhook(ts.URL, "secret", time.Second)
+	defer p.Close()
+
+	require.Error(t, p.Publish(context.Background(), domain.NewCloudEvent(domain.Event{ID: 1}, "/test")))
+}
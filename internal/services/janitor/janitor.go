@@ -0,0 +1,106 @@
+package janitor
+
+import (
+	"context"
+	"log/slog"
+	"short-url/internal/config"
+	"short-url/internal/lib/sl"
+	"time"
+)
+
+const (
+	ModePurge   = "purge"
+	ModeArchive = "archive"
+)
+
+type URLPurger interface {
+	PurgeExpiredURLs(now time.Time, limit int, archive bool) (int, error)
+	PurgeIdempotencyKeys(now time.Time, limit int) (int, error)
+}
+
+// Janitor periodically removes expired links and idempotency keys, the url_expired events are written by the storage.
+type Janitor struct {
+	storage URLPurger
+	log     *slog.Logger
+	cfg     config.Janitor
+	done    chan struct{}
+}
+
+func New(storage URLPurger, log *slog.Logger, cfg config.Janitor) *Janitor {
+	return &Janitor{
+		storage: storage,
+		log:     log,
+		cfg:     cfg,
+		done:    make(chan struct{}),
+	}
+}
+
+func (j *Janitor) StartPurgeExpired(ctx context.Context) {
+	const op = "janitor.StartPurgeExpired"
+	log := j.log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(j.cfg.Period)
+
+	go func() {
+		defer close(j.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("context done, stopping janitor")
+				return
+			case <-ticker.C:
+			}
+			purged := j.purge(ctx)
+			if purged > 0 {
+				log.Info("expired urls purged", slog.Int("count", purged), slog.String("mode", j.cfg.Mode))
+			}
+			if keys := j.purgeIdempotencyKeys(ctx); keys > 0 {
+				log.Info("expired idempotency keys purged", slog.Int("count", keys))
+			}
+		}
+	}()
+}
+
+// Wait blocks until the janitor stops after ctx of StartPurgeExpired is done.
+func (j *Janitor) Wait() {
+	<-j.done
+}
+
+// purge removes expired links batch by batch until none are left.
+func (j *Janitor) purge(ctx context.Context) int {
+	const op = "janitor.purge"
+
+	total := 0
+	for ctx.Err() == nil {
+		n, err := j.storage.PurgeExpiredURLs(time.Now(), j.cfg.BatchSize, j.cfg.Mode == ModeArchive)
+		if err != nil {
+			j.log.Error("error purging expired urls", slog.String("op", op), sl.Err(err))
+			return total
+		}
+		total += n
+		if n < j.cfg.BatchSize {
+			return total
+		}
+	}
+	return total
+}
+
+// purgeIdempotencyKeys removes expired idempotency keys batch by batch until none are left.
+func (j *Janitor) purgeIdempotencyKeys(ctx context.Context) int {
+	const op = "janitor.purgeIdempotencyKeys"
+
+	total := 0
+	for ctx.Err() == nil {
+		n, err := j.storage.PurgeIdempotencyKeys(time.Now(), j.cfg.BatchSize)
+		if err != nil {
+			j.log.Error("error purging idempotency keys", slog.String("op", op), sl.Err(err))
+			return total
+		}
+		total += n
+		if n < j.cfg.BatchSize {
+			return total
+		}
+	}
+	return total
+}
@@ -0,0 +1,49 @@
+package janitor
+
+import (
+	"context"
+	"short-url/internal/config"
+	"short-url/internal/lib/logger/handlers/silentlog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memPurger struct {
+	expired int
+	calls   int
+	archive bool
+	keys    int
+}
+
+func (p *memPurger) PurgeExpiredURLs(_ time.Time, limit int, archive bool) (int, error) {
+	p.calls++
+	p.archive = archive
+	n := min(limit, p.expired)
+	p.expired -= n
+	return n, nil
+}
+
+func (p *memPurger) PurgeIdempotencyKeys(_ time.Time, limit int) (int, error) {
+	n := min(limit, p.keys)
+	p.keys -= n
+	return n, nil
+}
+
+func TestJanitor_Purge(t *testing.T) {
+	st := &memPurger{expired: 25}
+	j := New(st, silentlog.NewSilentLogger(), config.Janitor{BatchSize: 10, Mode: ModeArchive})
+
+	require.Equal(t, 25, j.purge(context.Background()))
+	require.Equal(t, 3, st.calls)
+	require.True(t, st.archive)
+}
+
+func TestJanitor_PurgeIdempotencyKeys(t *testing.T) {
+	st := &memPurger{keys: 20}
+	j := New(st, silentlog.NewSilentLogger(), config.Janitor{BatchSize: 10, Mode: ModePurge})
+
+	require.Equal(t, 20, j.purgeIdempotencyKeys(context.Background()))
+	require.Zero(t, st.keys)
+}
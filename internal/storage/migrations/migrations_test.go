@@ -0,0 +1,53 @@
+package migrations_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"short-url/internal/storage/migrations"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrator_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := migrations.New(db, migrations.DialectSQLite)
+	require.NoError(t, err)
+
+	applied, err := m.Up()
+	require.NoError(t, err)
+	require.NotEmpty(t, applied)
+	require.Equal(t, 1, applied[0].Version)
+
+	//second run is a no-op
+	applied, err = m.Up()
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	for _, st := range statuses {
+		require.NotNil(t, st.AppliedAt, "migration %d is not applied", st.Version)
+	}
+
+	//roll everything back
+	for range statuses {
+		_, err := m.Down()
+		require.NoError(t, err)
+	}
+	_, err = m.Down()
+	require.ErrorIs(t, err, migrations.ErrNoMigrations)
+
+	var tables int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name IN ('url', 'events')").Scan(&tables))
+	require.Zero(t, tables)
+}
+
+func TestMigrator_UnknownDialect(t *testing.T) {
+	_, err := migrations.New(nil, "oracle")
+	require.Error(t, err)
+}
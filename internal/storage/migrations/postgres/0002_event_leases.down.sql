@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_events_status;
+
+UPDATE events SET status='new' WHERE status='in_progress';
+
+ALTER TABLE events
+	DROP COLUMN reserved_to,
+	DROP COLUMN worker_id;
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_status_check;
+ALTER TABLE events ADD CONSTRAINT events_status_check CHECK (status IN ('new', 'done'));
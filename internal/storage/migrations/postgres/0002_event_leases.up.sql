@@ -0,0 +1,8 @@
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_status_check;
+ALTER TABLE events ADD CONSTRAINT events_status_check CHECK (status IN ('new', 'in_progress', 'done'));
+
+ALTER TABLE events
+	ADD COLUMN reserved_to TIMESTAMPTZ DEFAULT NULL,
+	ADD COLUMN worker_id TEXT DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_events_status ON events(status, reserved_to);
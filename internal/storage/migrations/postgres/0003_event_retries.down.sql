@@ -0,0 +1,9 @@
+UPDATE events SET status='new' WHERE status='dead';
+
+ALTER TABLE events
+	DROP COLUMN attempts,
+	DROP COLUMN last_error,
+	DROP COLUMN next_attempt_at;
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_status_check;
+ALTER TABLE events ADD CONSTRAINT events_status_check CHECK (status IN ('new', 'in_progress', 'done'));
@@ -0,0 +1,7 @@
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_status_check;
+ALTER TABLE events ADD CONSTRAINT events_status_check CHECK (status IN ('new', 'in_progress', 'done', 'dead'));
+
+ALTER TABLE events
+	ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN last_error TEXT DEFAULT NULL,
+	ADD COLUMN next_attempt_at TIMESTAMPTZ DEFAULT NULL;
@@ -0,0 +1,10 @@
+ALTER TABLE url ADD COLUMN expires_at TIMESTAMPTZ DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_url_expires_at ON url(expires_at);
+
+CREATE TABLE IF NOT EXISTS url_archive(
+	id BIGINT PRIMARY KEY,
+	alias TEXT NOT NULL,
+	url TEXT NOT NULL,
+	expires_at TIMESTAMPTZ,
+	archived_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS click_rollup_state;
+DROP TABLE IF EXISTS click_breakdowns_hourly;
+DROP TABLE IF EXISTS click_rollups_daily;
+DROP TABLE IF EXISTS click_rollups_hourly;
+DROP INDEX IF EXISTS idx_clicks_clicked_at;
+ALTER TABLE clicks DROP COLUMN IF EXISTS browser;
+ALTER TABLE clicks DROP COLUMN IF EXISTS referrer_host;
@@ -0,0 +1,30 @@
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS referrer_host TEXT NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS browser TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_clicks_clicked_at ON clicks(clicked_at);
+
+CREATE TABLE IF NOT EXISTS click_rollups_hourly(
+	alias TEXT NOT NULL,
+	bucket_start TIMESTAMPTZ NOT NULL,
+	clicks BIGINT NOT NULL,
+	unique_visitors BIGINT NOT NULL,
+	PRIMARY KEY(alias, bucket_start));
+
+CREATE TABLE IF NOT EXISTS click_rollups_daily(
+	alias TEXT NOT NULL,
+	bucket_start TIMESTAMPTZ NOT NULL,
+	clicks BIGINT NOT NULL,
+	unique_visitors BIGINT NOT NULL,
+	PRIMARY KEY(alias, bucket_start));
+
+CREATE TABLE IF NOT EXISTS click_breakdowns_hourly(
+	alias TEXT NOT NULL,
+	bucket_start TIMESTAMPTZ NOT NULL,
+	dimension TEXT NOT NULL CHECK (dimension IN ('referrer', 'browser', 'country')),
+	value TEXT NOT NULL,
+	clicks BIGINT NOT NULL,
+	PRIMARY KEY(alias, bucket_start, dimension, value));
+
+CREATE TABLE IF NOT EXISTS click_rollup_state(
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	rolled_up_to TIMESTAMPTZ NOT NULL);
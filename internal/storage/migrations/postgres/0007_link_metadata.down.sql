@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_url_created_at;
+ALTER TABLE url DROP COLUMN IF EXISTS disabled;
+ALTER TABLE url DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE url DROP COLUMN IF EXISTS created_at;
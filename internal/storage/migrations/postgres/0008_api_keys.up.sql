@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS api_keys(
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	prefix TEXT NOT NULL,
+	key_hash TEXT NOT NULL UNIQUE,
+	scopes TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	revoked_at TIMESTAMPTZ);
+
+ALTER TABLE url ADD COLUMN IF NOT EXISTS api_key_id BIGINT REFERENCES api_keys(id);
@@ -0,0 +1,39 @@
+-- fails if several workspaces use the same alias, they have to be renamed first
+ALTER TABLE click_breakdowns_hourly ADD COLUMN IF NOT EXISTS alias TEXT;
+UPDATE click_breakdowns_hourly b SET alias = u.alias FROM url u WHERE u.id = b.url_id;
+DELETE FROM click_breakdowns_hourly WHERE alias IS NULL;
+ALTER TABLE click_breakdowns_hourly DROP COLUMN url_id;
+ALTER TABLE click_breakdowns_hourly ADD PRIMARY KEY(alias, bucket_start, dimension, value);
+
+ALTER TABLE click_rollups_daily ADD COLUMN IF NOT EXISTS alias TEXT;
+UPDATE click_rollups_daily r SET alias = u.alias FROM url u WHERE u.id = r.url_id;
+DELETE FROM click_rollups_daily WHERE alias IS NULL;
+ALTER TABLE click_rollups_daily DROP COLUMN url_id;
+ALTER TABLE click_rollups_daily ADD PRIMARY KEY(alias, bucket_start);
+
+ALTER TABLE click_rollups_hourly ADD COLUMN IF NOT EXISTS alias TEXT;
+UPDATE click_rollups_hourly r SET alias = u.alias FROM url u WHERE u.id = r.url_id;
+DELETE FROM click_rollups_hourly WHERE alias IS NULL;
+ALTER TABLE click_rollups_hourly DROP COLUMN url_id;
+ALTER TABLE click_rollups_hourly ADD PRIMARY KEY(alias, bucket_start);
+
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS alias TEXT;
+UPDATE clicks c SET alias = u.alias FROM url u WHERE u.id = c.url_id;
+DELETE FROM clicks WHERE alias IS NULL;
+ALTER TABLE clicks ALTER COLUMN alias SET NOT NULL;
+ALTER TABLE clicks DROP COLUMN url_id;
+CREATE INDEX IF NOT EXISTS idx_clicks_alias_clicked_at ON clicks(alias, clicked_at);
+
+ALTER TABLE url_archive DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_url_created_at;
+ALTER TABLE url DROP CONSTRAINT IF EXISTS url_workspace_id_alias_key;
+ALTER TABLE url ADD CONSTRAINT url_alias_key UNIQUE(alias);
+ALTER TABLE url DROP COLUMN IF EXISTS workspace_id;
+CREATE INDEX IF NOT EXISTS idx_alias ON url(alias);
+CREATE INDEX IF NOT EXISTS idx_url_created_at ON url(created_at, id);
+
+DROP INDEX IF EXISTS idx_api_keys_workspace_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS workspaces(
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	slug TEXT NOT NULL UNIQUE,
+	hostname TEXT UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL);
+
+-- existing links and keys belong to the default workspace
+INSERT INTO workspaces(id, name, slug, created_at) VALUES(1, 'Default', 'default', now());
+SELECT setval(pg_get_serial_sequence('workspaces', 'id'), 1);
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces(id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_workspace_id ON api_keys(workspace_id);
+
+-- aliases are unique per workspace
+ALTER TABLE url ADD COLUMN IF NOT EXISTS workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces(id);
+ALTER TABLE url DROP CONSTRAINT IF EXISTS url_alias_key;
+ALTER TABLE url ADD CONSTRAINT url_workspace_id_alias_key UNIQUE(workspace_id, alias);
+DROP INDEX IF EXISTS idx_alias;
+DROP INDEX IF EXISTS idx_url_created_at;
+CREATE INDEX IF NOT EXISTS idx_url_created_at ON url(workspace_id, created_at, id);
+
+ALTER TABLE url_archive ADD COLUMN IF NOT EXISTS workspace_id BIGINT NOT NULL DEFAULT 1;
+
+-- clicks and rollups are keyed by the link id instead of the alias,
+-- clicks of deleted links can't be matched anymore and are dropped
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS url_id BIGINT;
+UPDATE clicks c SET url_id = u.id FROM url u WHERE u.alias = c.alias;
+DELETE FROM clicks WHERE url_id IS NULL;
+ALTER TABLE clicks ALTER COLUMN url_id SET NOT NULL;
+ALTER TABLE clicks DROP COLUMN alias;
+CREATE INDEX IF NOT EXISTS idx_clicks_url_id_clicked_at ON clicks(url_id, clicked_at);
+
+ALTER TABLE click_rollups_hourly ADD COLUMN IF NOT EXISTS url_id BIGINT;
+UPDATE click_rollups_hourly r SET url_id = u.id FROM url u WHERE u.alias = r.alias;
+DELETE FROM click_rollups_hourly WHERE url_id IS NULL;
+ALTER TABLE click_rollups_hourly DROP COLUMN alias;
+ALTER TABLE click_rollups_hourly ADD PRIMARY KEY(url_id, bucket_start);
+
+ALTER TABLE click_rollups_daily ADD COLUMN IF NOT EXISTS url_id BIGINT;
+UPDATE click_rollups_daily r SET url_id = u.id FROM url u WHERE u.alias = r.alias;
+DELETE FROM click_rollups_daily WHERE url_id IS NULL;
+ALTER TABLE click_rollups_daily DROP COLUMN alias;
+ALTER TABLE click_rollups_daily ADD PRIMARY KEY(url_id, bucket_start);
+
+ALTER TABLE click_breakdowns_hourly ADD COLUMN IF NOT EXISTS url_id BIGINT;
+UPDATE click_breakdowns_hourly b SET url_id = u.id FROM url u WHERE u.alias = b.alias;
+DELETE FROM click_breakdowns_hourly WHERE url_id IS NULL;
+ALTER TABLE click_breakdowns_hourly DROP COLUMN alias;
+ALTER TABLE click_breakdowns_hourly ADD PRIMARY KEY(url_id, bucket_start, dimension, value);
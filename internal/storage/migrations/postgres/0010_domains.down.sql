@@ -0,0 +1,15 @@
+ALTER TABLE url_archive DROP COLUMN IF EXISTS domain_id;
+
+-- links of custom domains go back to the namespace of their workspace,
+-- fails if it already has the alias on another domain
+DROP INDEX IF EXISTS idx_url_default_alias;
+DROP INDEX IF EXISTS idx_url_domain_alias;
+ALTER TABLE url ADD CONSTRAINT url_workspace_id_alias_key UNIQUE(workspace_id, alias);
+ALTER TABLE url DROP COLUMN IF EXISTS domain_id;
+
+-- the first verified domain of a workspace becomes its hostname
+ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS hostname TEXT UNIQUE;
+UPDATE workspaces w SET hostname = (
+	SELECT hostname FROM domains d WHERE d.workspace_id = w.id AND d.verified_at IS NOT NULL ORDER BY d.id LIMIT 1);
+
+DROP TABLE IF EXISTS domains;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS domains(
+	id BIGSERIAL PRIMARY KEY,
+	workspace_id BIGINT NOT NULL REFERENCES workspaces(id),
+	hostname TEXT NOT NULL UNIQUE,
+	verification_token TEXT NOT NULL,
+	verified_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_domains_workspace_id ON domains(workspace_id);
+
+-- workspace hostnames were set by admins, they become verified domains
+INSERT INTO domains(workspace_id, hostname, verification_token, verified_at, created_at)
+	SELECT id, hostname, '', created_at, created_at FROM workspaces WHERE hostname IS NOT NULL;
+
+ALTER TABLE workspaces DROP COLUMN IF EXISTS hostname;
+
+-- aliases are unique per domain, links without a domain are served on the default one
+-- and keep their aliases unique per workspace
+ALTER TABLE url ADD COLUMN IF NOT EXISTS domain_id BIGINT REFERENCES domains(id);
+
+-- links of workspaces with a hostname move to its domain
+UPDATE url u SET domain_id = d.id FROM domains d WHERE d.workspace_id = u.workspace_id;
+
+ALTER TABLE url DROP CONSTRAINT IF EXISTS url_workspace_id_alias_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_url_domain_alias ON url(domain_id, alias) WHERE domain_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_url_default_alias ON url(workspace_id, alias) WHERE domain_id IS NULL;
+
+ALTER TABLE url_archive ADD COLUMN IF NOT EXISTS domain_id BIGINT;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS workspace_usage;
+
+ALTER TABLE workspaces DROP COLUMN IF EXISTS monthly_link_quota;
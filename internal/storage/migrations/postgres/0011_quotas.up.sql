@@ -0,0 +1,9 @@
+-- monthly number of links a workspace may create, NULL is unlimited
+ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS monthly_link_quota BIGINT;
+
+-- links created by the workspace per calendar month (UTC), period is 'YYYY-MM'
+CREATE TABLE IF NOT EXISTS workspace_usage(
+	workspace_id BIGINT NOT NULL REFERENCES workspaces(id),
+	period TEXT NOT NULL,
+	links_created BIGINT NOT NULL DEFAULT 0,
+	PRIMARY KEY(workspace_id, period));
@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS alias_sequence;
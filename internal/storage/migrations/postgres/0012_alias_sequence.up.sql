@@ -0,0 +1,2 @@
+-- ids of the sequential alias strategies
+CREATE SEQUENCE IF NOT EXISTS alias_sequence;
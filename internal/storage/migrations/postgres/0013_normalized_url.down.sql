@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_url_normalized_url;
+ALTER TABLE url DROP COLUMN IF EXISTS normalized_url;
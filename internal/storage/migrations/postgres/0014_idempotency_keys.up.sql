@@ -0,0 +1,13 @@
+-- responses of the requests sent with an Idempotency-Key header, status is 0 while the request is in progress
+CREATE TABLE IF NOT EXISTS idempotency_keys(
+	scope TEXT NOT NULL,
+	key TEXT NOT NULL,
+	request_hash TEXT NOT NULL,
+	status INTEGER NOT NULL DEFAULT 0,
+	content_type TEXT NOT NULL DEFAULT '',
+	body BYTEA,
+	created_at TIMESTAMPTZ NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY(scope, key));
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,2 @@
+-- raw clicks before it are purged, unique visitors of longer ranges can't be counted from them
+ALTER TABLE click_rollup_state ADD COLUMN purged_before TIMESTAMPTZ;
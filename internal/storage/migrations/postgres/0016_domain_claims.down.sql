@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_domains_verified_hostname;
+DROP INDEX IF EXISTS idx_domains_workspace_hostname;
+
+-- only the verified domain or else the oldest claim of a hostname is kept
+DELETE FROM domains d WHERE verified_at IS NULL AND EXISTS (
+	SELECT 1 FROM domains o WHERE o.hostname = d.hostname AND (o.verified_at IS NOT NULL OR o.id < d.id));
+
+ALTER TABLE domains ADD CONSTRAINT domains_hostname_key UNIQUE (hostname);
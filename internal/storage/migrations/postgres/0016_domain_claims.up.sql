@@ -0,0 +1,5 @@
+-- unverified claims don't take the hostname, it belongs to the workspace that verifies it first
+ALTER TABLE domains DROP CONSTRAINT IF EXISTS domains_hostname_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_domains_workspace_hostname ON domains(workspace_id, hostname);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_domains_verified_hostname ON domains(hostname) WHERE verified_at IS NOT NULL;
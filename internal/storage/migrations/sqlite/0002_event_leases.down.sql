@@ -0,0 +1,12 @@
+CREATE TABLE events_old(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_type TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'done')),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP);
+
+INSERT INTO events_old(id, event_type, payload, status, created_at)
+	SELECT id, event_type, payload, CASE status WHEN 'done' THEN 'done' ELSE 'new' END, created_at FROM events;
+
+DROP TABLE events;
+ALTER TABLE events_old RENAME TO events;
@@ -0,0 +1,17 @@
+-- sqlite can't alter a CHECK constraint, so the table is rebuilt
+CREATE TABLE events_new(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_type TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'in_progress', 'done')),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	reserved_to TIMESTAMP DEFAULT NULL,
+	worker_id TEXT DEFAULT NULL);
+
+INSERT INTO events_new(id, event_type, payload, status, created_at)
+	SELECT id, event_type, payload, status, created_at FROM events;
+
+DROP TABLE events;
+ALTER TABLE events_new RENAME TO events;
+
+CREATE INDEX IF NOT EXISTS idx_events_status ON events(status, reserved_to);
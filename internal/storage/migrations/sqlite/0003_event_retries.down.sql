@@ -0,0 +1,16 @@
+CREATE TABLE events_old(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_type TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'in_progress', 'done')),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	reserved_to TIMESTAMP DEFAULT NULL,
+	worker_id TEXT DEFAULT NULL);
+
+INSERT INTO events_old(id, event_type, payload, status, created_at, reserved_to, worker_id)
+	SELECT id, event_type, payload, CASE status WHEN 'dead' THEN 'new' ELSE status END, created_at, reserved_to, worker_id FROM events;
+
+DROP TABLE events;
+ALTER TABLE events_old RENAME TO events;
+
+CREATE INDEX IF NOT EXISTS idx_events_status ON events(status, reserved_to);
@@ -0,0 +1,20 @@
+-- sqlite can't alter a CHECK constraint, so the table is rebuilt
+CREATE TABLE events_new(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_type TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'in_progress', 'done', 'dead')),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	reserved_to TIMESTAMP DEFAULT NULL,
+	worker_id TEXT DEFAULT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT DEFAULT NULL,
+	next_attempt_at TIMESTAMP DEFAULT NULL);
+
+INSERT INTO events_new(id, event_type, payload, status, created_at, reserved_to, worker_id)
+	SELECT id, event_type, payload, status, created_at, reserved_to, worker_id FROM events;
+
+DROP TABLE events;
+ALTER TABLE events_new RENAME TO events;
+
+CREATE INDEX IF NOT EXISTS idx_events_status ON events(status, reserved_to);
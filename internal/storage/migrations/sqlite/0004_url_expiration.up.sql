@@ -0,0 +1,10 @@
+ALTER TABLE url ADD COLUMN expires_at TIMESTAMP DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_url_expires_at ON url(expires_at);
+
+CREATE TABLE IF NOT EXISTS url_archive(
+	id INTEGER PRIMARY KEY,
+	alias TEXT NOT NULL,
+	url TEXT NOT NULL,
+	expires_at TIMESTAMP,
+	archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS clicks(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	alias TEXT NOT NULL,
+	clicked_at TIMESTAMP NOT NULL,
+	referrer TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	country TEXT NOT NULL DEFAULT '',
+	ip_hash TEXT NOT NULL DEFAULT '');
+
+CREATE INDEX IF NOT EXISTS idx_clicks_alias_clicked_at ON clicks(alias, clicked_at);
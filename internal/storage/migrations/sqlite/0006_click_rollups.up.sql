@@ -0,0 +1,30 @@
+ALTER TABLE clicks ADD COLUMN referrer_host TEXT NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD COLUMN browser TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_clicks_clicked_at ON clicks(clicked_at);
+
+CREATE TABLE IF NOT EXISTS click_rollups_hourly(
+	alias TEXT NOT NULL,
+	bucket_start TIMESTAMP NOT NULL,
+	clicks INTEGER NOT NULL,
+	unique_visitors INTEGER NOT NULL,
+	PRIMARY KEY(alias, bucket_start));
+
+CREATE TABLE IF NOT EXISTS click_rollups_daily(
+	alias TEXT NOT NULL,
+	bucket_start TIMESTAMP NOT NULL,
+	clicks INTEGER NOT NULL,
+	unique_visitors INTEGER NOT NULL,
+	PRIMARY KEY(alias, bucket_start));
+
+CREATE TABLE IF NOT EXISTS click_breakdowns_hourly(
+	alias TEXT NOT NULL,
+	bucket_start TIMESTAMP NOT NULL,
+	dimension TEXT NOT NULL CHECK (dimension IN ('referrer', 'browser', 'country')),
+	value TEXT NOT NULL,
+	clicks INTEGER NOT NULL,
+	PRIMARY KEY(alias, bucket_start, dimension, value));
+
+CREATE TABLE IF NOT EXISTS click_rollup_state(
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	rolled_up_to TIMESTAMP NOT NULL);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_url_created_at;
+ALTER TABLE url DROP COLUMN disabled;
+ALTER TABLE url DROP COLUMN updated_at;
+ALTER TABLE url DROP COLUMN created_at;
@@ -0,0 +1,10 @@
+ALTER TABLE url ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE url ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE url ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- existing links are dated by the migration
+UPDATE url SET
+	created_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'),
+	updated_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
+
+CREATE INDEX IF NOT EXISTS idx_url_created_at ON url(created_at, id);
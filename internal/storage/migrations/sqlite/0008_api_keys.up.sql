@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS api_keys(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	prefix TEXT NOT NULL,
+	key_hash TEXT NOT NULL UNIQUE,
+	scopes TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	revoked_at TIMESTAMP);
+
+ALTER TABLE url ADD COLUMN api_key_id INTEGER REFERENCES api_keys(id);
@@ -0,0 +1,91 @@
+-- fails if several workspaces use the same alias, they have to be renamed first
+CREATE TABLE click_breakdowns_hourly_old(
+	alias TEXT NOT NULL,
+	bucket_start TIMESTAMP NOT NULL,
+	dimension TEXT NOT NULL CHECK (dimension IN ('referrer', 'browser', 'country')),
+	value TEXT NOT NULL,
+	clicks INTEGER NOT NULL,
+	PRIMARY KEY(alias, bucket_start, dimension, value));
+
+INSERT INTO click_breakdowns_hourly_old(alias, bucket_start, dimension, value, clicks)
+	SELECT u.alias, b.bucket_start, b.dimension, b.value, b.clicks
+	FROM click_breakdowns_hourly b JOIN url u ON u.id = b.url_id;
+
+DROP TABLE click_breakdowns_hourly;
+ALTER TABLE click_breakdowns_hourly_old RENAME TO click_breakdowns_hourly;
+
+CREATE TABLE click_rollups_daily_old(
+	alias TEXT NOT NULL,
+	bucket_start TIMESTAMP NOT NULL,
+	clicks INTEGER NOT NULL,
+	unique_visitors INTEGER NOT NULL,
+	PRIMARY KEY(alias, bucket_start));
+
+INSERT INTO click_rollups_daily_old(alias, bucket_start, clicks, unique_visitors)
+	SELECT u.alias, r.bucket_start, r.clicks, r.unique_visitors
+	FROM click_rollups_daily r JOIN url u ON u.id = r.url_id;
+
+DROP TABLE click_rollups_daily;
+ALTER TABLE click_rollups_daily_old RENAME TO click_rollups_daily;
+
+CREATE TABLE click_rollups_hourly_old(
+	alias TEXT NOT NULL,
+	bucket_start TIMESTAMP NOT NULL,
+	clicks INTEGER NOT NULL,
+	unique_visitors INTEGER NOT NULL,
+	PRIMARY KEY(alias, bucket_start));
+
+INSERT INTO click_rollups_hourly_old(alias, bucket_start, clicks, unique_visitors)
+	SELECT u.alias, r.bucket_start, r.clicks, r.unique_visitors
+	FROM click_rollups_hourly r JOIN url u ON u.id = r.url_id;
+
+DROP TABLE click_rollups_hourly;
+ALTER TABLE click_rollups_hourly_old RENAME TO click_rollups_hourly;
+
+CREATE TABLE clicks_old(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	alias TEXT NOT NULL,
+	clicked_at TIMESTAMP NOT NULL,
+	referrer TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	country TEXT NOT NULL DEFAULT '',
+	ip_hash TEXT NOT NULL DEFAULT '',
+	referrer_host TEXT NOT NULL DEFAULT '',
+	browser TEXT NOT NULL DEFAULT '');
+
+INSERT INTO clicks_old(id, alias, clicked_at, referrer, user_agent, country, ip_hash, referrer_host, browser)
+	SELECT c.id, u.alias, c.clicked_at, c.referrer, c.user_agent, c.country, c.ip_hash, c.referrer_host, c.browser
+	FROM clicks c JOIN url u ON u.id = c.url_id;
+
+DROP TABLE clicks;
+ALTER TABLE clicks_old RENAME TO clicks;
+
+CREATE INDEX IF NOT EXISTS idx_clicks_alias_clicked_at ON clicks(alias, clicked_at);
+CREATE INDEX IF NOT EXISTS idx_clicks_clicked_at ON clicks(clicked_at);
+
+ALTER TABLE url_archive DROP COLUMN workspace_id;
+
+CREATE TABLE url_old(
+	id INTEGER PRIMARY KEY,
+	alias TEXT NOT NULL UNIQUE,
+	url TEXT NOT NULL,
+	expires_at TIMESTAMP DEFAULT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00',
+	updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00',
+	disabled BOOLEAN NOT NULL DEFAULT FALSE,
+	api_key_id INTEGER REFERENCES api_keys(id));
+
+INSERT INTO url_old(id, alias, url, expires_at, created_at, updated_at, disabled, api_key_id)
+	SELECT id, alias, url, expires_at, created_at, updated_at, disabled, api_key_id FROM url;
+
+DROP TABLE url;
+ALTER TABLE url_old RENAME TO url;
+
+CREATE INDEX IF NOT EXISTS idx_alias ON url(alias);
+CREATE INDEX IF NOT EXISTS idx_url_expires_at ON url(expires_at);
+CREATE INDEX IF NOT EXISTS idx_url_created_at ON url(created_at, id);
+
+DROP INDEX IF EXISTS idx_api_keys_workspace_id;
+ALTER TABLE api_keys DROP COLUMN workspace_id;
+
+DROP TABLE IF EXISTS workspaces;
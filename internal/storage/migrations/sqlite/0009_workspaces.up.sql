@@ -0,0 +1,104 @@
+CREATE TABLE IF NOT EXISTS workspaces(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	slug TEXT NOT NULL UNIQUE,
+	hostname TEXT UNIQUE,
+	created_at TIMESTAMP NOT NULL);
+
+-- existing links and keys belong to the default workspace
+INSERT INTO workspaces(id, name, slug, created_at)
+	VALUES(1, 'Default', 'default', strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'));
+
+ALTER TABLE api_keys ADD COLUMN workspace_id INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_api_keys_workspace_id ON api_keys(workspace_id);
+
+-- aliases are unique per workspace, sqlite can't drop the UNIQUE constraint so the table is rebuilt.
+-- AUTOINCREMENT keeps ids of deleted links from being reused, clicks are keyed by the link id
+CREATE TABLE url_new(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workspace_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id),
+	alias TEXT NOT NULL,
+	url TEXT NOT NULL,
+	expires_at TIMESTAMP DEFAULT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00',
+	updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00',
+	disabled BOOLEAN NOT NULL DEFAULT FALSE,
+	api_key_id INTEGER REFERENCES api_keys(id),
+	UNIQUE(workspace_id, alias));
+
+INSERT INTO url_new(id, alias, url, expires_at, created_at, updated_at, disabled, api_key_id)
+	SELECT id, alias, url, expires_at, created_at, updated_at, disabled, api_key_id FROM url;
+
+DROP TABLE url;
+ALTER TABLE url_new RENAME TO url;
+
+CREATE INDEX IF NOT EXISTS idx_url_expires_at ON url(expires_at);
+CREATE INDEX IF NOT EXISTS idx_url_created_at ON url(workspace_id, created_at, id);
+
+ALTER TABLE url_archive ADD COLUMN workspace_id INTEGER NOT NULL DEFAULT 1;
+
+-- clicks and rollups are keyed by the link id instead of the alias,
+-- clicks of deleted links can't be matched anymore and are dropped
+CREATE TABLE clicks_new(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	url_id INTEGER NOT NULL,
+	clicked_at TIMESTAMP NOT NULL,
+	referrer TEXT NOT NULL DEFAULT '',
+	referrer_host TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	browser TEXT NOT NULL DEFAULT '',
+	country TEXT NOT NULL DEFAULT '',
+	ip_hash TEXT NOT NULL DEFAULT '');
+
+INSERT INTO clicks_new(id, url_id, clicked_at, referrer, referrer_host, user_agent, browser, country, ip_hash)
+	SELECT c.id, u.id, c.clicked_at, c.referrer, c.referrer_host, c.user_agent, c.browser, c.country, c.ip_hash
+	FROM clicks c JOIN url u ON u.alias = c.alias;
+
+DROP TABLE clicks;
+ALTER TABLE clicks_new RENAME TO clicks;
+
+CREATE INDEX IF NOT EXISTS idx_clicks_url_id_clicked_at ON clicks(url_id, clicked_at);
+CREATE INDEX IF NOT EXISTS idx_clicks_clicked_at ON clicks(clicked_at);
+
+CREATE TABLE click_rollups_hourly_new(
+	url_id INTEGER NOT NULL,
+	bucket_start TIMESTAMP NOT NULL,
+	clicks INTEGER NOT NULL,
+	unique_visitors INTEGER NOT NULL,
+	PRIMARY KEY(url_id, bucket_start));
+
+INSERT INTO click_rollups_hourly_new(url_id, bucket_start, clicks, unique_visitors)
+	SELECT u.id, r.bucket_start, r.clicks, r.unique_visitors
+	FROM click_rollups_hourly r JOIN url u ON u.alias = r.alias;
+
+DROP TABLE click_rollups_hourly;
+ALTER TABLE click_rollups_hourly_new RENAME TO click_rollups_hourly;
+
+CREATE TABLE click_rollups_daily_new(
+	url_id INTEGER NOT NULL,
+	bucket_start TIMESTAMP NOT NULL,
+	clicks INTEGER NOT NULL,
+	unique_visitors INTEGER NOT NULL,
+	PRIMARY KEY(url_id, bucket_start));
+
+INSERT INTO click_rollups_daily_new(url_id, bucket_start, clicks, unique_visitors)
+	SELECT u.id, r.bucket_start, r.clicks, r.unique_visitors
+	FROM click_rollups_daily r JOIN url u ON u.alias = r.alias;
+
+DROP TABLE click_rollups_daily;
+ALTER TABLE click_rollups_daily_new RENAME TO click_rollups_daily;
+
+CREATE TABLE click_breakdowns_hourly_new(
+	url_id INTEGER NOT NULL,
+	bucket_start TIMESTAMP NOT NULL,
+	dimension TEXT NOT NULL CHECK (dimension IN ('referrer', 'browser', 'country')),
+	value TEXT NOT NULL,
+	clicks INTEGER NOT NULL,
+	PRIMARY KEY(url_id, bucket_start, dimension, value));
+
+INSERT INTO click_breakdowns_hourly_new(url_id, bucket_start, dimension, value, clicks)
+	SELECT u.id, b.bucket_start, b.dimension, b.value, b.clicks
+	FROM click_breakdowns_hourly b JOIN url u ON u.alias = b.alias;
+
+DROP TABLE click_breakdowns_hourly;
+ALTER TABLE click_breakdowns_hourly_new RENAME TO click_breakdowns_hourly;
@@ -0,0 +1,43 @@
+ALTER TABLE url_archive DROP COLUMN domain_id;
+
+-- links of custom domains go back to the namespace of their workspace,
+-- fails if it already has the alias on another domain
+CREATE TABLE url_old(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workspace_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id),
+	alias TEXT NOT NULL,
+	url TEXT NOT NULL,
+	expires_at TIMESTAMP DEFAULT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00',
+	updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00',
+	disabled BOOLEAN NOT NULL DEFAULT FALSE,
+	api_key_id INTEGER REFERENCES api_keys(id),
+	UNIQUE(workspace_id, alias));
+
+INSERT INTO url_old(id, workspace_id, alias, url, expires_at, created_at, updated_at, disabled, api_key_id)
+	SELECT id, workspace_id, alias, url, expires_at, created_at, updated_at, disabled, api_key_id FROM url;
+
+DROP TABLE url;
+ALTER TABLE url_old RENAME TO url;
+
+CREATE INDEX IF NOT EXISTS idx_url_expires_at ON url(expires_at);
+CREATE INDEX IF NOT EXISTS idx_url_created_at ON url(workspace_id, created_at, id);
+
+-- the first verified domain of a workspace becomes its hostname
+CREATE TABLE workspaces_old(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	slug TEXT NOT NULL UNIQUE,
+	hostname TEXT UNIQUE,
+	created_at TIMESTAMP NOT NULL);
+
+INSERT INTO workspaces_old(id, name, slug, hostname, created_at)
+	SELECT w.id, w.name, w.slug,
+		(SELECT hostname FROM domains d WHERE d.workspace_id = w.id AND d.verified_at IS NOT NULL ORDER BY d.id LIMIT 1),
+		w.created_at
+	FROM workspaces w;
+
+DROP TABLE workspaces;
+ALTER TABLE workspaces_old RENAME TO workspaces;
+
+DROP TABLE IF EXISTS domains;
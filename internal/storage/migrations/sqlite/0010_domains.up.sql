@@ -0,0 +1,54 @@
+CREATE TABLE IF NOT EXISTS domains(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workspace_id INTEGER NOT NULL REFERENCES workspaces(id),
+	hostname TEXT NOT NULL UNIQUE,
+	verification_token TEXT NOT NULL,
+	verified_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_domains_workspace_id ON domains(workspace_id);
+
+-- workspace hostnames were set by admins, they become verified domains
+INSERT INTO domains(workspace_id, hostname, verification_token, verified_at, created_at)
+	SELECT id, hostname, '', created_at, created_at FROM workspaces WHERE hostname IS NOT NULL;
+
+-- sqlite can't drop the UNIQUE hostname column so the table is rebuilt
+CREATE TABLE workspaces_new(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	slug TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP NOT NULL);
+
+INSERT INTO workspaces_new(id, name, slug, created_at) SELECT id, name, slug, created_at FROM workspaces;
+
+DROP TABLE workspaces;
+ALTER TABLE workspaces_new RENAME TO workspaces;
+
+-- aliases are unique per domain, links without a domain are served on the default one
+-- and keep their aliases unique per workspace
+CREATE TABLE url_new(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workspace_id INTEGER NOT NULL DEFAULT 1 REFERENCES workspaces(id),
+	domain_id INTEGER REFERENCES domains(id),
+	alias TEXT NOT NULL,
+	url TEXT NOT NULL,
+	expires_at TIMESTAMP DEFAULT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00',
+	updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00+00:00',
+	disabled BOOLEAN NOT NULL DEFAULT FALSE,
+	api_key_id INTEGER REFERENCES api_keys(id));
+
+-- links of workspaces with a hostname move to its domain
+INSERT INTO url_new(id, workspace_id, domain_id, alias, url, expires_at, created_at, updated_at, disabled, api_key_id)
+	SELECT u.id, u.workspace_id, d.id, u.alias, u.url, u.expires_at, u.created_at, u.updated_at, u.disabled, u.api_key_id
+	FROM url u LEFT JOIN domains d ON d.workspace_id = u.workspace_id;
+
+DROP TABLE url;
+ALTER TABLE url_new RENAME TO url;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_url_domain_alias ON url(domain_id, alias) WHERE domain_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_url_default_alias ON url(workspace_id, alias) WHERE domain_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_url_expires_at ON url(expires_at);
+CREATE INDEX IF NOT EXISTS idx_url_created_at ON url(workspace_id, created_at, id);
+
+ALTER TABLE url_archive ADD COLUMN domain_id INTEGER;
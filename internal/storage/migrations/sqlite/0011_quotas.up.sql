@@ -0,0 +1,9 @@
+-- monthly number of links a workspace may create, NULL is unlimited
+ALTER TABLE workspaces ADD COLUMN monthly_link_quota INTEGER;
+
+-- links created by the workspace per calendar month (UTC), period is 'YYYY-MM'
+CREATE TABLE IF NOT EXISTS workspace_usage(
+	workspace_id INTEGER NOT NULL,
+	period TEXT NOT NULL,
+	links_created INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY(workspace_id, period));
@@ -0,0 +1,4 @@
+-- ids of the sequential alias strategies, only the last row is kept,
+-- AUTOINCREMENT never hands out an id twice
+CREATE TABLE IF NOT EXISTS alias_sequence(
+	id INTEGER PRIMARY KEY AUTOINCREMENT);
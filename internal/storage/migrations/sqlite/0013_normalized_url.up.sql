@@ -0,0 +1,4 @@
+-- normalized destination the links are deduplicated by, existing links are normalized by the server
+-- on startup (NormalizeURLs) as urlnorm can't be expressed in SQL
+ALTER TABLE url ADD COLUMN normalized_url TEXT;
+CREATE INDEX IF NOT EXISTS idx_url_normalized_url ON url(workspace_id, normalized_url);
@@ -0,0 +1 @@
+ALTER TABLE click_rollup_state DROP COLUMN purged_before;
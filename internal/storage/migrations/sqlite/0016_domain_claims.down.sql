@@ -0,0 +1,19 @@
+-- only the verified domain or else the oldest claim of a hostname is kept
+DELETE FROM domains WHERE verified_at IS NULL AND EXISTS (
+	SELECT 1 FROM domains o WHERE o.hostname = domains.hostname AND (o.verified_at IS NOT NULL OR o.id < domains.id));
+
+CREATE TABLE domains_old(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workspace_id INTEGER NOT NULL REFERENCES workspaces(id),
+	hostname TEXT NOT NULL UNIQUE,
+	verification_token TEXT NOT NULL,
+	verified_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL);
+
+INSERT INTO domains_old(id, workspace_id, hostname, verification_token, verified_at, created_at)
+	SELECT id, workspace_id, hostname, verification_token, verified_at, created_at FROM domains;
+
+DROP TABLE domains;
+ALTER TABLE domains_old RENAME TO domains;
+
+CREATE INDEX IF NOT EXISTS idx_domains_workspace_id ON domains(workspace_id);
@@ -0,0 +1,19 @@
+-- unverified claims don't take the hostname, it belongs to the workspace that verifies it first.
+-- sqlite can't drop the UNIQUE hostname column so the table is rebuilt
+CREATE TABLE domains_new(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workspace_id INTEGER NOT NULL REFERENCES workspaces(id),
+	hostname TEXT NOT NULL,
+	verification_token TEXT NOT NULL,
+	verified_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL);
+
+INSERT INTO domains_new(id, workspace_id, hostname, verification_token, verified_at, created_at)
+	SELECT id, workspace_id, hostname, verification_token, verified_at, created_at FROM domains;
+
+DROP TABLE domains;
+ALTER TABLE domains_new RENAME TO domains;
+
+CREATE INDEX IF NOT EXISTS idx_domains_workspace_id ON domains(workspace_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_domains_workspace_hostname ON domains(workspace_id, hostname);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_domains_verified_hostname ON domains(hostname) WHERE verified_at IS NOT NULL;
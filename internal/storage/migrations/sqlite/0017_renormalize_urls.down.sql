@@ -0,0 +1 @@
+UPDATE url SET normalized_url = url WHERE normalized_url IS NULL;
@@ -0,0 +1,2 @@
+-- 0013 used to copy the raw url of existing links, they are normalized again by the server on startup
+UPDATE url SET normalized_url = NULL WHERE normalized_url = url;
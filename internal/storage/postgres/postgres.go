@@ -0,0 +1,1684 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/lib/urlnorm"
+	"short-url/internal/storage"
+	"short-url/internal/storage/migrations"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// unique_violation, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const uniqueViolation = "23505"
+
+type Storage struct {
+	db *sql.DB
+}
+
+// event loaded from events table
+type event struct {
+	ID        int       `db:"id"`
+	EventType string    `db:"event_type"`
+	Payload   string    `db:"payload"`
+	Attempts  int       `db:"attempts"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func New(dsn string) (*Storage, error) {
+	const op = "storage.postgres.New"
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Storage{db}, nil
+}
+
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+// Migrator returns the schema migrator bound to this database.
+func (s *Storage) Migrator() (*migrations.Migrator, error) {
+	return migrations.New(s.db, migrations.DialectPostgres)
+}
+
+func (s *Storage) SaveURL(link domain.Link) (id int64, err error) {
+	const op = "storage.postgres.SaveURL"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	domainID, err := verifiedDomainID(tx, link)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	id, err = s.insertURL(tx, link, domainID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// SaveOrGetURL saves the link unless the workspace has an active link to the same normalized url
+// on the domain of the link, then that link is returned with created false and nothing is saved.
+func (s *Storage) SaveOrGetURL(link domain.Link) (_ domain.Link, created bool, err error) {
+	const op = "storage.postgres.SaveOrGetURL"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return domain.Link{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	domainID, err := verifiedDomainID(tx, link)
+	if err != nil {
+		return domain.Link{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	//concurrent saves of the same url wait for each other instead of both missing the lookup
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))",
+		fmt.Sprintf("url:%d:%s:%s", link.WorkspaceID, link.Domain, urlnorm.Normalize(link.URL)))
+	if err != nil {
+		return domain.Link{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	existing, err := scanLink(tx.QueryRow("SELECT "+linkColumns+` FROM url
+	WHERE workspace_id=$1 AND domain_id IS NOT DISTINCT FROM $2 AND normalized_url=$3
+		AND disabled=FALSE AND (expires_at IS NULL OR expires_at > $4)
+	ORDER BY id DESC LIMIT 1`,
+		link.WorkspaceID, domainID, urlnorm.Normalize(link.URL), time.Now().UTC()))
+	if err == nil {
+		_ = tx.Rollback()
+		return existing, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return domain.Link{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	link.ID, err = s.insertURL(tx, link, domainID)
+	if err != nil {
+		return domain.Link{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return domain.Link{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+	return link, true, nil
+}
+
+// SaveURLs saves the links in one transaction with their url_saved events and returns the result of each of them.
+// Every link is saved in a savepoint, so a rejected one (see storage.Rejected) doesn't affect the others.
+// With opts.Atomic the transaction is rolled back on the first rejected link: only its result is set
+// and nothing is saved. With opts.DryRun the transaction is always rolled back.
+func (s *Storage) SaveURLs(links []domain.Link, opts storage.SaveOptions) (_ []storage.SaveResult, err error) {
+	const op = "storage.postgres.SaveURLs"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	results := make([]storage.SaveResult, len(links))
+	for i, link := range links {
+		if !opts.Atomic {
+			if _, err = tx.Exec("SAVEPOINT link"); err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		var domainID sql.NullInt64
+		domainID, err = verifiedDomainID(tx, link)
+		if err == nil {
+			results[i].ID, err = s.insertURL(tx, link, domainID)
+		}
+		if err != nil && !storage.Rejected(err) {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if err != nil && opts.Atomic {
+			_ = tx.Rollback()
+			results = make([]storage.SaveResult, len(links))
+			results[i].Err = err
+			return results, nil
+		}
+		if err != nil {
+			results[i].Err = err
+			if _, err = tx.Exec("ROLLBACK TO SAVEPOINT link"); err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		if !opts.Atomic {
+			if _, err = tx.Exec("RELEASE SAVEPOINT link"); err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+
+	if opts.DryRun {
+		_ = tx.Rollback()
+		return results, nil
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return results, nil
+}
+
+// NormalizeURLs sets the normalized url of up to limit links saved before it was stored,
+// SaveOrGetURL doesn't find them until then. It returns the number of normalized links.
+func (s *Storage) NormalizeURLs(limit int) (n int, err error) {
+	const op = "storage.postgres.NormalizeURLs"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	rows, err := tx.Query("SELECT id, url FROM url WHERE normalized_url IS NULL ORDER BY id LIMIT $1", limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	urls := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var url string
+		if err = rows.Scan(&id, &url); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		urls[id] = url
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for id, url := range urls {
+		if _, err = tx.Exec("UPDATE url SET normalized_url=$1 WHERE id=$2", urlnorm.Normalize(url), id); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return len(urls), nil
+}
+
+// insertURL counts the link in the quota of the workspace, inserts it and writes the url_saved event.
+func (s *Storage) insertURL(tx *sql.Tx, link domain.Link, domainID sql.NullInt64) (int64, error) {
+	now := time.Now().UTC()
+	if err := takeQuota(tx, link.WorkspaceID, now); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := tx.QueryRow(`
+	INSERT INTO url(workspace_id, domain_id, url, normalized_url, alias, expires_at, created_at, updated_at, api_key_id, disabled)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`,
+		link.WorkspaceID, domainID, link.URL, urlnorm.Normalize(link.URL), link.Alias, nullTime(link.ExpiresAt), now, now, link.APIKeyID, link.Disabled).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, storage.ErrURLExists
+		}
+		return 0, err
+	}
+
+	//save event to events table
+	payload := domain.URLSavedPayload{
+		SchemaVersion: domain.PayloadSchemaVersion,
+		ID:            id,
+		WorkspaceID:   link.WorkspaceID,
+		Domain:        link.Domain,
+		URL:           link.URL,
+		Alias:         link.Alias,
+		ExpiresAt:     link.ExpiresAt,
+	}
+	if err := s.saveEvent(tx, domain.EventURLSaved, payload); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// NextAliasID returns the next id of the alias sequence, ids are never reused.
+func (s *Storage) NextAliasID() (int64, error) {
+	const op = "storage.postgres.NextAliasID"
+
+	var id int64
+	if err := s.db.QueryRow("SELECT nextval('alias_sequence')").Scan(&id); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// verifiedDomainID returns the id of the link domain, NULL for the default domain.
+// Domains of other workspaces and unverified ones are reported as storage.ErrDomainNotFound.
+func verifiedDomainID(tx *sql.Tx, link domain.Link) (sql.NullInt64, error) {
+	var id sql.NullInt64
+	if link.Domain == "" {
+		return id, nil
+	}
+	err := tx.QueryRow("SELECT id FROM domains WHERE workspace_id=$1 AND hostname=$2 AND verified_at IS NOT NULL",
+		link.WorkspaceID, link.Domain).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return id, storage.ErrDomainNotFound
+	}
+	return id, err
+}
+
+// takeQuota counts the new link in the usage of the workspace and returns storage.ErrQuotaExceeded
+// if it goes over the monthly quota, the count is rolled back with the transaction.
+func takeQuota(tx *sql.Tx, workspaceID int64, now time.Time) error {
+	var created int64
+	err := tx.QueryRow(`INSERT INTO workspace_usage(workspace_id, period, links_created) VALUES($1, $2, 1)
+		ON CONFLICT(workspace_id, period) DO UPDATE SET links_created = workspace_usage.links_created + 1
+		RETURNING links_created`, workspaceID, domain.UsagePeriod(now)).Scan(&created)
+	if err != nil {
+		return err
+	}
+
+	var quota sql.NullInt64
+	err = tx.QueryRow("SELECT monthly_link_quota FROM workspaces WHERE id=$1", workspaceID).Scan(&quota)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if quota.Valid && created > quota.Int64 {
+		return storage.ErrQuotaExceeded
+	}
+	return nil
+}
+
+func (s *Storage) saveEvent(tx *sql.Tx, eventType string, payload any) error {
+	const op = "storage.postgres.saveEvent"
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = tx.Exec("INSERT INTO events(event_type, payload) VALUES($1, $2)", eventType, string(data))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetURL returns the link to redirect to, storage.ErrURLExpired for links past their expires_at
+// even if the janitor hasn't purged them yet. Disabled links are reported as storage.ErrURLNotFound.
+func (s *Storage) GetURL(ref domain.LinkRef) (domain.Link, error) {
+	const op = "storage.postgres.GetURL"
+
+	where, args := linkWhere(ref)
+	link, err := scanLink(s.db.QueryRow("SELECT "+linkColumns+" FROM url WHERE "+where+" AND NOT disabled", args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Link{}, fmt.Errorf("%s: %w", op, storage.ErrURLNotFound)
+		}
+		return domain.Link{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		return domain.Link{}, fmt.Errorf("%s: %w", op, storage.ErrURLExpired)
+	}
+	return link, nil
+}
+
+// DeleteURL deletes the url and writes the url_deleted event in the same transaction.
+func (s *Storage) DeleteURL(ref domain.LinkRef) (err error) {
+	const op = "storage.postgres.DeleteURL"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var id int64
+	var deletedURL string
+	where, args := linkWhere(ref)
+	err = tx.QueryRow("DELETE FROM url WHERE "+where+" RETURNING id, url", args...).Scan(&id, &deletedURL)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrURLNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	payload := domain.URLDeletedPayload{
+		SchemaVersion: domain.PayloadSchemaVersion,
+		ID:            id,
+		WorkspaceID:   ref.WorkspaceID,
+		Domain:        ref.Domain,
+		URL:           deletedURL,
+		Alias:         ref.Alias,
+	}
+	if err = s.saveEvent(tx, domain.EventURLDeleted, payload); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetLink returns the link with its metadata, expired and disabled links included.
+func (s *Storage) GetLink(ref domain.LinkRef) (domain.Link, error) {
+	const op = "storage.postgres.GetLink"
+
+	where, args := linkWhere(ref)
+	link, err := scanLink(s.db.QueryRow("SELECT "+linkColumns+" FROM url WHERE "+where, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Link{}, fmt.Errorf("%s: %w", op, storage.ErrURLNotFound)
+		}
+		return domain.Link{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return link, nil
+}
+
+// UpdateLink applies the update to the link and writes the url_updated event in the same transaction.
+func (s *Storage) UpdateLink(ref domain.LinkRef, update domain.LinkUpdate) (_ domain.Link, err error) {
+	const op = "storage.postgres.UpdateLink"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	where, args := linkWhere(ref)
+	link, err := scanLink(tx.QueryRow("SELECT "+linkColumns+" FROM url WHERE "+where+" FOR UPDATE", args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Link{}, fmt.Errorf("%s: %w", op, storage.ErrURLNotFound)
+		}
+		return domain.Link{}, fmt.Errorf("%s: %w", op, err)
+	}
+	previousURL := link.URL
+	link.Apply(update)
+	link.UpdatedAt = time.Now().UTC()
+
+	_, err = tx.Exec("UPDATE url SET url=$1, normalized_url=$2, expires_at=$3, disabled=$4, updated_at=$5 WHERE id=$6",
+		link.URL, urlnorm.Normalize(link.URL), nullTime(link.ExpiresAt), link.Disabled, link.UpdatedAt, link.ID)
+	if err != nil {
+		return domain.Link{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	payload := domain.URLUpdatedPayload{
+		SchemaVersion: domain.PayloadSchemaVersion,
+		ID:            link.ID,
+		WorkspaceID:   link.WorkspaceID,
+		Domain:        link.Domain,
+		URL:           link.URL,
+		PreviousURL:   previousURL,
+		Alias:         link.Alias,
+		ExpiresAt:     link.ExpiresAt,
+		Disabled:      link.Disabled,
+	}
+	if err = s.saveEvent(tx, domain.EventURLUpdated, payload); err != nil {
+		return domain.Link{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return domain.Link{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return link, nil
+}
+
+// ListLinks returns up to query.Limit links matching the query, continuing after query.After.
+func (s *Storage) ListLinks(query domain.LinkQuery) ([]domain.Link, error) {
+	const op = "storage.postgres.ListLinks"
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	where := []string{"workspace_id = " + arg(query.WorkspaceID)}
+	if query.Prefix != "" {
+		where = append(where, "starts_with(alias, "+arg(query.Prefix)+")")
+	}
+	if query.CreatedFrom != nil {
+		where = append(where, "created_at >= "+arg(query.CreatedFrom.UTC()))
+	}
+	if query.CreatedTo != nil {
+		where = append(where, "created_at < "+arg(query.CreatedTo.UTC()))
+	}
+
+	cmp, dir := ">", "ASC"
+	if query.Desc {
+		cmp, dir = "<", "DESC"
+	}
+	orderBy := "created_at " + dir + ", id " + dir
+	if query.SortBy == domain.LinkSortAlias {
+		//an alias may be taken on the default domain and on custom domains, id breaks the tie
+		orderBy = "alias " + dir + ", id " + dir
+	}
+	if query.After != nil {
+		if query.SortBy == domain.LinkSortAlias {
+			where = append(where, "(alias, id) "+cmp+" ("+arg(query.After.Alias)+", "+arg(query.After.ID)+")")
+		} else {
+			where = append(where, "(created_at, id) "+cmp+" ("+arg(query.After.CreatedAt.UTC())+", "+arg(query.After.ID)+")")
+		}
+	}
+
+	rows, err := s.db.Query(`
+	SELECT `+linkColumns+` FROM url
+	WHERE `+strings.Join(where, " AND ")+`
+	ORDER BY `+orderBy+`
+	LIMIT `+arg(query.Limit), args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	links := []domain.Link{}
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return links, nil
+}
+
+const linkColumns = "id, workspace_id, alias, url, expires_at, disabled, created_at, updated_at, api_key_id, " +
+	"(SELECT hostname FROM domains WHERE domains.id = url.domain_id)"
+
+// linkWhere is the condition of the url table matching the link of ref
+func linkWhere(ref domain.LinkRef) (string, []any) {
+	if ref.Domain == "" {
+		return "workspace_id=$1 AND domain_id IS NULL AND alias=$2", []any{ref.WorkspaceID, ref.Alias}
+	}
+	return "workspace_id=$1 AND domain_id=(SELECT id FROM domains WHERE hostname=$2 AND verified_at IS NOT NULL) AND alias=$3",
+		[]any{ref.WorkspaceID, ref.Domain, ref.Alias}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanLink scans a row of linkColumns
+func scanLink(row rowScanner) (domain.Link, error) {
+	var link domain.Link
+	var expiresAt sql.NullTime
+	var apiKeyID sql.NullInt64
+	var hostname sql.NullString
+	err := row.Scan(&link.ID, &link.WorkspaceID, &link.Alias, &link.URL, &expiresAt, &link.Disabled, &link.CreatedAt, &link.UpdatedAt, &apiKeyID, &hostname)
+	if err != nil {
+		return domain.Link{}, err
+	}
+	if expiresAt.Valid {
+		t := expiresAt.Time.UTC()
+		link.ExpiresAt = &t
+	}
+	if apiKeyID.Valid {
+		link.APIKeyID = &apiKeyID.Int64
+	}
+	link.Domain = hostname.String
+	link.CreatedAt = link.CreatedAt.UTC()
+	link.UpdatedAt = link.UpdatedAt.UTC()
+	return link, nil
+}
+
+// CreateAPIKey stores a new key by the hash of its secret and returns its id.
+func (s *Storage) CreateAPIKey(key domain.APIKey, keyHash string) (int64, error) {
+	const op = "storage.postgres.CreateAPIKey"
+
+	//the key is only inserted into an existing workspace
+	var id int64
+	err := s.db.QueryRow(`
+	INSERT INTO api_keys(workspace_id, name, prefix, key_hash, scopes, created_at)
+	SELECT id, $1, $2, $3, $4, $5 FROM workspaces WHERE id=$6
+	RETURNING id`,
+		key.Name, key.Prefix, keyHash, strings.Join(key.Scopes, " "), key.CreatedAt.UTC(), key.WorkspaceID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrWorkspaceNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// GetAPIKeyByHash returns the active key with the hash, revoked keys are reported as storage.ErrAPIKeyNotFound.
+func (s *Storage) GetAPIKeyByHash(keyHash string) (domain.APIKey, error) {
+	const op = "storage.postgres.GetAPIKeyByHash"
+
+	key, err := scanAPIKey(s.db.QueryRow(
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash=$1 AND revoked_at IS NULL", keyHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.APIKey{}, fmt.Errorf("%s: %w", op, storage.ErrAPIKeyNotFound)
+		}
+		return domain.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return key, nil
+}
+
+// RevokeAPIKey disables the key of the workspace (storage.AnyWorkspace for any), links created by it are kept.
+func (s *Storage) RevokeAPIKey(workspaceID, id int64) error {
+	const op = "storage.postgres.RevokeAPIKey"
+
+	res, err := s.db.Exec(`
+	UPDATE api_keys SET revoked_at=$1
+	WHERE id=$2 AND ($3::bigint=0 OR workspace_id=$3) AND revoked_at IS NULL`, time.Now().UTC(), id, workspaceID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAPIKeyNotFound)
+	}
+	return nil
+}
+
+// RotateAPIKey replaces the secret of an active key of the workspace (storage.AnyWorkspace for any),
+// the previous secret stops working at once.
+func (s *Storage) RotateAPIKey(workspaceID, id int64, prefix string, keyHash string) (domain.APIKey, error) {
+	const op = "storage.postgres.RotateAPIKey"
+
+	key, err := scanAPIKey(s.db.QueryRow(`
+	UPDATE api_keys SET prefix=$1, key_hash=$2
+	WHERE id=$3 AND ($4::bigint=0 OR workspace_id=$4) AND revoked_at IS NULL
+	RETURNING `+apiKeyColumns, prefix, keyHash, id, workspaceID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.APIKey{}, fmt.Errorf("%s: %w", op, storage.ErrAPIKeyNotFound)
+		}
+		return domain.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return key, nil
+}
+
+const apiKeyColumns = "id, workspace_id, name, prefix, scopes, created_at, revoked_at"
+
+// scanAPIKey scans a row of apiKeyColumns
+func scanAPIKey(row rowScanner) (domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes string
+	var revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.WorkspaceID, &key.Name, &key.Prefix, &scopes, &key.CreatedAt, &revokedAt); err != nil {
+		return domain.APIKey{}, err
+	}
+	key.Scopes = strings.Fields(scopes)
+	key.CreatedAt = key.CreatedAt.UTC()
+	if revokedAt.Valid {
+		t := revokedAt.Time.UTC()
+		key.RevokedAt = &t
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns the keys of the workspace, revoked ones included.
+func (s *Storage) ListAPIKeys(workspaceID int64) ([]domain.APIKey, error) {
+	const op = "storage.postgres.ListAPIKeys"
+
+	rows, err := s.db.Query("SELECT "+apiKeyColumns+" FROM api_keys WHERE workspace_id=$1 ORDER BY id", workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return keys, nil
+}
+
+// CreateWorkspace returns storage.ErrWorkspaceExists if the slug is taken.
+func (s *Storage) CreateWorkspace(ws domain.Workspace) (int64, error) {
+	const op = "storage.postgres.CreateWorkspace"
+
+	var id int64
+	err := s.db.QueryRow("INSERT INTO workspaces(name, slug, monthly_link_quota, created_at) VALUES($1, $2, $3, $4) RETURNING id",
+		ws.Name, ws.Slug, ws.MonthlyLinkQuota, ws.CreatedAt.UTC()).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrWorkspaceExists)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// GetWorkspace returns storage.ErrWorkspaceNotFound for unknown ids.
+func (s *Storage) GetWorkspace(id int64) (domain.Workspace, error) {
+	const op = "storage.postgres.GetWorkspace"
+
+	ws, err := scanWorkspace(s.db.QueryRow("SELECT "+workspaceColumns+" FROM workspaces WHERE id=$1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Workspace{}, fmt.Errorf("%s: %w", op, storage.ErrWorkspaceNotFound)
+		}
+		return domain.Workspace{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return ws, nil
+}
+
+// GetWorkspaceBySlug returns storage.ErrWorkspaceNotFound for unknown slugs.
+func (s *Storage) GetWorkspaceBySlug(slug string) (domain.Workspace, error) {
+	const op = "storage.postgres.GetWorkspaceBySlug"
+
+	ws, err := scanWorkspace(s.db.QueryRow("SELECT "+workspaceColumns+" FROM workspaces WHERE slug=$1", slug))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Workspace{}, fmt.Errorf("%s: %w", op, storage.ErrWorkspaceNotFound)
+		}
+		return domain.Workspace{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return ws, nil
+}
+
+// SetWorkspaceQuota sets the monthly link quota of the workspace, nil removes it.
+// Returns storage.ErrWorkspaceNotFound for unknown ids.
+func (s *Storage) SetWorkspaceQuota(id int64, quota *int64) error {
+	const op = "storage.postgres.SetWorkspaceQuota"
+
+	res, err := s.db.Exec("UPDATE workspaces SET monthly_link_quota=$1 WHERE id=$2", quota, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrWorkspaceNotFound)
+	}
+	return nil
+}
+
+// GetWorkspaceUsage returns the links created by the workspace in the period, zero if it created none.
+func (s *Storage) GetWorkspaceUsage(workspaceID int64, period string) (domain.WorkspaceUsage, error) {
+	const op = "storage.postgres.GetWorkspaceUsage"
+
+	usage := domain.WorkspaceUsage{WorkspaceID: workspaceID, Period: period}
+	err := s.db.QueryRow("SELECT links_created FROM workspace_usage WHERE workspace_id=$1 AND period=$2",
+		workspaceID, period).Scan(&usage.LinksCreated)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return domain.WorkspaceUsage{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return usage, nil
+}
+
+const workspaceColumns = "id, name, slug, monthly_link_quota, created_at"
+
+// scanWorkspace scans a row of workspaceColumns
+func scanWorkspace(row rowScanner) (domain.Workspace, error) {
+	var ws domain.Workspace
+	var quota sql.NullInt64
+	if err := row.Scan(&ws.ID, &ws.Name, &ws.Slug, &quota, &ws.CreatedAt); err != nil {
+		return domain.Workspace{}, err
+	}
+	if quota.Valid {
+		ws.MonthlyLinkQuota = &quota.Int64
+	}
+	ws.CreatedAt = ws.CreatedAt.UTC()
+	return ws, nil
+}
+
+// CreateDomain claims the hostname for the workspace until a workspace verifies it.
+// It returns storage.ErrDomainExists if the workspace already claimed it or another one verified it.
+func (s *Storage) CreateDomain(d domain.Domain) (int64, error) {
+	const op = "storage.postgres.CreateDomain"
+
+	var id int64
+	err := s.db.QueryRow(`
+	INSERT INTO domains(workspace_id, hostname, verification_token, created_at)
+	SELECT $1::bigint, $2::text, $3::text, $4::timestamptz WHERE NOT EXISTS (SELECT 1 FROM domains WHERE hostname=$2 AND verified_at IS NOT NULL)
+	RETURNING id`,
+		d.WorkspaceID, d.Hostname, d.VerificationToken, d.CreatedAt.UTC()).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) || errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrDomainExists)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// GetDomain returns the verified domain of the hostname, storage.ErrDomainNotFound if no workspace verified it.
+func (s *Storage) GetDomain(hostname string) (domain.Domain, error) {
+	const op = "storage.postgres.GetDomain"
+
+	d, err := scanDomain(s.db.QueryRow("SELECT "+domainColumns+" FROM domains WHERE hostname=$1 AND verified_at IS NOT NULL", hostname))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Domain{}, fmt.Errorf("%s: %w", op, storage.ErrDomainNotFound)
+		}
+		return domain.Domain{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return d, nil
+}
+
+// GetWorkspaceDomain returns the domain the workspace claimed for the hostname, verified or not.
+func (s *Storage) GetWorkspaceDomain(workspaceID int64, hostname string) (domain.Domain, error) {
+	const op = "storage.postgres.GetWorkspaceDomain"
+
+	d, err := scanDomain(s.db.QueryRow("SELECT "+domainColumns+" FROM domains WHERE workspace_id=$1 AND hostname=$2", workspaceID, hostname))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Domain{}, fmt.Errorf("%s: %w", op, storage.ErrDomainNotFound)
+		}
+		return domain.Domain{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return d, nil
+}
+
+// ListDomains returns the domains of the workspace ordered by id.
+func (s *Storage) ListDomains(workspaceID int64) ([]domain.Domain, error) {
+	const op = "storage.postgres.ListDomains"
+
+	rows, err := s.db.Query("SELECT "+domainColumns+" FROM domains WHERE workspace_id=$1 ORDER BY id", workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	domains := []domain.Domain{}
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		domains = append(domains, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return domains, nil
+}
+
+// VerifyDomain marks the domain as verified, its links are redirected from then on.
+// The claims of other workspaces on the hostname are deleted, storage.ErrDomainExists is returned
+// if another workspace verified it first.
+func (s *Storage) VerifyDomain(id int64, verifiedAt time.Time) (err error) {
+	const op = "storage.postgres.VerifyDomain"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.Exec("UPDATE domains SET verified_at=$1 WHERE id=$2", verifiedAt.UTC(), id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrDomainExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrDomainNotFound)
+	}
+
+	_, err = tx.Exec(`
+	DELETE FROM domains WHERE verified_at IS NULL AND hostname=(SELECT hostname FROM domains WHERE id=$1)`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+const domainColumns = "id, workspace_id, hostname, verification_token, verified_at, created_at"
+
+// scanDomain scans a row of domainColumns
+func scanDomain(row rowScanner) (domain.Domain, error) {
+	var d domain.Domain
+	var verifiedAt sql.NullTime
+	if err := row.Scan(&d.ID, &d.WorkspaceID, &d.Hostname, &d.VerificationToken, &verifiedAt, &d.CreatedAt); err != nil {
+		return domain.Domain{}, err
+	}
+	if verifiedAt.Valid {
+		t := verifiedAt.Time.UTC()
+		d.VerifiedAt = &t
+	}
+	d.CreatedAt = d.CreatedAt.UTC()
+	return d, nil
+}
+
+// SaveClicks writes a batch of clicks in one transaction, sampled clicks also get a url_clicked event.
+func (s *Storage) SaveClicks(clicks []domain.Click) (err error) {
+	const op = "storage.postgres.SaveClicks"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.Prepare(`
+	INSERT INTO clicks(url_id, clicked_at, referrer, referrer_host, user_agent, browser, country, ip_hash)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8)`)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	for _, click := range clicks {
+		_, err = stmt.Exec(click.LinkID, click.ClickedAt.UTC(), click.Referrer, click.ReferrerHost,
+			click.UserAgent, click.Browser, click.Country, click.IPHash)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if !click.Sampled {
+			continue
+		}
+		payload := domain.URLClickedPayload{
+			SchemaVersion: domain.PayloadSchemaVersion,
+			ID:            click.LinkID,
+			WorkspaceID:   click.WorkspaceID,
+			Domain:        click.Domain,
+			Alias:         click.Alias,
+			ClickedAt:     click.ClickedAt.UTC(),
+		}
+		if err = s.saveEvent(tx, domain.EventURLClicked, payload); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetClickStats counts clicks of the link in [from, to) grouped by hour or day buckets in UTC.
+// Stats are read from the rollups when they cover the range and from the raw clicks otherwise.
+func (s *Storage) GetClickStats(linkID int64, from, to time.Time, bucket string) (domain.ClickStats, error) {
+	const op = "storage.postgres.GetClickStats"
+
+	var alias string
+	err := s.db.QueryRow("SELECT alias FROM url WHERE id=$1", linkID).Scan(&alias)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ClickStats{}, fmt.Errorf("%s: %w", op, storage.ErrURLNotFound)
+		}
+		return domain.ClickStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rolledUpTo, err := rolledUpTo(s.db)
+	if err != nil {
+		return domain.ClickStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+	purgedBefore, err := clicksPurgedBefore(s.db)
+	if err != nil {
+		return domain.ClickStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stats := domain.ClickStats{
+		Alias:   alias,
+		From:    from.UTC(),
+		To:      to.UTC(),
+		Bucket:  bucket,
+		Source:  domain.StatsSourceRaw,
+		Buckets: []domain.ClickBucket{},
+	}
+	if storage.RollupCovers(from, to, bucket, rolledUpTo) {
+		stats.Source = domain.StatsSourceRollup
+	}
+	args := []any{linkID, from.UTC(), to.UTC()}
+
+	var totalsQuery, bucketsQuery string
+	if stats.Source == domain.StatsSourceRollup {
+		table := "click_rollups_hourly"
+		if bucket == domain.BucketDay {
+			table = "click_rollups_daily"
+		}
+		totalsQuery = `
+		SELECT COALESCE(SUM(clicks), 0) FROM ` + table + `
+		WHERE url_id=$1 AND bucket_start >= $2 AND bucket_start < $3`
+		bucketsQuery = `
+		SELECT bucket_start, clicks, unique_visitors FROM ` + table + `
+		WHERE url_id=$1 AND bucket_start >= $2 AND bucket_start < $3
+		ORDER BY bucket_start`
+	} else {
+		totalsQuery = `
+		SELECT COUNT(*) FROM clicks
+		WHERE url_id=$1 AND clicked_at >= $2 AND clicked_at < $3`
+		bucketsQuery = `
+		SELECT ` + bucketExpr(bucket) + ` AS bucket, COUNT(*), COUNT(DISTINCT ip_hash) FROM clicks
+		WHERE url_id=$1 AND clicked_at >= $2 AND clicked_at < $3
+		GROUP BY bucket
+		ORDER BY bucket`
+	}
+
+	err = s.db.QueryRow(totalsQuery, args...).Scan(&stats.Total)
+	if err != nil {
+		return domain.ClickStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+	//a visitor of several buckets is counted once per bucket in the rollups, so unique visitors of the range
+	//are counted from the raw clicks as long as none of the range are purged
+	if stats.Source == domain.StatsSourceRaw || purgedBefore.IsZero() || !from.Before(purgedBefore) {
+		var unique int
+		err = s.db.QueryRow(`
+		SELECT COUNT(DISTINCT ip_hash) FROM clicks
+		WHERE url_id=$1 AND clicked_at >= $2 AND clicked_at < $3`, args...).Scan(&unique)
+		if err != nil {
+			return domain.ClickStats{}, fmt.Errorf("%s: %w", op, err)
+		}
+		stats.UniqueVisitors = &unique
+	}
+
+	rows, err := s.db.Query(bucketsQuery, args...)
+	if err != nil {
+		return domain.ClickStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b domain.ClickBucket
+		if err := rows.Scan(&b.Start, &b.Clicks, &b.UniqueVisitors); err != nil {
+			return domain.ClickStats{}, fmt.Errorf("%s: %w", op, err)
+		}
+		b.Start = b.Start.UTC()
+		stats.Buckets = append(stats.Buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.ClickStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, dim := range []struct {
+		name string
+		dst  *[]domain.ClickCount
+	}{
+		{domain.DimensionReferrer, &stats.Referrers},
+		{domain.DimensionBrowser, &stats.Browsers},
+		{domain.DimensionCountry, &stats.Countries},
+	} {
+		*dim.dst, err = s.clickBreakdown(dim.name, stats.Source == domain.StatsSourceRollup, args)
+		if err != nil {
+			return domain.ClickStats{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return stats, nil
+}
+
+// CountClicks returns the total clicks of the links: the rolled up hours and the raw clicks after them.
+// Links without clicks are missing from the map.
+func (s *Storage) CountClicks(linkIDs []int64) (map[int64]int64, error) {
+	const op = "storage.postgres.CountClicks"
+
+	totals := make(map[int64]int64, len(linkIDs))
+	if len(linkIDs) == 0 {
+		return totals, nil
+	}
+	rolledUpTo, err := rolledUpTo(s.db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.Query(`
+	SELECT url_id, SUM(n) FROM (
+		SELECT url_id, clicks AS n FROM click_rollups_hourly WHERE url_id = ANY($1) AND bucket_start < $2
+		UNION ALL
+		SELECT url_id, 1 AS n FROM clicks WHERE url_id = ANY($1) AND clicked_at >= $2) c
+	GROUP BY url_id`, pq.Array(linkIDs), rolledUpTo)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, total int64
+		if err := rows.Scan(&id, &total); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		totals[id] = total
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return totals, nil
+}
+
+// clickBreakdown returns the top values of the dimension, args are the link id, from and to.
+func (s *Storage) clickBreakdown(dimension string, rollup bool, args []any) ([]domain.ClickCount, error) {
+	var rows *sql.Rows
+	var err error
+	if rollup {
+		rows, err = s.db.Query(`
+		SELECT value, SUM(clicks) AS n FROM click_breakdowns_hourly
+		WHERE url_id=$1 AND bucket_start >= $2 AND bucket_start < $3 AND dimension=$4
+		GROUP BY value
+		ORDER BY n DESC, value
+		LIMIT $5`, append(args, dimension, storage.BreakdownLimit)...)
+	} else {
+		rows, err = s.db.Query(`
+		SELECT `+breakdownColumns[dimension]+` AS value, COUNT(*) AS n FROM clicks
+		WHERE url_id=$1 AND clicked_at >= $2 AND clicked_at < $3
+		GROUP BY value
+		ORDER BY n DESC, value
+		LIMIT $4`, append(args, storage.BreakdownLimit)...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []domain.ClickCount{}
+	for rows.Next() {
+		var c domain.ClickCount
+		if err := rows.Scan(&c.Value, &c.Clicks); err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+
+	return res, rows.Err()
+}
+
+// RollupClicks aggregates the raw clicks up to the hour-aligned to into the hourly and daily rollups,
+// continuing from the previous run. The daily rollup of the current day is recomputed until the day is over.
+// It returns the time the clicks are rolled up to.
+func (s *Storage) RollupClicks(to time.Time) (_ time.Time, err error) {
+	const op = "storage.postgres.RollupClicks"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	//serializes concurrent rollups of several replicas
+	if _, err = tx.Exec("LOCK TABLE click_rollup_state IN EXCLUSIVE MODE"); err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	to = to.UTC().Truncate(time.Hour)
+	from, err := rolledUpTo(tx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if from.IsZero() {
+		//the first run starts from the oldest click
+		err = tx.QueryRow("SELECT clicked_at FROM clicks ORDER BY clicked_at LIMIT 1").Scan(&from)
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, tx.Commit()
+		}
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: %w", op, err)
+		}
+		from = from.UTC().Truncate(time.Hour)
+	}
+	if !from.Before(to) {
+		return from, tx.Commit()
+	}
+	dayFrom := from.Truncate(24 * time.Hour)
+
+	type statement struct {
+		query string
+		args  []any
+	}
+	statements := []statement{
+		{"DELETE FROM click_rollups_hourly WHERE bucket_start >= $1 AND bucket_start < $2", []any{from, to}},
+		{`
+		INSERT INTO click_rollups_hourly(url_id, bucket_start, clicks, unique_visitors)
+		SELECT url_id, ` + bucketExpr(domain.BucketHour) + ` AS bucket, COUNT(*), COUNT(DISTINCT ip_hash) FROM clicks
+		WHERE clicked_at >= $1 AND clicked_at < $2
+		GROUP BY url_id, bucket`, []any{from, to}},
+		{"DELETE FROM click_breakdowns_hourly WHERE bucket_start >= $1 AND bucket_start < $2", []any{from, to}},
+		{"DELETE FROM click_rollups_daily WHERE bucket_start >= $1 AND bucket_start < $2", []any{dayFrom, to}},
+		{`
+		INSERT INTO click_rollups_daily(url_id, bucket_start, clicks, unique_visitors)
+		SELECT url_id, ` + bucketExpr(domain.BucketDay) + ` AS bucket, COUNT(*), COUNT(DISTINCT ip_hash) FROM clicks
+		WHERE clicked_at >= $1 AND clicked_at < $2
+		GROUP BY url_id, bucket`, []any{dayFrom, to}},
+	}
+	for _, dimension := range []string{domain.DimensionReferrer, domain.DimensionBrowser, domain.DimensionCountry} {
+		column := breakdownColumns[dimension]
+		statements = append(statements, statement{`
+		INSERT INTO click_breakdowns_hourly(url_id, bucket_start, dimension, value, clicks)
+		SELECT url_id, ` + bucketExpr(domain.BucketHour) + ` AS bucket, $1::text, ` + column + `, COUNT(*) FROM clicks
+		WHERE clicked_at >= $2 AND clicked_at < $3
+		GROUP BY url_id, bucket, ` + column, []any{dimension, from, to}})
+	}
+	statements = append(statements, statement{`
+	INSERT INTO click_rollup_state(id, rolled_up_to) VALUES(1, $1)
+	ON CONFLICT(id) DO UPDATE SET rolled_up_to=excluded.rolled_up_to`, []any{to}})
+
+	for _, st := range statements {
+		if _, err = tx.Exec(st.query, st.args...); err != nil {
+			return time.Time{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return to, nil
+}
+
+// PurgeClicks deletes up to limit raw clicks older than before and returns the number of deleted ones.
+// before is recorded first, GetClickStats doesn't count unique visitors from the clicks before it.
+func (s *Storage) PurgeClicks(before time.Time, limit int) (int, error) {
+	const op = "storage.postgres.PurgeClicks"
+
+	_, err := s.db.Exec(`
+	UPDATE click_rollup_state SET purged_before=$1
+	WHERE id=1 AND (purged_before IS NULL OR purged_before < $1)`, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := s.db.Exec(`
+	DELETE FROM clicks WHERE id IN (
+		SELECT id FROM clicks WHERE clicked_at < $1 ORDER BY id LIMIT $2)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	n, _ := res.RowsAffected()
+
+	return int(n), nil
+}
+
+// columns of the clicks table by breakdown dimension
+var breakdownColumns = map[string]string{
+	domain.DimensionReferrer: "referrer_host",
+	domain.DimensionBrowser:  "browser",
+	domain.DimensionCountry:  "country",
+}
+
+// bucketExpr truncates clicked_at to the bucket start in UTC
+func bucketExpr(bucket string) string {
+	if bucket == domain.BucketDay {
+		return "date_trunc('day', clicked_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'"
+	}
+	return "date_trunc('hour', clicked_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'"
+}
+
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// rolledUpTo returns the time the clicks are rolled up to, zero before the first rollup
+func rolledUpTo(q queryRower) (time.Time, error) {
+	var t time.Time
+	err := q.QueryRow("SELECT rolled_up_to FROM click_rollup_state WHERE id=1").Scan(&t)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return t.UTC(), err
+}
+
+// clicksPurgedBefore returns the time the raw clicks are purged before, zero if none are
+func clicksPurgedBefore(q queryRower) (time.Time, error) {
+	var t sql.NullTime
+	err := q.QueryRow("SELECT purged_before FROM click_rollup_state WHERE id=1").Scan(&t)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return t.Time.UTC(), err
+}
+
+// PurgeExpiredURLs deletes up to limit links expired before now, copying them to url_archive if archive is set.
+// A url_expired event is written for each of them in the same transaction. It returns the number of purged links.
+func (s *Storage) PurgeExpiredURLs(now time.Time, limit int, archive bool) (n int, err error) {
+	const op = "storage.postgres.PurgeExpiredURLs"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	rows, err := tx.Query(`
+	SELECT id, workspace_id, domain_id, (SELECT hostname FROM domains WHERE domains.id = url.domain_id), alias, url, expires_at FROM url
+	WHERE expires_at IS NOT NULL AND expires_at <= $1
+	ORDER BY expires_at
+	LIMIT $2`, now.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	type expiredLink struct {
+		domain.Link
+		domainID sql.NullInt64
+	}
+	var expired []expiredLink
+	for rows.Next() {
+		var link expiredLink
+		var hostname sql.NullString
+		var expiresAt time.Time
+		if err = rows.Scan(&link.ID, &link.WorkspaceID, &link.domainID, &hostname, &link.Alias, &link.URL, &expiresAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		link.Domain = hostname.String
+		link.ExpiresAt = &expiresAt
+		expired = append(expired, link)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, link := range expired {
+		if archive {
+			_, err = tx.Exec("INSERT INTO url_archive(id, workspace_id, domain_id, alias, url, expires_at) VALUES($1, $2, $3, $4, $5, $6)",
+				link.ID, link.WorkspaceID, link.domainID, link.Alias, link.URL, link.ExpiresAt.UTC())
+			if err != nil {
+				return 0, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		if _, err = tx.Exec("DELETE FROM url WHERE id=$1", link.ID); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		payload := domain.URLExpiredPayload{
+			SchemaVersion: domain.PayloadSchemaVersion,
+			ID:            link.ID,
+			WorkspaceID:   link.WorkspaceID,
+			Domain:        link.Domain,
+			URL:           link.URL,
+			Alias:         link.Alias,
+			ExpiresAt:     link.ExpiresAt.UTC(),
+			Archived:      archive,
+		}
+		if err = s.saveEvent(tx, domain.EventURLExpired, payload); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return len(expired), nil
+}
+
+// CreateIdempotencyKey reserves the key for a request in progress, an expired record of the key is replaced.
+// Returns storage.ErrIdempotencyKeyExists if the key is already taken in its scope.
+func (s *Storage) CreateIdempotencyKey(key domain.IdempotencyKey) (err error) {
+	const op = "storage.postgres.CreateIdempotencyKey"
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Exec("DELETE FROM idempotency_keys WHERE scope=$1 AND key=$2 AND expires_at <= $3",
+		key.Scope, key.Key, key.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = tx.Exec(`
+	INSERT INTO idempotency_keys(scope, key, request_hash, created_at, expires_at) VALUES($1, $2, $3, $4, $5)`,
+		key.Scope, key.Key, key.RequestHash, key.CreatedAt.UTC(), key.ExpiresAt.UTC())
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrIdempotencyKeyExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetIdempotencyKey returns storage.ErrIdempotencyKeyNotFound for unknown keys.
+func (s *Storage) GetIdempotencyKey(scope, key string) (domain.IdempotencyKey, error) {
+	const op = "storage.postgres.GetIdempotencyKey"
+
+	k := domain.IdempotencyKey{Scope: scope, Key: key}
+	err := s.db.QueryRow(`
+	SELECT request_hash, status, content_type, body, created_at, expires_at FROM idempotency_keys
+	WHERE scope=$1 AND key=$2`, scope, key).
+		Scan(&k.RequestHash, &k.Status, &k.ContentType, &k.Body, &k.CreatedAt, &k.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.IdempotencyKey{}, fmt.Errorf("%s: %w", op, storage.ErrIdempotencyKeyNotFound)
+		}
+		return domain.IdempotencyKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+	k.CreatedAt, k.ExpiresAt = k.CreatedAt.UTC(), k.ExpiresAt.UTC()
+	return k, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request, it is replayed to the retries from then on.
+// Returns storage.ErrIdempotencyKeyNotFound for unknown keys.
+func (s *Storage) CompleteIdempotencyKey(scope, key string, status int, contentType string, body []byte) error {
+	const op = "storage.postgres.CompleteIdempotencyKey"
+
+	res, err := s.db.Exec("UPDATE idempotency_keys SET status=$1, content_type=$2, body=$3 WHERE scope=$4 AND key=$5",
+		status, contentType, body, scope, key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrIdempotencyKeyNotFound)
+	}
+	return nil
+}
+
+// DeleteIdempotencyKey releases the key, so the request may be retried with it.
+func (s *Storage) DeleteIdempotencyKey(scope, key string) error {
+	const op = "storage.postgres.DeleteIdempotencyKey"
+
+	if _, err := s.db.Exec("DELETE FROM idempotency_keys WHERE scope=$1 AND key=$2", scope, key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes up to limit keys expired before now and returns the number of deleted ones.
+func (s *Storage) PurgeIdempotencyKeys(now time.Time, limit int) (int, error) {
+	const op = "storage.postgres.PurgeIdempotencyKeys"
+
+	res, err := s.db.Exec(`
+	DELETE FROM idempotency_keys WHERE (scope, key) IN (
+		SELECT scope, key FROM idempotency_keys WHERE expires_at <= $1 ORDER BY expires_at LIMIT $2)`, now.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	n, _ := res.RowsAffected()
+
+	return int(n), nil
+}
+
+// ClaimEvents leases up to limit events to workerID until now+lease.
+// Events with an expired lease are claimable again, so a crashed worker doesn't lose them.
+func (s *Storage) ClaimEvents(workerID string, limit int, lease time.Duration) ([]domain.Event, error) {
+	const op = "storage.postgres.ClaimEvents"
+
+	now := time.Now().UTC()
+	//SKIP LOCKED lets concurrent workers claim disjoint batches without waiting on each other
+	rows, err := s.db.Query(`
+	UPDATE events SET status='in_progress', reserved_to=$1, worker_id=$2
+	WHERE id IN (
+		SELECT id FROM events
+		WHERE (status='new' AND (next_attempt_at IS NULL OR next_attempt_at <= $3))
+			OR (status='in_progress' AND reserved_to < $3)
+		ORDER BY id
+		LIMIT $4
+		FOR UPDATE SKIP LOCKED)
+	RETURNING id, event_type, payload, attempts, created_at`, now.Add(lease), workerID, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		var e event
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Payload, &e.Attempts, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		events = append(events, domain.Event{
+			ID:        e.ID,
+			EventType: e.EventType,
+			Payload:   e.Payload,
+			CreatedAt: e.CreatedAt,
+			Attempts:  e.Attempts,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrEventNotFound)
+	}
+	//RETURNING doesn't guarantee the order
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+
+	return events, nil
+}
+
+// MarkEventsAsDone marks the whole batch as done in one statement.
+func (s *Storage) MarkEventsAsDone(eventIDs []int) error {
+	const op = "storage.postgres.MarkEventsAsDone"
+
+	_, err := s.db.Exec("UPDATE events SET status='done', reserved_to=NULL WHERE id = ANY($1)", pq.Array(eventIDs))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkEventFailed returns the event to the pool, it can't be claimed before nextAttemptAt.
+func (s *Storage) MarkEventFailed(eventID int, lastError string, nextAttemptAt time.Time) error {
+	const op = "storage.postgres.MarkEventFailed"
+
+	_, err := s.db.Exec(`
+	UPDATE events SET status='new', reserved_to=NULL, attempts=attempts+1, last_error=$1, next_attempt_at=$2
+	WHERE id=$3`, lastError, nextAttemptAt.UTC(), eventID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkEventDead stops delivery of the event until it is requeued.
+func (s *Storage) MarkEventDead(eventID int, lastError string) error {
+	const op = "storage.postgres.MarkEventDead"
+
+	_, err := s.db.Exec(`
+	UPDATE events SET status='dead', reserved_to=NULL, attempts=attempts+1, last_error=$1, next_attempt_at=NULL
+	WHERE id=$2`, lastError, eventID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ListDeadEvents(limit int) ([]domain.DeadEvent, error) {
+	const op = "storage.postgres.ListDeadEvents"
+
+	rows, err := s.db.Query(`
+	SELECT id, event_type, payload, attempts, last_error, created_at
+	FROM events WHERE status='dead'
+	ORDER BY id
+	LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	events := []domain.DeadEvent{}
+	for rows.Next() {
+		var e event
+		var lastError sql.NullString
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Payload, &e.Attempts, &lastError, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		events = append(events, domain.DeadEvent{
+			Event: domain.Event{
+				ID:        e.ID,
+				EventType: e.EventType,
+				Payload:   e.Payload,
+				CreatedAt: e.CreatedAt,
+			},
+			Attempts:  e.Attempts,
+			LastError: lastError.String,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+// ListEvents returns up to limit oldest events of the domain.EventStatuses status.
+func (s *Storage) ListEvents(status string, limit int) ([]domain.OutboxEvent, error) {
+	const op = "storage.postgres.ListEvents"
+
+	statuses, ok := storage.EventRowStatuses[status]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown event status %q", op, status)
+	}
+	rows, err := s.db.Query(`
+	SELECT id, event_type, payload, attempts, last_error, next_attempt_at, created_at
+	FROM events WHERE status IN (`+statuses+`)
+	ORDER BY id
+	LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	events := []domain.OutboxEvent{}
+	for rows.Next() {
+		var e event
+		var lastError sql.NullString
+		var nextAttemptAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Payload, &e.Attempts, &lastError, &nextAttemptAt, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ev := domain.OutboxEvent{
+			Event: domain.Event{
+				ID:        e.ID,
+				EventType: e.EventType,
+				Payload:   e.Payload,
+				CreatedAt: e.CreatedAt,
+			},
+			Status:    status,
+			Attempts:  e.Attempts,
+			LastError: lastError.String,
+		}
+		if nextAttemptAt.Valid {
+			t := nextAttemptAt.Time.UTC()
+			ev.NextAttemptAt = &t
+		}
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+// RequeueEvent returns a dead event to the pool with a fresh attempts budget.
+func (s *Storage) RequeueEvent(eventID int) error {
+	const op = "storage.postgres.RequeueEvent"
+
+	res, err := s.db.Exec(`
+	UPDATE events SET status='new', attempts=0, next_attempt_at=NULL
+	WHERE id=$1 AND status='dead'`, eventID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrDeadEventNotFound)
+	}
+
+	return nil
+}
+
+// PurgeEvents deletes up to limit delivered events created before the time, with dead the dead ones too,
+// and returns the number of deleted ones.
+func (s *Storage) PurgeEvents(before time.Time, dead bool, limit int) (int, error) {
+	const op = "storage.postgres.PurgeEvents"
+
+	statuses := []string{"done"}
+	if dead {
+		statuses = append(statuses, "dead")
+	}
+	res, err := s.db.Exec(`
+	DELETE FROM events WHERE id IN (
+		SELECT id FROM events WHERE status = ANY($1) AND created_at < $2 ORDER BY id LIMIT $3)`,
+		pq.Array(statuses), before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	n, _ := res.RowsAffected()
+
+	return int(n), nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// nullTime stores nil as NULL and everything else in UTC, so timestamps compare correctly
+func nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// ws is the workspace of the test links
+const ws = domain.DefaultWorkspaceID
+
+// ref is the link alias of the workspace on the default domain
+func ref(workspaceID int64, alias string) domain.LinkRef {
+	return domain.LinkRef{WorkspaceID: workspaceID, Alias: alias}
+}
+
+// newTestStorage migrates a schema of its own in the STORAGE_DSN database and drops it after the test,
+// the tests are skipped without the variable
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	dsn := os.Getenv("STORAGE_DSN")
+	if dsn == "" {
+		t.Skip("STORAGE_DSN isn't set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	_, err = db.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := db.Exec("DROP SCHEMA " + schema + " CASCADE")
+		require.NoError(t, err)
+	})
+
+	s, err := New(withSearchPath(dsn, schema))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+
+	m, err := s.Migrator()
+	require.NoError(t, err)
+	_, err = m.Up()
+	require.NoError(t, err)
+
+	return s
+}
+
+// withSearchPath adds the search_path runtime parameter to a URL or a key=value dsn
+func withSearchPath(dsn, schema string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&search_path=" + schema
+	}
+	return dsn + "?search_path=" + schema
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Unique violation", err: &pq.Error{Code: "23505"}, want: true},
+		{name: "Wrapped", err: fmt.Errorf("storage.postgres.SaveURL: %w", &pq.Error{Code: "23505"}), want: true},
+		{name: "Foreign key violation", err: &pq.Error{Code: "23503"}},
+		{name: "Other error", err: errors.New("duplicate key value violates unique constraint")},
+		{name: "No error"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, isUniqueViolation(tc.err))
+		})
+	}
+}
+
+func TestWithSearchPath(t *testing.T) {
+	require.Equal(t, "host=db dbname=app search_path=test_1", withSearchPath("host=db dbname=app", "test_1"))
+	require.Equal(t, "postgres://db/app?search_path=test_1", withSearchPath("postgres://db/app", "test_1"))
+	require.Equal(t, "postgres://db/app?sslmode=disable&search_path=test_1", withSearchPath("postgres://db/app?sslmode=disable", "test_1"))
+}
+
+func TestStorage_SaveGetURL(t *testing.T) {
+	s := newTestStorage(t)
+
+	_, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "ex"})
+	require.NoError(t, err)
+
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.org", Alias: "ex"})
+	require.ErrorIs(t, err, storage.ErrURLExists)
+
+	link, err := s.GetURL(ref(ws, "ex"))
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com", link.URL)
+
+	_, err = s.GetURL(ref(ws, "missing"))
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+}
+
+func TestStorage_Workspaces(t *testing.T) {
+	s := newTestStorage(t)
+
+	other, err := s.CreateWorkspace(domain.Workspace{Name: "Team B", Slug: "teamb", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	_, err = s.CreateWorkspace(domain.Workspace{Name: "Team B again", Slug: "teamb", CreatedAt: time.Now()})
+	require.ErrorIs(t, err, storage.ErrWorkspaceExists)
+
+	//aliases are namespaced by workspace
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://a.example", Alias: "ex"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, URL: "https://b.example", Alias: "ex"})
+	require.NoError(t, err)
+
+	_, err = s.GetWorkspace(100)
+	require.ErrorIs(t, err, storage.ErrWorkspaceNotFound)
+}
+
+func TestStorage_Domains(t *testing.T) {
+	s := newTestStorage(t)
+
+	other, err := s.CreateWorkspace(domain.Workspace{Name: "Team B", Slug: "teamb", CreatedAt: time.Now()})
+	require.NoError(t, err)
+
+	//unverified claims don't block other workspaces
+	otherID, err := s.CreateDomain(domain.Domain{WorkspaceID: other, Hostname: "go.example", VerificationToken: "token-b", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	id, err := s.CreateDomain(domain.Domain{WorkspaceID: ws, Hostname: "go.example", VerificationToken: "token", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	_, err = s.CreateDomain(domain.Domain{WorkspaceID: ws, Hostname: "go.example", VerificationToken: "token", CreatedAt: time.Now()})
+	require.ErrorIs(t, err, storage.ErrDomainExists)
+
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, Domain: "go.example", URL: "https://a.example", Alias: "ex"})
+	require.ErrorIs(t, err, storage.ErrDomainNotFound)
+
+	//the first verification wins, the other claims are dropped
+	require.NoError(t, s.VerifyDomain(id, time.Now()))
+	require.ErrorIs(t, s.VerifyDomain(otherID, time.Now()), storage.ErrDomainNotFound)
+	_, err = s.CreateDomain(domain.Domain{WorkspaceID: other, Hostname: "go.example", VerificationToken: "token-b", CreatedAt: time.Now()})
+	require.ErrorIs(t, err, storage.ErrDomainExists)
+
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, Domain: "go.example", URL: "https://a.example", Alias: "ex"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, Domain: "go.example", URL: "https://b.example", Alias: "ex"})
+	require.ErrorIs(t, err, storage.ErrURLExists)
+}
+
+func TestStorage_Quotas(t *testing.T) {
+	s := newTestStorage(t)
+
+	quota := int64(1)
+	other, err := s.CreateWorkspace(domain.Workspace{Name: "Team B", Slug: "teamb", MonthlyLinkQuota: &quota, CreatedAt: time.Now()})
+	require.NoError(t, err)
+
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, URL: "https://a.example", Alias: "a"})
+	require.NoError(t, err)
+	//failed saves aren't counted
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, URL: "https://a.example", Alias: "a"})
+	require.ErrorIs(t, err, storage.ErrURLExists)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, URL: "https://b.example", Alias: "b"})
+	require.ErrorIs(t, err, storage.ErrQuotaExceeded)
+
+	usage, err := s.GetWorkspaceUsage(other, domain.UsagePeriod(time.Now()))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), usage.LinksCreated)
+}
+
+func TestStorage_IdempotencyKeys(t *testing.T) {
+	s := newTestStorage(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	key := domain.IdempotencyKey{Scope: "key:1", Key: "k1", RequestHash: "h1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, s.CreateIdempotencyKey(key))
+	require.ErrorIs(t, s.CreateIdempotencyKey(key), storage.ErrIdempotencyKeyExists)
+	require.ErrorIs(t, s.CompleteIdempotencyKey("key:1", "unknown", 201, "", nil), storage.ErrIdempotencyKeyNotFound)
+}
+
+func TestStorage_Events(t *testing.T) {
+	s := newTestStorage(t)
+
+	_, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "first"})
+	require.NoError(t, err)
+
+	events, err := s.ClaimEvents("worker-a", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	_, err = s.ClaimEvents("worker-b", 10, time.Minute)
+	require.ErrorIs(t, err, storage.ErrEventNotFound)
+
+	require.NoError(t, s.MarkEventDead(events[0].ID, "bad gateway"))
+	dead, err := s.ListEvents(domain.EventStatusDead, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, "bad gateway", dead[0].LastError)
+
+	require.NoError(t, s.RequeueEvent(events[0].ID))
+	require.ErrorIs(t, s.RequeueEvent(events[0].ID), storage.ErrDeadEventNotFound)
+}
@@ -0,0 +1,896 @@
+package sqlite_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"short-url/internal/http-server/model/domain"
+	"short-url/internal/storage"
+	"short-url/internal/storage/sqlite"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ws is the workspace of the test links
+const ws = domain.DefaultWorkspaceID
+
+// ref is the link alias of the workspace on the default domain
+func ref(workspaceID int64, alias string) domain.LinkRef {
+	return domain.LinkRef{WorkspaceID: workspaceID, Alias: alias}
+}
+
+func newTestStorage(t *testing.T) *sqlite.Storage {
+	t.Helper()
+
+	s, err := sqlite.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	m, err := s.Migrator()
+	require.NoError(t, err)
+	_, err = m.Up()
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestStorage_SaveGetURL(t *testing.T) {
+	s := newTestStorage(t)
+
+	_, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "ex"})
+	require.NoError(t, err)
+
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.org", Alias: "ex"})
+	require.ErrorIs(t, err, storage.ErrURLExists)
+
+	link, err := s.GetURL(ref(ws, "ex"))
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com", link.URL)
+
+	_, err = s.GetURL(ref(ws, "missing"))
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+}
+
+func TestStorage_ClaimEvents(t *testing.T) {
+	s := newTestStorage(t)
+
+	_, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "first"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.org", Alias: "second"})
+	require.NoError(t, err)
+
+	a, err := s.ClaimEvents("worker-a", 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, a, 1)
+
+	//the leased event is invisible to other workers
+	b, err := s.ClaimEvents("worker-b", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, b, 1)
+	require.NotEqual(t, a[0].ID, b[0].ID)
+
+	_, err = s.ClaimEvents("worker-c", 10, time.Minute)
+	require.ErrorIs(t, err, storage.ErrEventNotFound)
+
+	require.NoError(t, s.MarkEventsAsDone([]int{a[0].ID, b[0].ID}))
+	_, err = s.ClaimEvents("worker-c", 10, time.Minute)
+	require.ErrorIs(t, err, storage.ErrEventNotFound)
+}
+
+func TestStorage_ClaimEvents_LeaseExpired(t *testing.T) {
+	s := newTestStorage(t)
+
+	_, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "first"})
+	require.NoError(t, err)
+
+	//negative lease is already expired
+	a, err := s.ClaimEvents("worker-a", 1, -time.Second)
+	require.NoError(t, err)
+	require.Len(t, a, 1)
+
+	b, err := s.ClaimEvents("worker-b", 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, b, 1)
+	require.Equal(t, a[0].ID, b[0].ID)
+}
+
+func TestStorage_EventRetries(t *testing.T) {
+	s := newTestStorage(t)
+
+	_, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "first"})
+	require.NoError(t, err)
+
+	events, err := s.ClaimEvents("worker-a", 1, time.Minute)
+	require.NoError(t, err)
+	id := events[0].ID
+
+	//not claimable before the next attempt
+	require.NoError(t, s.MarkEventFailed(id, "timeout", time.Now().Add(time.Hour)))
+	_, err = s.ClaimEvents("worker-a", 1, time.Minute)
+	require.ErrorIs(t, err, storage.ErrEventNotFound)
+
+	pending, err := s.ListEvents(domain.EventStatusPending, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, "timeout", pending[0].LastError)
+	require.NotNil(t, pending[0].NextAttemptAt)
+	_, err = s.ListEvents("new", 10)
+	require.Error(t, err)
+
+	require.NoError(t, s.MarkEventFailed(id, "timeout", time.Now().Add(-time.Second)))
+	events, err = s.ClaimEvents("worker-a", 1, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 2, events[0].Attempts)
+
+	require.NoError(t, s.MarkEventDead(id, "bad gateway"))
+	dead, err := s.ListDeadEvents(10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, 3, dead[0].Attempts)
+	require.Equal(t, "bad gateway", dead[0].LastError)
+	listed, err := s.ListEvents(domain.EventStatusDead, 10)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, domain.EventStatusDead, listed[0].Status)
+	require.Nil(t, listed[0].NextAttemptAt)
+
+	require.NoError(t, s.RequeueEvent(id))
+	require.ErrorIs(t, s.RequeueEvent(id), storage.ErrDeadEventNotFound)
+
+	events, err = s.ClaimEvents("worker-a", 1, time.Minute)
+	require.NoError(t, err)
+	require.Zero(t, events[0].Attempts)
+
+	//only delivered events are purged, dead ones on request
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "second"})
+	require.NoError(t, err)
+	require.NoError(t, s.MarkEventsAsDone([]int{id}))
+	events, err = s.ClaimEvents("worker-a", 1, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, s.MarkEventDead(events[0].ID, "bad gateway"))
+
+	n, err := s.PurgeEvents(time.Now().Add(-time.Hour), true, 10)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	n, err = s.PurgeEvents(time.Now().Add(time.Hour), false, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	n, err = s.PurgeEvents(time.Now().Add(time.Hour), true, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
+
+func TestStorage_SaveURL_EventPayload(t *testing.T) {
+	s := newTestStorage(t)
+
+	const url = `https://example.com/?q="quoted"\path`
+	id, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: url, Alias: "quoted"})
+	require.NoError(t, err)
+
+	events, err := s.ClaimEvents("worker-a", 1, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, domain.EventURLSaved, events[0].EventType)
+	require.False(t, events[0].CreatedAt.IsZero())
+
+	var payload domain.URLSavedPayload
+	require.NoError(t, json.Unmarshal([]byte(events[0].Payload), &payload))
+	require.Equal(t, domain.URLSavedPayload{
+		SchemaVersion: domain.PayloadSchemaVersion,
+		ID:            id,
+		WorkspaceID:   ws,
+		URL:           url,
+		Alias:         "quoted",
+	}, payload)
+}
+
+func TestStorage_MutationEvents(t *testing.T) {
+	s := newTestStorage(t)
+
+	linkID, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "ex"})
+	require.NoError(t, err)
+
+	newURL := "https://example.org"
+	_, err = s.UpdateLink(ref(ws, "ex"), domain.LinkUpdate{URL: &newURL})
+	require.NoError(t, err)
+	_, err = s.UpdateLink(ref(ws, "missing"), domain.LinkUpdate{URL: &newURL})
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+
+	link, err := s.GetURL(ref(ws, "ex"))
+	require.NoError(t, err)
+	require.Equal(t, "https://example.org", link.URL)
+
+	require.NoError(t, s.SaveClicks([]domain.Click{
+		{LinkID: linkID, Alias: "ex", ClickedAt: time.Now(), IPHash: "a"},
+		{LinkID: linkID, WorkspaceID: ws, Alias: "ex", ClickedAt: time.Now(), IPHash: "a", Sampled: true},
+	}))
+
+	require.NoError(t, s.DeleteURL(ref(ws, "ex")))
+	require.ErrorIs(t, s.DeleteURL(ref(ws, "ex")), storage.ErrURLNotFound)
+
+	events, err := s.ClaimEvents("worker-a", 10, time.Minute)
+	require.NoError(t, err)
+
+	var types []string
+	for _, ev := range events {
+		types = append(types, ev.EventType)
+	}
+	require.Equal(t, []string{
+		domain.EventURLSaved,
+		domain.EventURLUpdated,
+		domain.EventURLClicked,
+		domain.EventURLDeleted,
+	}, types)
+
+	var clicked domain.URLClickedPayload
+	require.NoError(t, json.Unmarshal([]byte(events[2].Payload), &clicked))
+	require.Equal(t, ws, clicked.WorkspaceID)
+	require.Equal(t, "ex", clicked.Alias)
+
+	var deleted domain.URLDeletedPayload
+	require.NoError(t, json.Unmarshal([]byte(events[3].Payload), &deleted))
+	require.Equal(t, "https://example.org", deleted.URL)
+}
+
+func TestStorage_ExpiredURLs(t *testing.T) {
+	s := newTestStorage(t)
+
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	_, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "expired", ExpiresAt: &past})
+	require.NoError(t, err)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "alive", ExpiresAt: &future})
+	require.NoError(t, err)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "forever"})
+	require.NoError(t, err)
+
+	_, err = s.GetURL(ref(ws, "expired"))
+	require.ErrorIs(t, err, storage.ErrURLExpired)
+	_, err = s.GetURL(ref(ws, "alive"))
+	require.NoError(t, err)
+
+	n, err := s.PurgeExpiredURLs(time.Now(), 10, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	_, err = s.GetURL(ref(ws, "expired"))
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+
+	n, err = s.PurgeExpiredURLs(time.Now(), 10, true)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	events, err := s.ClaimEvents("worker-a", 10, time.Minute)
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	require.Equal(t, domain.EventURLExpired, last.EventType)
+
+	var payload domain.URLExpiredPayload
+	require.NoError(t, json.Unmarshal([]byte(last.Payload), &payload))
+	require.Equal(t, "expired", payload.Alias)
+	require.True(t, payload.Archived)
+}
+
+func TestStorage_ClickStats(t *testing.T) {
+	s := newTestStorage(t)
+
+	linkID, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "ex"})
+	require.NoError(t, err)
+
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, s.SaveClicks([]domain.Click{
+		{LinkID: linkID, Alias: "ex", ClickedAt: day.Add(time.Hour), IPHash: "a"},
+		{LinkID: linkID, Alias: "ex", ClickedAt: day.Add(2 * time.Hour), IPHash: "a"},
+		{LinkID: linkID, Alias: "ex", ClickedAt: day.Add(25 * time.Hour), IPHash: "b"},
+		{LinkID: linkID, Alias: "ex", ClickedAt: day.Add(-time.Hour), IPHash: "c"},
+	}))
+
+	stats, err := s.GetClickStats(linkID, day, day.Add(48*time.Hour), domain.BucketDay)
+	require.NoError(t, err)
+	require.Equal(t, 3, stats.Total)
+	require.Equal(t, 2, *stats.UniqueVisitors)
+	require.Len(t, stats.Buckets, 2)
+	require.True(t, day.Equal(stats.Buckets[0].Start))
+	require.Equal(t, 2, stats.Buckets[0].Clicks)
+	require.Equal(t, 1, stats.Buckets[0].UniqueVisitors)
+
+	stats, err = s.GetClickStats(linkID, day, day.Add(24*time.Hour), domain.BucketHour)
+	require.NoError(t, err)
+	require.Len(t, stats.Buckets, 2)
+	require.True(t, day.Add(time.Hour).Equal(stats.Buckets[0].Start))
+
+	_, err = s.GetClickStats(linkID+1, day, day.Add(24*time.Hour), domain.BucketDay)
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+}
+
+func TestStorage_RollupClicks(t *testing.T) {
+	s := newTestStorage(t)
+
+	linkID, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "ex"})
+	require.NoError(t, err)
+
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, s.SaveClicks([]domain.Click{
+		{LinkID: linkID, Alias: "ex", ClickedAt: day.Add(time.Hour), IPHash: "a", ReferrerHost: "news.example.com", Browser: "firefox", Country: "DE"},
+		{LinkID: linkID, Alias: "ex", ClickedAt: day.Add(90 * time.Minute), IPHash: "a", ReferrerHost: "news.example.com", Browser: "chrome", Country: "DE"},
+		{LinkID: linkID, Alias: "ex", ClickedAt: day.Add(25 * time.Hour), IPHash: "a", Browser: "chrome", Country: "US"},
+		{LinkID: linkID, Alias: "ex", ClickedAt: day.Add(49 * time.Hour), IPHash: "c"},
+	}))
+
+	rolledUpTo, err := s.RollupClicks(day.Add(48*time.Hour + 30*time.Minute))
+	require.NoError(t, err)
+	require.True(t, day.Add(48*time.Hour).Equal(rolledUpTo))
+
+	//the visitor of both days is counted once for the range while its raw clicks are kept
+	stats, err := s.GetClickStats(linkID, day, day.Add(48*time.Hour), domain.BucketDay)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatsSourceRollup, stats.Source)
+	require.Equal(t, 1, *stats.UniqueVisitors)
+
+	//raw clicks of the rolled up days are no longer needed
+	n, err := s.PurgeClicks(day.Add(48*time.Hour), 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	stats, err = s.GetClickStats(linkID, day, day.Add(48*time.Hour), domain.BucketDay)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatsSourceRollup, stats.Source)
+	require.Equal(t, 3, stats.Total)
+	require.Nil(t, stats.UniqueVisitors)
+	require.Len(t, stats.Buckets, 2)
+	require.True(t, day.Equal(stats.Buckets[0].Start))
+	require.Equal(t, 2, stats.Buckets[0].Clicks)
+	require.Equal(t, 1, stats.Buckets[0].UniqueVisitors)
+	require.Equal(t, []domain.ClickCount{{Value: "chrome", Clicks: 2}, {Value: "firefox", Clicks: 1}}, stats.Browsers)
+	require.Equal(t, []domain.ClickCount{{Value: "news.example.com", Clicks: 2}, {Value: "", Clicks: 1}}, stats.Referrers)
+	require.Equal(t, []domain.ClickCount{{Value: "DE", Clicks: 2}, {Value: "US", Clicks: 1}}, stats.Countries)
+
+	stats, err = s.GetClickStats(linkID, day, day.Add(24*time.Hour), domain.BucketHour)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatsSourceRollup, stats.Source)
+	require.Len(t, stats.Buckets, 1)
+	require.True(t, day.Add(time.Hour).Equal(stats.Buckets[0].Start))
+
+	//the range isn't rolled up yet, so it is read from the raw clicks
+	stats, err = s.GetClickStats(linkID, day.Add(48*time.Hour), day.Add(72*time.Hour), domain.BucketDay)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatsSourceRaw, stats.Source)
+	require.Equal(t, 1, stats.Total)
+
+	//the next run continues from the previous one
+	rolledUpTo, err = s.RollupClicks(day.Add(72 * time.Hour))
+	require.NoError(t, err)
+	require.True(t, day.Add(72*time.Hour).Equal(rolledUpTo))
+
+	stats, err = s.GetClickStats(linkID, day, day.Add(72*time.Hour), domain.BucketDay)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatsSourceRollup, stats.Source)
+	require.Equal(t, 4, stats.Total)
+	require.Len(t, stats.Buckets, 3)
+
+	//totals add the rollups to the raw clicks after them
+	require.NoError(t, s.SaveClicks([]domain.Click{{LinkID: linkID, Alias: "ex", ClickedAt: day.Add(73 * time.Hour)}}))
+	totals, err := s.CountClicks([]int64{linkID, linkID + 1})
+	require.NoError(t, err)
+	require.Equal(t, map[int64]int64{linkID: 5}, totals)
+}
+
+func TestStorage_GetUpdateLink(t *testing.T) {
+	s := newTestStorage(t)
+
+	_, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "ex"})
+	require.NoError(t, err)
+
+	link, err := s.GetLink(ref(ws, "ex"))
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com", link.URL)
+	require.False(t, link.CreatedAt.IsZero())
+	require.Nil(t, link.ExpiresAt)
+
+	expiresAt := time.Now().Add(time.Hour).UTC()
+	disabled := true
+	link, err = s.UpdateLink(ref(ws, "ex"), domain.LinkUpdate{ExpiresAt: &expiresAt, Disabled: &disabled})
+	require.NoError(t, err)
+	require.True(t, link.Disabled)
+	require.Equal(t, "https://example.com", link.URL)
+
+	//disabled links are not redirected but still readable
+	_, err = s.GetURL(ref(ws, "ex"))
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+	link, err = s.GetLink(ref(ws, "ex"))
+	require.NoError(t, err)
+	require.True(t, link.Disabled)
+	require.NotNil(t, link.ExpiresAt)
+	require.True(t, expiresAt.Equal(*link.ExpiresAt))
+
+	enabled := false
+	_, err = s.UpdateLink(ref(ws, "ex"), domain.LinkUpdate{NeverExpires: true, Disabled: &enabled})
+	require.NoError(t, err)
+	link, err = s.GetLink(ref(ws, "ex"))
+	require.NoError(t, err)
+	require.Nil(t, link.ExpiresAt)
+	_, err = s.GetURL(ref(ws, "ex"))
+	require.NoError(t, err)
+
+	_, err = s.GetLink(ref(ws, "missing"))
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+}
+
+func TestStorage_ListLinks(t *testing.T) {
+	s := newTestStorage(t)
+
+	for _, alias := range []string{"go-1", "go-2", "Go-3", "rust-1", "go-4"} {
+		_, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com/" + alias, Alias: alias})
+		require.NoError(t, err)
+	}
+
+	aliases := func(links []domain.Link) []string {
+		res := []string{}
+		for _, l := range links {
+			res = append(res, l.Alias)
+		}
+		return res
+	}
+
+	links, err := s.ListLinks(domain.LinkQuery{WorkspaceID: ws, Prefix: "go-", SortBy: domain.LinkSortAlias, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"go-1", "go-2", "go-4"}, aliases(links))
+
+	//page through the links newest first
+	var all []string
+	query := domain.LinkQuery{WorkspaceID: ws, SortBy: domain.LinkSortCreatedAt, Desc: true, Limit: 2}
+	for {
+		links, err := s.ListLinks(query)
+		require.NoError(t, err)
+		all = append(all, aliases(links)...)
+		if len(links) < query.Limit {
+			break
+		}
+		last := links[len(links)-1]
+		query.After = &domain.LinkCursor{ID: last.ID, Alias: last.Alias, CreatedAt: last.CreatedAt}
+	}
+	require.Equal(t, []string{"go-4", "rust-1", "Go-3", "go-2", "go-1"}, all)
+
+	//the same alias on a custom domain isn't skipped by the alias cursor
+	domainID, err := s.CreateDomain(domain.Domain{WorkspaceID: ws, Hostname: "go.example", VerificationToken: "token", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, s.VerifyDomain(domainID, time.Now()))
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, Domain: "go.example", URL: "https://example.com/go-1", Alias: "go-1"})
+	require.NoError(t, err)
+
+	all = nil
+	query = domain.LinkQuery{WorkspaceID: ws, Prefix: "go-", SortBy: domain.LinkSortAlias, Limit: 1}
+	for {
+		links, err := s.ListLinks(query)
+		require.NoError(t, err)
+		all = append(all, aliases(links)...)
+		if len(links) < query.Limit {
+			break
+		}
+		last := links[len(links)-1]
+		query.After = &domain.LinkCursor{ID: last.ID, Alias: last.Alias, CreatedAt: last.CreatedAt}
+	}
+	require.Equal(t, []string{"go-1", "go-1", "go-2", "go-4"}, all)
+
+	future := time.Now().Add(time.Hour)
+	links, err = s.ListLinks(domain.LinkQuery{WorkspaceID: ws, CreatedFrom: &future, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, links)
+}
+
+func TestStorage_APIKeys(t *testing.T) {
+	s := newTestStorage(t)
+
+	id, err := s.CreateAPIKey(domain.APIKey{
+		WorkspaceID: ws,
+		Name:        "ci",
+		Prefix:      "sk_abcdef",
+		Scopes:      []string{domain.ScopeLinksWrite, domain.ScopeLinksRead},
+		CreatedAt:   time.Now(),
+	}, "hash1")
+	require.NoError(t, err)
+
+	key, err := s.GetAPIKeyByHash("hash1")
+	require.NoError(t, err)
+	require.Equal(t, id, key.ID)
+	require.Equal(t, []string{domain.ScopeLinksWrite, domain.ScopeLinksRead}, key.Scopes)
+
+	//links remember the key that created them
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://example.com", Alias: "ex", APIKeyID: &id})
+	require.NoError(t, err)
+	link, err := s.GetLink(ref(ws, "ex"))
+	require.NoError(t, err)
+	require.NotNil(t, link.APIKeyID)
+	require.Equal(t, id, *link.APIKeyID)
+
+	//keys of other workspaces aren't found
+	_, err = s.RotateAPIKey(ws+1, id, "sk_ghijkl", "hash2")
+	require.ErrorIs(t, err, storage.ErrAPIKeyNotFound)
+	require.ErrorIs(t, s.RevokeAPIKey(ws+1, id), storage.ErrAPIKeyNotFound)
+
+	//the old secret stops working after a rotation
+	key, err = s.RotateAPIKey(ws, id, "sk_ghijkl", "hash2")
+	require.NoError(t, err)
+	require.Equal(t, "sk_ghijkl", key.Prefix)
+	require.Equal(t, "ci", key.Name)
+	_, err = s.GetAPIKeyByHash("hash1")
+	require.ErrorIs(t, err, storage.ErrAPIKeyNotFound)
+	_, err = s.GetAPIKeyByHash("hash2")
+	require.NoError(t, err)
+
+	require.NoError(t, s.RevokeAPIKey(storage.AnyWorkspace, id))
+	_, err = s.GetAPIKeyByHash("hash2")
+	require.ErrorIs(t, err, storage.ErrAPIKeyNotFound)
+	require.ErrorIs(t, s.RevokeAPIKey(ws, id), storage.ErrAPIKeyNotFound)
+	_, err = s.RotateAPIKey(ws, id, "sk_mnopqr", "hash3")
+	require.ErrorIs(t, err, storage.ErrAPIKeyNotFound)
+}
+
+func TestStorage_Workspaces(t *testing.T) {
+	s := newTestStorage(t)
+
+	other, err := s.CreateWorkspace(domain.Workspace{Name: "Team B", Slug: "teamb", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	_, err = s.CreateWorkspace(domain.Workspace{Name: "Team B again", Slug: "teamb", CreatedAt: time.Now()})
+	require.ErrorIs(t, err, storage.ErrWorkspaceExists)
+
+	ws2, err := s.GetWorkspaceBySlug("teamb")
+	require.NoError(t, err)
+	require.Equal(t, other, ws2.ID)
+	ws2, err = s.GetWorkspace(other)
+	require.NoError(t, err)
+	require.Equal(t, "teamb", ws2.Slug)
+	_, err = s.GetWorkspace(100)
+	require.ErrorIs(t, err, storage.ErrWorkspaceNotFound)
+	def, err := s.GetWorkspaceBySlug("default")
+	require.NoError(t, err)
+	require.Equal(t, domain.DefaultWorkspaceID, def.ID)
+
+	//aliases are namespaced by workspace
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://a.example", Alias: "ex"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, URL: "https://b.example", Alias: "ex"})
+	require.NoError(t, err)
+
+	link, err := s.GetURL(ref(other, "ex"))
+	require.NoError(t, err)
+	require.Equal(t, "https://b.example", link.URL)
+
+	links, err := s.ListLinks(domain.LinkQuery{WorkspaceID: other, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	require.Equal(t, other, links[0].WorkspaceID)
+
+	//a workspace can't touch the links of another one
+	require.NoError(t, s.DeleteURL(ref(other, "ex")))
+	require.ErrorIs(t, s.DeleteURL(ref(other, "ex")), storage.ErrURLNotFound)
+	_, err = s.GetLink(ref(ws, "ex"))
+	require.NoError(t, err)
+
+	//api keys are the members of a workspace
+	_, err = s.CreateAPIKey(domain.APIKey{WorkspaceID: other, Name: "b", Prefix: "sk_b", Scopes: []string{domain.ScopeLinksRead}, CreatedAt: time.Now()}, "hash-b")
+	require.NoError(t, err)
+	_, err = s.CreateAPIKey(domain.APIKey{WorkspaceID: 42, Name: "c", Prefix: "sk_c", Scopes: []string{domain.ScopeLinksRead}, CreatedAt: time.Now()}, "hash-c")
+	require.ErrorIs(t, err, storage.ErrWorkspaceNotFound)
+
+	members, err := s.ListAPIKeys(other)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.Equal(t, "b", members[0].Name)
+	require.Equal(t, other, members[0].WorkspaceID)
+}
+
+func TestStorage_Domains(t *testing.T) {
+	s := newTestStorage(t)
+
+	other, err := s.CreateWorkspace(domain.Workspace{Name: "Team B", Slug: "teamb", CreatedAt: time.Now()})
+	require.NoError(t, err)
+
+	//unverified claims don't block other workspaces
+	otherID, err := s.CreateDomain(domain.Domain{WorkspaceID: other, Hostname: "go.example", VerificationToken: "token-b", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	id, err := s.CreateDomain(domain.Domain{WorkspaceID: ws, Hostname: "go.example", VerificationToken: "token", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	_, err = s.CreateDomain(domain.Domain{WorkspaceID: ws, Hostname: "go.example", VerificationToken: "token", CreatedAt: time.Now()})
+	require.ErrorIs(t, err, storage.ErrDomainExists)
+
+	d, err := s.GetWorkspaceDomain(ws, "go.example")
+	require.NoError(t, err)
+	require.Equal(t, id, d.ID)
+	require.Equal(t, "token", d.VerificationToken)
+	require.False(t, d.Verified())
+	d, err = s.GetWorkspaceDomain(other, "go.example")
+	require.NoError(t, err)
+	require.Equal(t, otherID, d.ID)
+	_, err = s.GetWorkspaceDomain(ws, "unknown.example")
+	require.ErrorIs(t, err, storage.ErrDomainNotFound)
+	_, err = s.GetDomain("go.example")
+	require.ErrorIs(t, err, storage.ErrDomainNotFound)
+
+	//links are only created on verified domains of the workspace
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, Domain: "go.example", URL: "https://a.example", Alias: "ex"})
+	require.ErrorIs(t, err, storage.ErrDomainNotFound)
+
+	//the first verification wins, the other claims are dropped
+	require.NoError(t, s.VerifyDomain(id, time.Now()))
+	require.ErrorIs(t, s.VerifyDomain(otherID, time.Now()), storage.ErrDomainNotFound)
+	require.ErrorIs(t, s.VerifyDomain(100, time.Now()), storage.ErrDomainNotFound)
+	_, err = s.CreateDomain(domain.Domain{WorkspaceID: other, Hostname: "go.example", VerificationToken: "token-b", CreatedAt: time.Now()})
+	require.ErrorIs(t, err, storage.ErrDomainExists)
+
+	d, err = s.GetDomain("go.example")
+	require.NoError(t, err)
+	require.Equal(t, id, d.ID)
+	require.True(t, d.Verified())
+
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, Domain: "go.example", URL: "https://a.example", Alias: "ex"})
+	require.ErrorIs(t, err, storage.ErrDomainNotFound)
+
+	//the alias is unique per domain, the default domain has its own namespace
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, Domain: "go.example", URL: "https://a.example", Alias: "ex"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, Domain: "go.example", URL: "https://b.example", Alias: "ex"})
+	require.ErrorIs(t, err, storage.ErrURLExists)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://b.example", Alias: "ex"})
+	require.NoError(t, err)
+
+	link, err := s.GetURL(domain.LinkRef{WorkspaceID: ws, Domain: "go.example", Alias: "ex"})
+	require.NoError(t, err)
+	require.Equal(t, "https://a.example", link.URL)
+	require.Equal(t, "go.example", link.Domain)
+	link, err = s.GetURL(ref(ws, "ex"))
+	require.NoError(t, err)
+	require.Equal(t, "https://b.example", link.URL)
+	require.Empty(t, link.Domain)
+	_, err = s.GetURL(domain.LinkRef{WorkspaceID: other, Domain: "go.example", Alias: "ex"})
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+
+	require.NoError(t, s.DeleteURL(domain.LinkRef{WorkspaceID: ws, Domain: "go.example", Alias: "ex"}))
+	_, err = s.GetLink(ref(ws, "ex"))
+	require.NoError(t, err)
+
+	domains, err := s.ListDomains(ws)
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+	require.True(t, domains[0].Verified())
+	domains, err = s.ListDomains(other)
+	require.NoError(t, err)
+	require.Empty(t, domains)
+}
+
+func TestStorage_Quotas(t *testing.T) {
+	s := newTestStorage(t)
+
+	quota := int64(2)
+	other, err := s.CreateWorkspace(domain.Workspace{Name: "Team B", Slug: "teamb", MonthlyLinkQuota: &quota, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	ws2, err := s.GetWorkspace(other)
+	require.NoError(t, err)
+	require.Equal(t, &quota, ws2.MonthlyLinkQuota)
+
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, URL: "https://a.example", Alias: "a"})
+	require.NoError(t, err)
+	//failed saves aren't counted
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, URL: "https://a.example", Alias: "a"})
+	require.ErrorIs(t, err, storage.ErrURLExists)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, URL: "https://b.example", Alias: "b"})
+	require.NoError(t, err)
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, URL: "https://c.example", Alias: "c"})
+	require.ErrorIs(t, err, storage.ErrQuotaExceeded)
+	_, err = s.GetURL(ref(other, "c"))
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+
+	period := domain.UsagePeriod(time.Now())
+	usage, err := s.GetWorkspaceUsage(other, period)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), usage.LinksCreated)
+	usage, err = s.GetWorkspaceUsage(other, "2000-01")
+	require.NoError(t, err)
+	require.Zero(t, usage.LinksCreated)
+
+	//workspaces without a quota are unlimited
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://c.example", Alias: "c"})
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetWorkspaceQuota(other, nil))
+	_, err = s.SaveURL(domain.Link{WorkspaceID: other, URL: "https://c.example", Alias: "c"})
+	require.NoError(t, err)
+	ws2, err = s.GetWorkspace(other)
+	require.NoError(t, err)
+	require.Nil(t, ws2.MonthlyLinkQuota)
+
+	require.ErrorIs(t, s.SetWorkspaceQuota(100, &quota), storage.ErrWorkspaceNotFound)
+}
+
+func TestStorage_NextAliasID(t *testing.T) {
+	s := newTestStorage(t)
+
+	first, err := s.NextAliasID()
+	require.NoError(t, err)
+	for i := int64(1); i <= 3; i++ {
+		id, err := s.NextAliasID()
+		require.NoError(t, err)
+		require.Equal(t, first+i, id)
+	}
+}
+
+func TestStorage_SaveOrGetURL(t *testing.T) {
+	s := newTestStorage(t)
+
+	link, created, err := s.SaveOrGetURL(domain.Link{WorkspaceID: ws, URL: "https://Example.com:443/a?b=2&a=1", Alias: "first"})
+	require.NoError(t, err)
+	require.True(t, created)
+	require.NotZero(t, link.ID)
+
+	//the same normalized url returns the existing link
+	link, created, err = s.SaveOrGetURL(domain.Link{WorkspaceID: ws, URL: "https://example.com/a?a=1&b=2", Alias: "second"})
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, "first", link.Alias)
+	_, err = s.GetURL(ref(ws, "second"))
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+
+	//links of other workspaces aren't shared
+	other, err := s.CreateWorkspace(domain.Workspace{Name: "Team B", Slug: "teamb", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	link, created, err = s.SaveOrGetURL(domain.Link{WorkspaceID: other, URL: "https://example.com/a?a=1&b=2", Alias: "first"})
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, other, link.WorkspaceID)
+
+	//disabled and expired links aren't reused
+	disabled := true
+	_, err = s.UpdateLink(ref(ws, "first"), domain.LinkUpdate{Disabled: &disabled})
+	require.NoError(t, err)
+	link, created, err = s.SaveOrGetURL(domain.Link{WorkspaceID: ws, URL: "https://example.com/a?a=1&b=2", Alias: "third"})
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, "third", link.Alias)
+
+	//an updated destination is matched by its new url
+	_, err = s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://old.example", Alias: "moved"})
+	require.NoError(t, err)
+	newURL := "https://NEW.example"
+	_, err = s.UpdateLink(ref(ws, "moved"), domain.LinkUpdate{URL: &newURL})
+	require.NoError(t, err)
+	link, created, err = s.SaveOrGetURL(domain.Link{WorkspaceID: ws, URL: "https://new.example/", Alias: "fourth"})
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, "moved", link.Alias)
+}
+
+func TestStorage_NormalizeURLs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	s, err := sqlite.New(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+
+	//roll back to the schema before normalized urls and save a link with it
+	m, err := s.Migrator()
+	require.NoError(t, err)
+	_, err = m.Up()
+	require.NoError(t, err)
+	for {
+		mig, err := m.Down()
+		require.NoError(t, err)
+		if mig.Version == 13 {
+			break
+		}
+	}
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO url(workspace_id, alias, url) VALUES(?, 'old', 'HTTPS://Example.com:443')", ws)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	_, err = m.Up()
+	require.NoError(t, err)
+	n, err := s.NormalizeURLs(10)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	n, err = s.NormalizeURLs(10)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	link, created, err := s.SaveOrGetURL(domain.Link{WorkspaceID: ws, URL: "https://example.com/", Alias: "new"})
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, "old", link.Alias)
+}
+
+func TestStorage_IdempotencyKeys(t *testing.T) {
+	s := newTestStorage(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	key := domain.IdempotencyKey{Scope: "key:1", Key: "k1", RequestHash: "h1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, s.CreateIdempotencyKey(key))
+	require.ErrorIs(t, s.CreateIdempotencyKey(key), storage.ErrIdempotencyKeyExists)
+
+	//keys of other scopes don't clash
+	require.NoError(t, s.CreateIdempotencyKey(domain.IdempotencyKey{Scope: "key:2", Key: "k1", RequestHash: "h2", CreatedAt: now, ExpiresAt: now.Add(time.Minute)}))
+
+	got, err := s.GetIdempotencyKey("key:1", "k1")
+	require.NoError(t, err)
+	require.False(t, got.Completed())
+	require.Equal(t, "h1", got.RequestHash)
+
+	require.NoError(t, s.CompleteIdempotencyKey("key:1", "k1", 201, "application/json", []byte(`{"alias":"a"}`)))
+	got, err = s.GetIdempotencyKey("key:1", "k1")
+	require.NoError(t, err)
+	require.True(t, got.Completed())
+	require.Equal(t, 201, got.Status)
+	require.Equal(t, "application/json", got.ContentType)
+	require.Equal(t, `{"alias":"a"}`, string(got.Body))
+	require.True(t, now.Add(time.Hour).Equal(got.ExpiresAt))
+
+	require.ErrorIs(t, s.CompleteIdempotencyKey("key:1", "unknown", 201, "", nil), storage.ErrIdempotencyKeyNotFound)
+
+	//released keys may be reserved again
+	require.NoError(t, s.DeleteIdempotencyKey("key:1", "k1"))
+	_, err = s.GetIdempotencyKey("key:1", "k1")
+	require.ErrorIs(t, err, storage.ErrIdempotencyKeyNotFound)
+	require.NoError(t, s.CreateIdempotencyKey(key))
+
+	//an expired key is replaced by a new request
+	later := now.Add(2 * time.Minute)
+	require.NoError(t, s.CreateIdempotencyKey(domain.IdempotencyKey{Scope: "key:2", Key: "k1", RequestHash: "h3", CreatedAt: later, ExpiresAt: later.Add(time.Minute)}))
+	got, err = s.GetIdempotencyKey("key:2", "k1")
+	require.NoError(t, err)
+	require.Equal(t, "h3", got.RequestHash)
+
+	n, err := s.PurgeIdempotencyKeys(now.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	_, err = s.GetIdempotencyKey("key:1", "k1")
+	require.ErrorIs(t, err, storage.ErrIdempotencyKeyNotFound)
+}
+
+func TestStorage_SaveURLs(t *testing.T) {
+	s := newTestStorage(t)
+
+	_, err := s.SaveURL(domain.Link{WorkspaceID: ws, URL: "https://taken.example", Alias: "taken"})
+	require.NoError(t, err)
+
+	links := []domain.Link{
+		{WorkspaceID: ws, URL: "https://a.example", Alias: "a"},
+		{WorkspaceID: ws, URL: "https://b.example", Alias: "taken"},
+		{WorkspaceID: ws, URL: "https://c.example", Alias: "c", Domain: "unknown.example"},
+		{WorkspaceID: ws, URL: "https://d.example", Alias: "d"},
+	}
+
+	//all-or-nothing batches stop on the first rejected link
+	results, err := s.SaveURLs(links, storage.SaveOptions{Atomic: true})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	require.ErrorIs(t, results[1].Err, storage.ErrURLExists)
+	require.Zero(t, results[0].ID)
+	require.NoError(t, results[2].Err)
+	_, err = s.GetURL(ref(ws, "a"))
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+
+	//partial batches save the rest
+	results, err = s.SaveURLs(links, storage.SaveOptions{})
+	require.NoError(t, err)
+	require.NotZero(t, results[0].ID)
+	require.ErrorIs(t, results[1].Err, storage.ErrURLExists)
+	require.ErrorIs(t, results[2].Err, storage.ErrDomainNotFound)
+	require.NotZero(t, results[3].ID)
+
+	for _, alias := range []string{"a", "d"} {
+		link, err := s.GetURL(ref(ws, alias))
+		require.NoError(t, err)
+		require.Equal(t, "https://"+alias+".example", link.URL)
+	}
+	link, err := s.GetURL(ref(ws, "taken"))
+	require.NoError(t, err)
+	require.Equal(t, "https://taken.example", link.URL)
+
+	//every saved link has its event
+	events, err := s.ClaimEvents("worker", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	usage, err := s.GetWorkspaceUsage(ws, domain.UsagePeriod(time.Now()))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), usage.LinksCreated)
+}
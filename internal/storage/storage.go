@@ -1,9 +1,122 @@
 package storage
 
-import "errors"
+import (
+	"errors"
+	"short-url/internal/http-server/model/domain"
+	"time"
+)
 
 var (
 	ErrURLNotFound   = errors.New("url not found")
 	ErrURLExists     = errors.New("url exists")
+	ErrURLExpired    = errors.New("url expired")
 	ErrEventNotFound = errors.New("no new events")
+
+	ErrDeadEventNotFound = errors.New("dead event not found")
+	ErrAPIKeyNotFound    = errors.New("api key not found")
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrWorkspaceExists   = errors.New("workspace exists")
+	ErrDomainNotFound    = errors.New("domain not found")
+	ErrDomainExists      = errors.New("domain exists")
+	ErrQuotaExceeded     = errors.New("monthly link quota exceeded")
+
+	ErrIdempotencyKeyExists   = errors.New("idempotency key exists")
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
 )
+
+// Repository is implemented by every storage backend (sqlite, postgres).
+// Backends must return the sentinel errors above so handlers behave the same on any of them.
+type Repository interface {
+	SaveURL(link domain.Link) (int64, error)
+	SaveOrGetURL(link domain.Link) (domain.Link, bool, error)
+	NormalizeURLs(limit int) (int, error)
+	SaveURLs(links []domain.Link, opts SaveOptions) ([]SaveResult, error)
+	GetURL(ref domain.LinkRef) (domain.Link, error)
+	NextAliasID() (int64, error)
+	DeleteURL(ref domain.LinkRef) error
+	GetLink(ref domain.LinkRef) (domain.Link, error)
+	UpdateLink(ref domain.LinkRef, update domain.LinkUpdate) (domain.Link, error)
+	ListLinks(query domain.LinkQuery) ([]domain.Link, error)
+	CreateAPIKey(key domain.APIKey, keyHash string) (int64, error)
+	GetAPIKeyByHash(keyHash string) (domain.APIKey, error)
+	RevokeAPIKey(workspaceID, id int64) error
+	RotateAPIKey(workspaceID, id int64, prefix string, keyHash string) (domain.APIKey, error)
+	ListAPIKeys(workspaceID int64) ([]domain.APIKey, error)
+	CreateWorkspace(ws domain.Workspace) (int64, error)
+	GetWorkspace(id int64) (domain.Workspace, error)
+	GetWorkspaceBySlug(slug string) (domain.Workspace, error)
+	SetWorkspaceQuota(id int64, quota *int64) error
+	GetWorkspaceUsage(workspaceID int64, period string) (domain.WorkspaceUsage, error)
+	CreateDomain(d domain.Domain) (int64, error)
+	GetDomain(hostname string) (domain.Domain, error)
+	GetWorkspaceDomain(workspaceID int64, hostname string) (domain.Domain, error)
+	ListDomains(workspaceID int64) ([]domain.Domain, error)
+	VerifyDomain(id int64, verifiedAt time.Time) error
+	SaveClicks(clicks []domain.Click) error
+	GetClickStats(linkID int64, from, to time.Time, bucket string) (domain.ClickStats, error)
+	CountClicks(linkIDs []int64) (map[int64]int64, error)
+	RollupClicks(to time.Time) (time.Time, error)
+	PurgeClicks(before time.Time, limit int) (int, error)
+	PurgeExpiredURLs(now time.Time, limit int, archive bool) (int, error)
+	CreateIdempotencyKey(key domain.IdempotencyKey) error
+	GetIdempotencyKey(scope, key string) (domain.IdempotencyKey, error)
+	CompleteIdempotencyKey(scope, key string, status int, contentType string, body []byte) error
+	DeleteIdempotencyKey(scope, key string) error
+	PurgeIdempotencyKeys(now time.Time, limit int) (int, error)
+	ClaimEvents(workerID string, limit int, lease time.Duration) ([]domain.Event, error)
+	MarkEventsAsDone(eventIDs []int) error
+	MarkEventFailed(eventID int, lastError string, nextAttemptAt time.Time) error
+	MarkEventDead(eventID int, lastError string) error
+	ListDeadEvents(limit int) ([]domain.DeadEvent, error)
+	ListEvents(status string, limit int) ([]domain.OutboxEvent, error)
+	RequeueEvent(eventID int) error
+	PurgeEvents(before time.Time, dead bool, limit int) (int, error)
+}
+
+// EventRowStatuses are the statuses of the events table by domain.EventStatuses
+var EventRowStatuses = map[string]string{
+	domain.EventStatusPending: "'new', 'in_progress'",
+	domain.EventStatusDead:    "'dead'",
+	domain.EventStatusDone:    "'done'",
+}
+
+// AnyWorkspace is the workspace id of RevokeAPIKey and RotateAPIKey matching the keys of every workspace,
+// only global admins may use it
+const AnyWorkspace int64 = 0
+
+// SaveOptions of SaveURLs
+type SaveOptions struct {
+	// roll back the transaction on the first rejected link, nothing is saved
+	Atomic bool
+	// roll back the transaction after saving, the results tell what would be saved
+	DryRun bool
+}
+
+// SaveResult is the outcome of a link of SaveURLs: its id, or the sentinel error it was rejected with
+// (ErrURLExists, ErrDomainNotFound, ErrQuotaExceeded)
+type SaveResult struct {
+	ID  int64
+	Err error
+}
+
+// Rejected reports whether the error of a save is caused by the link rather than the storage
+func Rejected(err error) bool {
+	return errors.Is(err, ErrURLExists) || errors.Is(err, ErrDomainNotFound) || errors.Is(err, ErrQuotaExceeded)
+}
+
+// BreakdownLimit is the number of top values returned for each breakdown dimension
+const BreakdownLimit = 10
+
+// RollupCovers reports whether click stats of [from, to) can be read from the rollups:
+// the range is aligned to the buckets and all of them are aggregated by rolledUpTo.
+func RollupCovers(from, to time.Time, bucket string, rolledUpTo time.Time) bool {
+	size := time.Hour
+	if bucket == domain.BucketDay {
+		size = 24 * time.Hour
+	}
+	from, to = from.UTC(), to.UTC()
+	return !rolledUpTo.IsZero() &&
+		from.Equal(from.Truncate(size)) &&
+		to.Equal(to.Truncate(size)) &&
+		!to.After(rolledUpTo)
+}
@@ -3,9 +3,12 @@ package tests
 import (
 	"net/http"
 	"net/url"
+	"short-url/internal/http-server/handlers/url/batch"
+	"short-url/internal/http-server/handlers/url/importer"
 	"short-url/internal/http-server/handlers/url/save"
 	"short-url/internal/lib/api"
 	"short-url/internal/lib/random"
+	"strings"
 	"testing"
 
 	"github.com/brianvoe/gofakeit/v6"
@@ -30,29 +33,43 @@ func TestURLShortner_HappyPath(t *testing.T) {
 		Alias: random.NewRandomString(10),
 	}).WithBasicAuth("user", "password").
 		Expect().
-		Status(http.StatusOK).
+		Status(http.StatusCreated).
 		JSON().
 		Object().
-		ContainsKey("alias")
+		ContainsKey("alias").
+		ContainsKey("short_url")
 }
 
 func TestURLShortner_SaveRedirect(t *testing.T) {
 	testCases := []struct {
-		name  string
-		url   string
-		alais string
-		error string
+		name   string
+		url    string
+		alais  string
+		status int
+		// content type of the response
+		mediaType string
+		error     string
 	}{
 		{
-			name:  "Valid URL",
-			url:   gofakeit.URL(),
-			alais: gofakeit.Word() + gofakeit.Word(),
+			name:      "Valid URL",
+			url:       gofakeit.URL(),
+			alais:     gofakeit.Word() + gofakeit.Word(),
+			status:    http.StatusCreated,
+			mediaType: "application/json",
+		},
+		{
+			name:      "Generated alias",
+			url:       gofakeit.URL(),
+			status:    http.StatusCreated,
+			mediaType: "application/json",
 		},
 		{
-			name:  "Invalid URL",
-			url:   "123456",
-			alais: gofakeit.Word(),
-			error: "invalid body,field URL is not in URL format",
+			name:      "Invalid URL",
+			url:       "123456",
+			alais:     gofakeit.Word(),
+			status:    http.StatusBadRequest,
+			mediaType: "application/problem+json",
+			error:     "field URL is not in URL format",
 		},
 	}
 	for _, tc := range testCases {
@@ -70,12 +87,13 @@ func TestURLShortner_SaveRedirect(t *testing.T) {
 					Alias: tc.alais,
 				}).
 				WithBasicAuth("user", "password").
-				Expect().Status(http.StatusOK).
-				JSON().Object()
+				Expect().Status(tc.status).
+				JSON(httpexpect.ContentOpts{MediaType: tc.mediaType}).Object()
 
 			if tc.error != "" {
-				req.NotContainsKey("alis")
-				req.Value("error").String().IsEqual(tc.error)
+				req.NotContainsKey("alias")
+				req.Value("detail").String().IsEqual("invalid body")
+				req.Value("errors").Array().Value(0).Object().Value("message").String().IsEqual(tc.error)
 				return
 			}
 
@@ -105,3 +123,163 @@ func testRedirect(t *testing.T, alias string, urlToRedirect string) {
 	require.Equal(t, urlToRedirect, redirectToURL)
 
 }
+
+func TestURLShortner_Dedupe(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	target := gofakeit.URL() + "/dedupe?b=2&a=1"
+
+	alias := e.POST("/url").WithJSON(save.Request{URL: target, Dedupe: true}).
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("alias").String().Raw()
+
+	//the same normalized url returns the existing alias
+	e.POST("/url").WithJSON(save.Request{URL: strings.Replace(target, "b=2&a=1", "a=1&b=2", 1), Dedupe: true}).
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("alias").String().IsEqual(alias)
+}
+
+func TestURLShortner_IdempotencyKey(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	key := random.NewRandomString(16)
+	target := gofakeit.URL()
+
+	alias := e.POST("/url").WithJSON(save.Request{URL: target}).
+		WithHeader("Idempotency-Key", key).
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("alias").String().Raw()
+
+	//the retry gets the original response instead of a new link
+	resp := e.POST("/url").WithJSON(save.Request{URL: target}).
+		WithHeader("Idempotency-Key", key).
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusCreated)
+	resp.Header("Idempotent-Replayed").IsEqual("true")
+	resp.JSON().Object().Value("alias").String().IsEqual(alias)
+
+	e.POST("/url").WithJSON(save.Request{URL: gofakeit.URL()}).
+		WithHeader("Idempotency-Key", key).
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusUnprocessableEntity)
+}
+
+func TestURLShortner_Batch(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	alias := gofakeit.Word() + random.NewRandomString(6)
+	items := []save.Request{
+		{URL: gofakeit.URL(), Alias: alias},
+		{URL: "123456"},
+		{URL: gofakeit.URL()},
+	}
+
+	var resp batch.Response
+	e.POST("/url/batch").WithJSON(items).
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Decode(&resp)
+
+	require.Equal(t, 2, resp.Created)
+	require.Equal(t, 1, resp.Failed)
+	require.Equal(t, http.StatusBadRequest, resp.Results[1].Code)
+	require.Equal(t, alias, resp.Results[0].Alias)
+	testRedirect(t, alias, items[0].URL)
+	testRedirect(t, resp.Results[2].Alias, items[2].URL)
+
+	//the taken alias rolls the whole atomic batch back
+	other := random.NewRandomString(12)
+	e.POST("/url/batch").WithQuery("mode", "atomic").
+		WithBytes([]byte(`{"url":"https://example.com","alias":"`+other+`"}`+"\n"+`{"url":"https://example.com","alias":"`+alias+`"}`)).
+		WithHeader("Content-Type", "application/x-ndjson").
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusUnprocessableEntity).
+		JSON().Object().Value("results").Array().Value(1).Object().Value("code").Number().IsEqual(http.StatusConflict)
+
+	e.GET("/url/"+other).
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestURLShortner_ExportImport(t *testing.T) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	e := httpexpect.Default(t, u.String())
+
+	alias := gofakeit.Word() + random.NewRandomString(6)
+	target := gofakeit.URL()
+	e.POST("/url").WithJSON(save.Request{URL: target, Alias: alias}).
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusCreated)
+
+	e.GET("/url/export").WithQuery("clicks", "true").
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusOK).
+		HasContentType("text/csv").
+		Body().Contains(alias + "," + target)
+
+	other := random.NewRandomString(12)
+	ndjson := `{"alias":"` + other + `","url":"https://example.com"}` + "\n" +
+		`{"alias":"` + alias + `","url":"https://example.com/new"}` + "\n" +
+		`{"alias":"bad` + other + `","url":"not a url"}` + "\n"
+
+	//a dry run saves nothing
+	var sum importer.Response
+	e.POST("/url/import").WithQuery("dry_run", "true").WithQuery("on_conflict", "overwrite").
+		WithBytes([]byte(ndjson)).
+		WithHeader("Content-Type", "application/x-ndjson").
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Decode(&sum)
+
+	require.Equal(t, 1, sum.Created)
+	require.Equal(t, 1, sum.Updated)
+	require.Equal(t, 1, sum.Failed)
+	require.Equal(t, 3, sum.Errors[0].Line)
+	e.GET("/url/"+other).
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusNotFound)
+
+	e.POST("/url/import").WithQuery("on_conflict", "overwrite").
+		WithBytes([]byte(ndjson)).
+		WithHeader("Content-Type", "application/x-ndjson").
+		WithBasicAuth("user", "password").
+		Expect().
+		Status(http.StatusOK)
+
+	testRedirect(t, other, "https://example.com")
+	testRedirect(t, alias, "https://example.com/new")
+}